@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net"
+	"runtime/debug"
+	"strings"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	walletv1 "github.com/hokdre/mini-ewallet/pkg/pb/wallet/v1"
+	"github.com/hokdre/mini-ewallet/pkg/ratelimit"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	grpcServer *grpc.Server
+)
+
+// grpcPublicMethods are served without a token, like the public routes of
+// setupRoutes.
+var grpcPublicMethods = map[string]bool{
+	walletv1.WalletService_Init_FullMethodName: true,
+}
+
+// grpcScopes is the scope each protected method needs, a method that is
+// neither public nor listed here is denied. It mirrors the route scopes of
+// setupRoutes.
+var grpcScopes = map[string]string{
+	walletv1.WalletService_Get_FullMethodName:             model.TokenScope.WalletRead,
+	walletv1.WalletService_GetTransactions_FullMethodName: model.TokenScope.WalletRead,
+	walletv1.WalletService_Enable_FullMethodName:          model.TokenScope.WalletWrite,
+	walletv1.WalletService_Disable_FullMethodName:         model.TokenScope.WalletWrite,
+	walletv1.WalletService_Deposit_FullMethodName:         model.TokenScope.WalletWrite,
+	walletv1.WalletService_Withdrawal_FullMethodName:      model.TokenScope.WalletWrite,
+}
+
+type GrpcConfig struct {
+	PORT          string
+	WalletHandler walletv1.WalletServiceServer
+	TokenService  internal.TokenService
+	// RateLimits limits Init per peer address and the methods behind a token
+	// per account, with the WalletRead or WalletWrite limit of their scope.
+	RateLimits RateLimits
+}
+
+func GrpcStart(cfg GrpcConfig) {
+	listener, err := net.Listen("tcp", cfg.PORT)
+	if err != nil {
+		log.Fatalf("failed listen grpc : %s", err)
+	}
+
+	grpcServer = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			TracingInterceptor(),
+			RecoveryInterceptor(),
+			RateLimitInterceptor(map[string]ratelimit.Config{
+				walletv1.WalletService_Init_FullMethodName: cfg.RateLimits.Init,
+			}, peerKey),
+			AuthorizationInterceptor(cfg.TokenService, grpcPublicMethods, grpcScopes),
+			RateLimitInterceptor(grpcRateLimits(cfg.RateLimits), grpcAccountKey),
+		),
+	)
+	walletv1.RegisterWalletServiceServer(grpcServer, cfg.WalletHandler)
+
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			log.Fatalf("Shutting down the grpc server: %s", err)
+		}
+	}()
+}
+
+// grpcRateLimits gives every protected method the limit of its scope, the
+// same limits the http routes of that scope take.
+func grpcRateLimits(limits RateLimits) map[string]ratelimit.Config {
+	scopeLimits := map[string]ratelimit.Config{
+		model.TokenScope.WalletRead:  limits.WalletRead,
+		model.TokenScope.WalletWrite: limits.WalletWrite,
+	}
+
+	methodLimits := map[string]ratelimit.Config{}
+	for method, scope := range grpcScopes {
+		methodLimits[method] = scopeLimits[scope]
+	}
+
+	return methodLimits
+}
+
+// GrpcDown waits for in flight calls until ctx is done, then cuts them off.
+func GrpcDown(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		log.Printf("Successfully shutdown the grpc server")
+	case <-ctx.Done():
+		grpcServer.Stop()
+		log.Printf("Ungrafully shutdown the grpc server : %s \n", ctx.Err())
+	}
+}
+
+// AuthorizationInterceptor is the gRPC counterpart of AuthorizationMiddleware
+// and RequireScope, the token travels in the "authorization" metadata as
+// "Token <access token>". Public methods skip the token, any other method
+// without a scope is denied so a new rpc is closed until it is listed.
+func AuthorizationInterceptor(tokenService internal.TokenService, public map[string]bool, scopes map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		scope, protected := scopes[info.FullMethod]
+		if !protected {
+			return nil, util.GrpcError(model.ErrForbidden)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		authHeaders := md.Get("authorization")
+		if len(authHeaders) != 1 {
+			return nil, util.GrpcError(model.ErrLoginInfoUknown)
+		}
+
+		headers := strings.Split(authHeaders[0], " ")
+		if len(headers) != 2 || strings.ToLower(headers[0]) != "token" {
+			return nil, util.GrpcError(model.ErrLoginInfoUknown)
+		}
+
+		token, err := tokenService.Verify(ctx, headers[1], model.TokenType.Access)
+		if err != nil {
+			return nil, util.GrpcError(err)
+		}
+
+		if !token.HasScope(scope) {
+			return nil, util.GrpcError(model.ErrInsufficientScope)
+		}
+
+		return handler(util.ContextWithToken(ctx, token), req)
+	}
+}
+
+// RecoveryInterceptor turns a panicking handler into an internal error, like
+// the echo Recover middleware does for http.
+func RecoveryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in %s : %v \n%s", info.FullMethod, r, debug.Stack())
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
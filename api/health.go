@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"github.com/labstack/echo/v4"
+)
+
+// readinessTimeout bounds every readiness check, a probe must not hang on a
+// database that stopped answering.
+const readinessTimeout = 2 * time.Second
+
+var errShuttingDown = errors.New("shutting down")
+
+// HealthCheck reports whether a dependency the server needs is usable.
+type HealthCheck func(ctx context.Context) error
+
+// ready is false until the http port is bound and again once HttpDown
+// begins, so load balancers stop sending requests before the server closes.
+var ready atomic.Bool
+
+// Liveness answers as long as the process serves http at all, it checks no
+// dependency so an unreachable database never gets the pod restarted.
+func Liveness(ctx echo.Context) error {
+	return util.SendSuccess(ctx, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readiness runs every check and answers 503 with the failed ones while any
+// fails or the server is shutting down.
+func Readiness(checks map[string]HealthCheck) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if !ready.Load() {
+			return util.SendError(ctx, http.StatusServiceUnavailable, errShuttingDown)
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx.Request().Context(), readinessTimeout)
+		defer cancel()
+
+		failed := map[string]string{}
+		for name, check := range checks {
+			if err := check(checkCtx); err != nil {
+				failed[name] = err.Error()
+			}
+		}
+		if len(failed) > 0 {
+			ctx.Logger().Errorf("not ready : %v \n", failed)
+			return util.SendFailed(ctx, http.StatusServiceUnavailable, failed)
+		}
+
+		return util.SendSuccess(ctx, http.StatusOK, map[string]string{"status": "ready"})
+	}
+}
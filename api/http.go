@@ -2,18 +2,23 @@ package api
 
 import (
 	"context"
+	"log"
+	"net"
 	"net/http"
 	"time"
 
+	"github.com/hokdre/mini-ewallet/internal"
 	"github.com/hokdre/mini-ewallet/internal/controller"
-	"github.com/hokdre/mini-ewallet/pkg/util"
+	"github.com/hokdre/mini-ewallet/pkg/metrics"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	_ "github.com/lib/pq"
 )
 
 var (
-	e *echo.Echo
+	e             *echo.Echo
+	metricsServer *http.Server
+	drainDelay    time.Duration
 )
 
 type Config struct {
@@ -21,12 +26,31 @@ type Config struct {
 	ReadTimeOut   time.Duration
 	WriteTimeOut  time.Duration
 	WalletHandler *controller.WalletHttpController
-	Encryption    util.Encryption
+	TokenHandler  *controller.TokenHttpController
+	TokenService  internal.TokenService
+	RateLimits    RateLimits
+	// TrustProxy reads the client ip of X-Forwarded-For set by proxies on
+	// private networks, otherwise the ip of the connection is used.
+	TrustProxy bool
+	// Readiness are the checks behind /readyz, the database for instance.
+	Readiness map[string]HealthCheck
+	// DrainDelay is how long HttpDown keeps serving after /readyz turned
+	// not ready, long enough for load balancers to notice.
+	DrainDelay time.Duration
+	// MetricsPORT serves /metrics apart from the api so it stays off the
+	// public port, empty leaves metrics unserved.
+	MetricsPORT string
 }
 
 func HTTPStart(cfg Config) {
 	e = echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	if cfg.TrustProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	}
 	e.Use(middleware.Logger())
+	e.Use(TracingMiddleware())
+	e.Use(MetricsMiddleware())
 	e.Use(middleware.Recover())
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
 		AllowOrigins: []string{"*"},
@@ -35,12 +59,20 @@ func HTTPStart(cfg Config) {
 			http.MethodPost,
 			http.MethodPatch,
 		},
+		// the wallet version clients send back as If-Match
+		ExposeHeaders: []string{"ETag"},
 	}))
 
+	e.GET("/healthz", Liveness)
+	e.GET("/readyz", Readiness(cfg.Readiness))
+	drainDelay = cfg.DrainDelay
+
 	setupRoutes(
 		e,
 		cfg.WalletHandler,
-		cfg.Encryption,
+		cfg.TokenHandler,
+		cfg.TokenService,
+		cfg.RateLimits,
 	)
 
 	server := &http.Server{
@@ -48,18 +80,62 @@ func HTTPStart(cfg Config) {
 		ReadTimeout:  cfg.ReadTimeOut,
 		WriteTimeout: cfg.WriteTimeOut,
 	}
+
+	// the port is bound before /readyz turns ready, StartServer serves on
+	// e.Listener instead of binding again
+	listener, err := net.Listen("tcp", cfg.PORT)
+	if err != nil {
+		log.Fatalf("failed listen http : %s", err)
+	}
+	e.Listener = listener
+	if cfg.MetricsPORT != "" {
+		metricsStart(cfg.MetricsPORT)
+	}
+	ready.Store(true)
+
 	go func() {
 		if err := e.StartServer(server); err != nil && err != http.ErrServerClosed {
 			e.Logger.Fatal("Shutting down the server: ", err)
 		}
 	}()
+}
+
+// metricsStart serves /metrics on a listener of its own, meant for the
+// scraper on an internal network only.
+func metricsStart(port string) {
+	listener, err := net.Listen("tcp", port)
+	if err != nil {
+		log.Fatalf("failed listen metrics : %s", err)
+	}
 
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	metricsServer = &http.Server{Handler: mux}
+
+	go func() {
+		if err := metricsServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Shutting down the metrics server: %s", err)
+		}
+	}()
 }
 
+// HttpDown turns /readyz not ready first, then waits for the drain delay
+// before in flight requests are finished and connections are closed.
 func HttpDown(ctx context.Context) {
+	ready.Store(false)
+	select {
+	case <-time.After(drainDelay):
+	case <-ctx.Done():
+	}
+
 	if err := e.Shutdown(ctx); err != nil {
 		e.Logger.Fatalf("Ungrafully shutdown : %s \n", err)
 	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			e.Logger.Printf("Ungrafully shutdown the metrics server : %s \n", err)
+		}
+	}
 
 	e.Logger.Printf("Successfully shutdown the server")
 }
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/pkg/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// MetricsMiddleware counts and times every request by its route, it runs
+// before Recover so panics are recorded as the 500 they end up as.
+func MetricsMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			start := time.Now()
+			err := next(ctx)
+
+			metrics.ObserveRequest(ctx.Request().Method, route(ctx), responseStatus(ctx, err), time.Since(start))
+			return err
+		}
+	}
+}
+
+// responseStatus is the status the request ends with. An error reaches the
+// error handler after the middlewares, so its status is not written yet.
+func responseStatus(ctx echo.Context, err error) int {
+	if err == nil {
+		return ctx.Response().Status
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	if ctx.Response().Committed {
+		return ctx.Response().Status
+	}
+	return http.StatusInternalServerError
+}
+
+// route is the registered path rather than the requested one, so ids do not
+// end up in label values and span names.
+func route(ctx echo.Context) string {
+	if ctx.Path() == "" {
+		return "unmatched"
+	}
+
+	return ctx.Path()
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/ratelimit"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"github.com/labstack/echo/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// RateLimits are the token buckets of the routes, a zero config leaves the
+// routes unlimited.
+type RateLimits struct {
+	Init        ratelimit.Config
+	Token       ratelimit.Config
+	WalletRead  ratelimit.Config
+	WalletWrite ratelimit.Config
+}
+
+// RateLimitMiddleware answers 429 once the bucket of the caller is empty.
+// Every route gets buckets of its own, keyOf tells whose bucket of the route
+// a request takes from.
+func RateLimitMiddleware(cfg ratelimit.Config, keyOf func(echo.Context) (string, error)) echo.MiddlewareFunc {
+	limiter := ratelimit.New(cfg)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if limiter == nil {
+			return next
+		}
+
+		return func(ctx echo.Context) error {
+			key, err := keyOf(ctx)
+			if err != nil {
+				return util.SendError(ctx, http.StatusUnauthorized, err)
+			}
+
+			allowed, retryAfter := limiter.Allow(ctx.Path()+" "+key, time.Now())
+			if !allowed {
+				ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(ratelimit.RetryAfterSeconds(retryAfter)))
+				return util.SendError(ctx, http.StatusTooManyRequests, model.ErrRateLimited)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// accountKey limits per account, it runs after AuthorizationMiddleware.
+func accountKey(ctx echo.Context) (string, error) {
+	accountID, err := util.GetAccountID(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return accountID.String(), nil
+}
+
+// ipKey limits per client, see Config.TrustProxy for where the ip comes from.
+func ipKey(ctx echo.Context) (string, error) {
+	return ctx.RealIP(), nil
+}
+
+// RateLimitInterceptor is the gRPC counterpart of RateLimitMiddleware, every
+// method in limits gets buckets of its own and keyOf tells whose bucket of the
+// method a call takes from. An empty bucket fails with ResourceExhausted and a
+// "retry-after" header in seconds.
+func RateLimitInterceptor(limits map[string]ratelimit.Config, keyOf func(context.Context) (string, error)) grpc.UnaryServerInterceptor {
+	limiters := map[string]*ratelimit.Limiter{}
+	for method, cfg := range limits {
+		if limiter := ratelimit.New(cfg); limiter != nil {
+			limiters[method] = limiter
+		}
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		limiter, ok := limiters[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		key, err := keyOf(ctx)
+		if err != nil {
+			return nil, util.GrpcError(err)
+		}
+
+		allowed, retryAfter := limiter.Allow(info.FullMethod+" "+key, time.Now())
+		if !allowed {
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(ratelimit.RetryAfterSeconds(retryAfter))))
+			return nil, util.GrpcError(model.ErrRateLimited)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// grpcAccountKey limits per account, it runs after AuthorizationInterceptor.
+func grpcAccountKey(ctx context.Context) (string, error) {
+	accountID, err := util.AccountIDFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return accountID.String(), nil
+}
+
+// peerKey is the host of the peer address, the port changes with every
+// connection of the same client.
+func peerKey(ctx context.Context) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "", nil
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String(), nil
+	}
+
+	return host, nil
+}
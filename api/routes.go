@@ -4,7 +4,7 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
 	"github.com/hokdre/mini-ewallet/internal/controller"
 	"github.com/hokdre/mini-ewallet/internal/model"
 	"github.com/hokdre/mini-ewallet/pkg/util"
@@ -15,22 +15,37 @@ import (
 func setupRoutes(
 	e *echo.Echo,
 	walletHandler *controller.WalletHttpController,
-	encryption util.Encryption,
+	tokenHandler *controller.TokenHttpController,
+	tokenService internal.TokenService,
+	limits RateLimits,
 ) {
 	e.Logger.SetLevel(log.DEBUG)
+	read := RequireScope(model.TokenScope.WalletRead)
+	write := RequireScope(model.TokenScope.WalletWrite)
+	readLimit := RateLimitMiddleware(limits.WalletRead, accountKey)
+	writeLimit := RateLimitMiddleware(limits.WalletWrite, accountKey)
+
 	protected := e.Group("/api/v1/wallet")
-	protected.Use(AuthorizationMiddleware(encryption))
-	protected.GET("", walletHandler.Get)
-	protected.POST("", walletHandler.Enable)
-	protected.PATCH("", walletHandler.Disable)
-	protected.GET("/transactions", walletHandler.GetTransactions)
-	protected.POST("/deposits", walletHandler.Deposit)
-	protected.POST("/withdrawals", walletHandler.Withdrawal)
+	protected.Use(AuthorizationMiddleware(tokenService))
+	protected.GET("", walletHandler.Get, read, readLimit)
+	protected.POST("", walletHandler.Enable, write, writeLimit)
+	protected.PATCH("", walletHandler.Disable, write, writeLimit)
+	protected.GET("/transactions", walletHandler.GetTransactions, read, readLimit)
+	protected.GET("/statements", walletHandler.Statement, read, readLimit)
+	protected.POST("/transactions/:id/refunds", walletHandler.Refund, write, writeLimit)
+	protected.POST("/deposits", walletHandler.Deposit, write, writeLimit)
+	protected.POST("/withdrawals", walletHandler.Withdrawal, write, writeLimit)
+	protected.POST("/transfers", walletHandler.Transfer, write, writeLimit)
+	protected.POST("/holds", walletHandler.Authorize, write, writeLimit)
+	protected.POST("/holds/:id/capture", walletHandler.Capture, write, writeLimit)
+	protected.POST("/holds/:id/void", walletHandler.Void, write, writeLimit)
 
-	e.POST("/api/v1/init", walletHandler.Init)
+	e.POST("/api/v1/init", walletHandler.Init, RateLimitMiddleware(limits.Init, ipKey))
+	e.POST("/api/v1/token/refresh", tokenHandler.Refresh, RateLimitMiddleware(limits.Token, ipKey))
+	e.POST("/api/v1/token/revoke", tokenHandler.Revoke)
 }
 
-func AuthorizationMiddleware(encryption util.Encryption) func(next echo.HandlerFunc) echo.HandlerFunc {
+func AuthorizationMiddleware(tokenService internal.TokenService) func(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
 			// Get the Authorization header
@@ -59,25 +74,40 @@ func AuthorizationMiddleware(encryption util.Encryption) func(next echo.HandlerF
 					model.ErrLoginInfoUknown,
 				)
 			}
-			token, err := encryption.Decrypt(headers[1])
+
+			token, err := tokenService.Verify(
+				ctx.Request().Context(),
+				headers[1],
+				model.TokenType.Access,
+			)
 			if err != nil {
-				return util.SendError(
-					ctx,
-					http.StatusUnauthorized,
-					model.ErrLoginInfoUknown,
-				)
+				return util.SendFailedOrError(ctx, err)
 			}
 
-			accountID, err := uuid.Parse(token)
+			util.SetToken(ctx, token)
+			return next(ctx)
+		}
+	}
+}
+
+// RequireScope rejects tokens not granted scope, it runs after
+// AuthorizationMiddleware.
+func RequireScope(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			token, err := util.GetToken(ctx)
 			if err != nil {
+				return util.SendError(ctx, http.StatusUnauthorized, err)
+			}
+
+			if !token.HasScope(scope) {
 				return util.SendError(
 					ctx,
-					http.StatusUnauthorized,
-					model.ErrLoginInfoUknown,
+					http.StatusForbidden,
+					model.ErrInsufficientScope,
 				)
 			}
 
-			util.SetAccountID(ctx, accountID)
 			return next(ctx)
 		}
 	}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var tracer = otel.Tracer("github.com/hokdre/mini-ewallet/api")
+
+// TracingMiddleware continues the trace of the W3C traceparent header, or
+// starts one, and runs the request in its server span.
+func TracingMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			req := ctx.Request()
+			parent := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			spanCtx, span := tracer.Start(
+				parent,
+				req.Method+" "+route(ctx),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(req.Method),
+					semconv.HTTPRoute(route(ctx)),
+					semconv.URLPath(req.URL.Path),
+				),
+			)
+			defer span.End()
+
+			ctx.SetRequest(req.WithContext(spanCtx))
+			err := next(ctx)
+
+			code := responseStatus(ctx, err)
+			span.SetAttributes(semconv.HTTPResponseStatusCode(code))
+			if code >= http.StatusInternalServerError {
+				span.SetStatus(otelcodes.Error, http.StatusText(code))
+			}
+			if err != nil {
+				span.RecordError(err)
+			}
+
+			return err
+		}
+	}
+}
+
+// TracingInterceptor is the gRPC counterpart of TracingMiddleware, the
+// trace context travels in the metadata.
+func TracingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		parent := otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+		service, method := info.FullMethod, info.FullMethod
+		if i := strings.LastIndex(info.FullMethod, "/"); i > 0 {
+			service, method = strings.TrimPrefix(info.FullMethod[:i], "/"), info.FullMethod[i+1:]
+		}
+		ctx, span := tracer.Start(
+			parent,
+			strings.TrimPrefix(info.FullMethod, "/"),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.RPCSystemGRPC,
+				semconv.RPCService(service),
+				semconv.RPCMethod(method),
+			),
+		)
+		defer span.End()
+
+		res, err := handler(ctx, req)
+		if err != nil {
+			span.RecordError(err)
+			if isServerError(status.Code(err)) {
+				span.SetStatus(otelcodes.Error, err.Error())
+			}
+		}
+
+		return res, err
+	}
+}
+
+// isServerError tells the codes that mean our fault, the way a 5xx does.
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// metadataCarrier lets the propagator read incoming gRPC metadata.
+type metadataCarrier metadata.MD
+
+func (m metadataCarrier) Get(key string) string {
+	values := metadata.MD(m).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (m metadataCarrier) Set(key string, value string) {
+	metadata.MD(m).Set(key, value)
+}
+
+func (m metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
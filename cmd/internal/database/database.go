@@ -0,0 +1,39 @@
+// Package database opens the sql storage configured for the commands.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hokdre/mini-ewallet/config"
+	"github.com/hokdre/mini-ewallet/pkg/persistence"
+)
+
+// Open opens the postgres or sqlite database of cfg, sqlite takes the same
+// queries as postgres. The memory storage has no database to open.
+func Open(cfg config.Config) (*sql.DB, error) {
+	switch cfg.Storage {
+	case config.StorageSQLite:
+		return persistence.OpenSQLiteDB(
+			persistence.SQLiteConfig{
+				Path:        cfg.SQLitePath,
+				BusyTimeout: cfg.SQLiteBusyTimeout,
+			},
+		)
+	case config.StoragePostgres, "":
+		return persistence.OpenPostgreDB(
+			persistence.Config{
+				Host:        cfg.PostgreHost,
+				Username:    cfg.PostgreUsername,
+				Password:    cfg.PostgrePassword,
+				DB:          cfg.PostgreDB,
+				Port:        cfg.PostgrePort,
+				SSLMode:     cfg.PostgreSSLMode,
+				MaxIdleConn: cfg.PostgreMaxIdleConn,
+				MaxOpenConn: cfg.PostgreMaxOpenConn,
+			},
+		)
+	default:
+		return nil, fmt.Errorf("storage %q has no database", cfg.Storage)
+	}
+}
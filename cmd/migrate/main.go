@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/hokdre/mini-ewallet/cmd/internal/database"
+	"github.com/hokdre/mini-ewallet/config"
+	"github.com/hokdre/mini-ewallet/migrations"
+	"github.com/hokdre/mini-ewallet/pkg/migration"
+)
+
+const usage = `usage: migrate <command>
+
+commands:
+  up            apply every pending migration
+  down [steps]  roll back the latest steps migrations, default 1
+  status        list migrations and when they were applied`
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	cfg := config.Init()
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("failed open db : %s", err)
+	}
+	defer db.Close()
+
+	scripts, err := migration.Load(migrations.FS)
+	if err != nil {
+		log.Fatalf("failed load migrations : %s", err)
+	}
+	migrator := migration.New(db, scripts)
+	ctx := context.Background()
+
+	switch os.Args[1] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied  %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("failed migrate up : %s", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				log.Fatalf("invalid steps %q", os.Args[2])
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("failed migrate down : %s", err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("failed read status : %s", err)
+		}
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, appliedAt)
+		}
+	default:
+		log.Fatal(usage)
+	}
+}
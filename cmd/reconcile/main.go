@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/cmd/internal/database"
+	"github.com/hokdre/mini-ewallet/config"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/reconciliation"
+)
+
+// exitMismatch is returned when the report has findings, failures to run
+// the checks exit with 1.
+const exitMismatch = 2
+
+func main() {
+	format := flag.String("format", "json", "report format, json or csv")
+	pendingAge := flag.Duration("pending-age", 5*time.Minute, "report transactions pending for longer than this")
+	flag.Parse()
+
+	if *format != "json" && *format != "csv" {
+		log.Fatalf("unknown format %q", *format)
+	}
+
+	cfg := config.Init()
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("failed open db : %s", err)
+	}
+	defer db.Close()
+
+	service := reconciliation.NewReconciliationService(reconciliation.Config{
+		TxRepository:             internal.NewTxRepository(db),
+		ReconciliationRepository: reconciliation.NewReconciliationRepository(db),
+		PendingAge:               *pendingAge,
+	})
+
+	report, err := service.Reconcile(context.Background(), time.Now())
+	if err != nil {
+		log.Fatalf("failed reconcile : %s", err)
+	}
+
+	if *format == "csv" {
+		err = reconciliation.WriteCSV(os.Stdout, report)
+	} else {
+		err = reconciliation.WriteJSON(os.Stdout, report)
+	}
+	if err != nil {
+		log.Fatalf("failed write report : %s", err)
+	}
+
+	if !report.IsClean() {
+		fmt.Fprintf(os.Stderr, "%d of %d wallets drifted, %d stuck pending, %d orphans\n",
+			len(report.Drifts), report.Wallets, len(report.StuckPending), len(report.Orphans))
+		db.Close()
+		os.Exit(exitMismatch)
+	}
+}
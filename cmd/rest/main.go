@@ -2,78 +2,211 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/hokdre/mini-ewallet/api"
+	"github.com/hokdre/mini-ewallet/cmd/internal/database"
 	"github.com/hokdre/mini-ewallet/config"
 	"github.com/hokdre/mini-ewallet/internal"
 	"github.com/hokdre/mini-ewallet/internal/account"
+	"github.com/hokdre/mini-ewallet/internal/audit"
 	"github.com/hokdre/mini-ewallet/internal/controller"
+	"github.com/hokdre/mini-ewallet/internal/fee"
+	"github.com/hokdre/mini-ewallet/internal/hold"
+	"github.com/hokdre/mini-ewallet/internal/ledger"
+	"github.com/hokdre/mini-ewallet/internal/memory"
+	"github.com/hokdre/mini-ewallet/internal/outbox"
+	"github.com/hokdre/mini-ewallet/internal/token"
 	"github.com/hokdre/mini-ewallet/internal/transaction"
 	"github.com/hokdre/mini-ewallet/internal/wallet"
-	"github.com/hokdre/mini-ewallet/pkg/persistence"
+	"github.com/hokdre/mini-ewallet/internal/webhook"
+	"github.com/hokdre/mini-ewallet/migrations"
+	"github.com/hokdre/mini-ewallet/pkg/metrics"
+	"github.com/hokdre/mini-ewallet/pkg/migration"
+	"github.com/hokdre/mini-ewallet/pkg/ratelimit"
+	"github.com/hokdre/mini-ewallet/pkg/tracing"
 	"github.com/hokdre/mini-ewallet/pkg/util"
 )
 
+const tracingFlushTimeout = 5 * time.Second
+
 func main() {
 	cfg := config.Init()
 
-	// persistence
-	db, err := persistence.OpenPostgreDB(
-		persistence.Config{
-			Host:        cfg.PostgreHost,
-			Username:    cfg.PostgreUsername,
-			Password:    cfg.PostgrePassword,
-			DB:          cfg.PostgreDB,
-			Port:        cfg.PostgrePort,
-			SSLMode:     cfg.PostgreSSLMode,
-			MaxIdleConn: cfg.PostgreMaxIdleConn,
-			MaxOpenConn: cfg.PostgreMaxOpenConn,
-		},
-	)
+	shutdownTracing, err := tracing.Start(context.Background(), tracing.Config{
+		ServiceName:  "mini-ewallet",
+		Exporter:     cfg.TraceExporter,
+		OTLPEndpoint: cfg.TraceOTLPEndpoint,
+		OTLPInsecure: cfg.TraceOTLPInsecure,
+		File:         cfg.TraceFile,
+		SampleRatio:  cfg.TraceSampleRatio,
+	})
 	if err != nil {
-		log.Fatalf("failed open db : %s", err)
+		log.Fatalf("failed start tracing : %s", err)
 	}
 
 	// repository
-	accountRepo := account.NewAccountRepo(db)
-	walletRepo := wallet.NewWalletRepository(db)
-	transactionRepo := transaction.NewAccountRepo(db)
-	txRepo := internal.NewTxRepository(db)
+	repos := openRepositories(cfg)
 
 	// util
 	validator := util.NewValidator()
-	encryption, err := util.NewAesEncryption(cfg.AESSecret)
+	decryptionKeys, err := util.ParseAesKeys(cfg.AESDecryptionKeys)
+	if err != nil {
+		log.Fatalf("failed parse decryption keys : %s", err)
+	}
+	encryption, err := util.NewAesEncryption(
+		util.AesKey{ID: cfg.AESKeyID, Secret: cfg.AESSecret},
+		decryptionKeys...,
+	)
 	if err != nil {
 		log.Fatalf("failed construct encryption : %s", err)
 	}
 
 	// service
+	tokenService := token.NewTokenService(token.Config{
+		Encryption:      encryption,
+		TokenRepository: repos.token,
+		AccessTTL:       cfg.TokenAccessTTL,
+		RefreshTTL:      cfg.TokenRefreshTTL,
+	})
+
+	limits := wallet.Limits{
+		Deposit: wallet.Limit{
+			MaxAmount:     cfg.LimitDepositMaxAmount,
+			DailyAmount:   cfg.LimitDepositDailyAmount,
+			MonthlyAmount: cfg.LimitDepositMonthlyAmount,
+			DailyCount:    cfg.LimitDepositDailyCount,
+			MonthlyCount:  cfg.LimitDepositMonthlyCount,
+		},
+		Withdrawal: wallet.Limit{
+			MaxAmount:     cfg.LimitWithdrawalMaxAmount,
+			DailyAmount:   cfg.LimitWithdrawalDailyAmount,
+			MonthlyAmount: cfg.LimitWithdrawalMonthlyAmount,
+			DailyCount:    cfg.LimitWithdrawalDailyCount,
+			MonthlyCount:  cfg.LimitWithdrawalMonthlyCount,
+		},
+		MaxBalance: cfg.LimitMaxBalance,
+	}
+
+	withdrawalFee, err := fee.New(fee.Config{
+		Type:        cfg.FeeWithdrawalType,
+		Flat:        cfg.FeeWithdrawalFlat,
+		BasisPoints: cfg.FeeWithdrawalBasisPoints,
+		Tiers:       cfg.FeeWithdrawalTiers,
+		Min:         cfg.FeeWithdrawalMin,
+		Max:         cfg.FeeWithdrawalMax,
+	})
+	if err != nil {
+		log.Fatalf("failed construct withdrawal fee : %s", err)
+	}
+	transferFee, err := fee.New(fee.Config{
+		Type:        cfg.FeeTransferType,
+		Flat:        cfg.FeeTransferFlat,
+		BasisPoints: cfg.FeeTransferBasisPoints,
+		Tiers:       cfg.FeeTransferTiers,
+		Min:         cfg.FeeTransferMin,
+		Max:         cfg.FeeTransferMax,
+	})
+	if err != nil {
+		log.Fatalf("failed construct transfer fee : %s", err)
+	}
+
 	walletService := wallet.NewWalletService(
 		wallet.Config{
-			AccountRepo:           accountRepo,
-			WalletRepository:      walletRepo,
-			TransactionRepository: transactionRepo,
-			TxRepository:          txRepo,
+			AccountRepo:           repos.account,
+			WalletRepository:      repos.wallet,
+			TransactionRepository: repos.transaction,
+			TxRepository:          repos.tx,
+			LedgerRepository:      repos.ledger,
+			HoldRepository:        repos.hold,
+			OutboxRepository:      repos.outbox,
+			AuditRepository:       repos.audit,
+			HoldExpiry:            cfg.HoldExpiry,
+			PendingRecoveryAge:    cfg.PendingRecoveryAge,
+			Limits:                limits,
+			WithdrawalFee:         withdrawalFee,
+			TransferFee:           transferFee,
+			TxTimeout:             cfg.TxTimeout,
 			Validator:             validator,
-			Encryption:            encryption,
+			TokenService:          tokenService,
 		},
 	)
 
+	// background jobs
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go walletService.RunHoldExpiry(jobCtx, cfg.HoldSweepInterval)
+	go walletService.RunPendingRecovery(jobCtx, cfg.PendingRecoveryInterval)
+	go tokenService.RunRevocationSweep(jobCtx, cfg.TokenRevocationSweepInterval)
+
+	webhookCfg := webhook.Config{
+		OutboxRepository: repos.outbox,
+		Endpoints:        cfg.WebhookEndpoints,
+		Secret:           cfg.WebhookSecret,
+		MaxAttempts:      cfg.WebhookMaxAttempts,
+		BaseBackoff:      cfg.WebhookBaseBackoff,
+		MaxBackoff:       cfg.WebhookMaxBackoff,
+	}
+	if err := webhookCfg.Validate(); err != nil {
+		log.Fatalf("failed construct webhook dispatcher : %s", err)
+	}
+	dispatcher := webhook.NewDispatcher(webhookCfg)
+	go dispatcher.Run(jobCtx, cfg.WebhookSweepInterval)
+
 	// http handler
 	walletHandler := controller.NewWalletController(walletService)
+	tokenHandler := controller.NewTokenController(tokenService)
 
 	// start server
+	rateLimits := api.RateLimits{
+		Init: ratelimit.Config{
+			PerMinute: cfg.RateLimitInitPerMinute,
+			Burst:     cfg.RateLimitInitBurst,
+		},
+		Token: ratelimit.Config{
+			PerMinute: cfg.RateLimitTokenPerMinute,
+			Burst:     cfg.RateLimitTokenBurst,
+		},
+		WalletRead: ratelimit.Config{
+			PerMinute: cfg.RateLimitWalletReadPerMinute,
+			Burst:     cfg.RateLimitWalletReadBurst,
+		},
+		WalletWrite: ratelimit.Config{
+			PerMinute: cfg.RateLimitWalletWritePerMinute,
+			Burst:     cfg.RateLimitWalletWriteBurst,
+		},
+	}
+	metricsPort := ""
+	if cfg.MetricsPORT != "" {
+		metricsPort = ":" + cfg.MetricsPORT
+	}
 	api.HTTPStart(api.Config{
 		PORT:          ":" + cfg.RestPORT,
 		ReadTimeOut:   cfg.RestReadTimeOut,
 		WriteTimeOut:  cfg.RestWriteTimeOut,
 		WalletHandler: walletHandler,
-		Encryption:    encryption,
+		TokenHandler:  tokenHandler,
+		TokenService:  tokenService,
+		RateLimits:    rateLimits,
+		TrustProxy:    cfg.RestTrustProxy,
+		Readiness:     repos.readiness,
+		DrainDelay:    cfg.RestDrainDelay,
+		MetricsPORT:   metricsPort,
 	})
+	if cfg.GrpcPORT != "" {
+		api.GrpcStart(api.GrpcConfig{
+			PORT:          ":" + cfg.GrpcPORT,
+			WalletHandler: controller.NewWalletGrpcController(walletService),
+			TokenService:  tokenService,
+			RateLimits:    rateLimits,
+		})
+	}
 
 	// shutdown
 	quit := make(chan os.Signal, 1)
@@ -86,5 +219,104 @@ func main() {
 		cfg.RestShoutDownTimeOut,
 	)
 	defer cancel()
+	// http goes first, /readyz has to turn not ready before anything stops
 	api.HttpDown(ctx)
+	if cfg.GrpcPORT != "" {
+		api.GrpcDown(ctx)
+	}
+
+	// the spans of the last requests are flushed once the servers are down
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), tracingFlushTimeout)
+	defer cancelFlush()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Printf("failed flush traces : %s \n", err)
+	}
+}
+
+type repositories struct {
+	account     internal.AccountRepository
+	wallet      internal.WalletRepository
+	transaction internal.TransactionRepository
+	tx          internal.TxRepository
+	ledger      internal.LedgerRepository
+	hold        internal.HoldRepository
+	outbox      internal.OutboxRepository
+	audit       internal.AuditRepository
+	token       internal.TokenRepository
+	// readiness checks the database behind the repositories, memory has none.
+	readiness map[string]api.HealthCheck
+}
+
+func openRepositories(cfg config.Config) repositories {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		// nothing survives a restart, meant for demos and local runs
+		store := memory.NewStore()
+		return repositories{
+			account:     memory.NewAccountRepository(store),
+			wallet:      memory.NewWalletRepository(store),
+			transaction: memory.NewTransactionRepository(store),
+			tx:          store,
+			ledger:      memory.NewLedgerRepository(store),
+			hold:        memory.NewHoldRepository(store),
+			outbox:      memory.NewOutboxRepository(store),
+			audit:       memory.NewAuditRepository(store),
+			token:       memory.NewTokenRepository(store),
+		}
+	case config.StoragePostgres, config.StorageSQLite, "":
+	default:
+		log.Fatalf("unknown storage %q", cfg.Storage)
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("failed open db : %s", err)
+	}
+	schema := schemaCheck(db)
+	if cfg.MigrationRequireLatest {
+		if err := schema(context.Background()); err != nil {
+			log.Fatalf("%s, run go run ./cmd/migrate up", err)
+		}
+	}
+	if err := metrics.RegisterDB(db, "wallet"); err != nil {
+		log.Fatalf("failed register db metrics : %s", err)
+	}
+
+	return repositories{
+		account:     account.NewAccountRepo(db),
+		wallet:      wallet.NewWalletRepository(db),
+		transaction: transaction.NewAccountRepo(db),
+		tx:          internal.NewTxRepository(db),
+		ledger:      ledger.NewLedgerRepository(db),
+		hold:        hold.NewHoldRepository(db),
+		outbox:      outbox.NewOutboxRepository(db),
+		audit:       audit.NewAuditRepository(db),
+		token:       token.NewTokenRepository(db),
+		readiness: map[string]api.HealthCheck{
+			"database": db.PingContext,
+			"schema":   schema,
+		},
+	}
+}
+
+// schemaCheck fails while migrations are pending, the queries of this
+// build may need columns the database does not have yet.
+func schemaCheck(db *sql.DB) api.HealthCheck {
+	scripts, err := migration.Load(migrations.FS)
+	if err != nil {
+		log.Fatalf("failed load migrations : %s", err)
+	}
+	migrator := migration.New(db, scripts)
+
+	return func(ctx context.Context) error {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			return fmt.Errorf("schema is behind by %d migrations", len(pending))
+		}
+
+		return nil
+	}
 }
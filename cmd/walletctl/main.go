@@ -0,0 +1,468 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/cmd/internal/database"
+	"github.com/hokdre/mini-ewallet/config"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/account"
+	"github.com/hokdre/mini-ewallet/internal/audit"
+	"github.com/hokdre/mini-ewallet/internal/hold"
+	"github.com/hokdre/mini-ewallet/internal/ledger"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/internal/outbox"
+	"github.com/hokdre/mini-ewallet/internal/token"
+	"github.com/hokdre/mini-ewallet/internal/transaction"
+	"github.com/hokdre/mini-ewallet/internal/wallet"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+)
+
+const usage = `usage: walletctl <command> [flags] <external_id>
+
+commands:
+  account       show the account
+  wallet        show the wallet state, fails when the balance drifted from the ledger
+  transactions  list transactions, newest first
+                  -limit 20, -cursor, -type and -status narrow the list
+  audit         list the status changes of the wallet, newest first
+  enable        enable the wallet
+  disable       disable the wallet
+                  -version  required, the version shown by wallet
+  token         issue an access and refresh token pair, recorded in the audit log
+  adjust        post a manual adjustment
+                  -amount   required, a negative amount debits the wallet
+                  -reason   required, stored with the transaction
+                  -reference  makes retries safe, defaults to a new id
+
+enable, disable, token and adjust record -operator, which defaults to $USER`
+
+const timeFormat = "2006-01-02 15:04:05"
+
+type walletctl struct {
+	accounts        internal.AccountRepository
+	wallets         internal.WalletRepository
+	transactionRepo internal.TransactionRepository
+	ledgerRepo      internal.LedgerRepository
+	auditRepo       internal.AuditRepository
+	txRepo          internal.TxRepository
+	walletService   internal.WalletService
+	tokenService    internal.TokenService
+	out             *tabwriter.Writer
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal(usage)
+	}
+
+	command := os.Args[1]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	limit := flags.Int("limit", 20, "")
+	cursor := flags.String("cursor", "", "")
+	types := flags.String("type", "", "")
+	statuses := flags.String("status", "", "")
+	amount := flags.Int64("amount", 0, "")
+	reason := flags.String("reason", "", "")
+	reference := flags.String("reference", "", "")
+	version := flags.Int64("version", 0, "")
+	operator := flags.String("operator", os.Getenv("USER"), "")
+	_ = flags.Parse(os.Args[2:])
+	if flags.NArg() != 1 {
+		log.Fatal(usage)
+	}
+	externalID := flags.Arg(0)
+
+	cfg := config.Init()
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("failed open db : %s", err)
+	}
+	defer db.Close()
+
+	ctl, err := newWalletctl(cfg, db)
+	if err != nil {
+		log.Fatalf("failed construct services : %s", err)
+	}
+	defer ctl.out.Flush()
+
+	ctx := util.ContextWithOperator(context.Background(), strings.TrimSpace(*operator))
+	switch command {
+	case "account":
+		err = ctl.account(ctx, externalID)
+	case "wallet":
+		err = ctl.wallet(ctx, externalID)
+	case "transactions":
+		err = ctl.transactions(ctx, externalID, internal.TransactionFilter{
+			Types:    splitValues(*types),
+			Statuses: splitValues(*statuses),
+			Limit:    *limit,
+		}, *cursor)
+	case "audit":
+		err = ctl.audit(ctx, externalID)
+	case "enable":
+		err = ctl.setStatus(ctx, externalID, *version, ctl.walletService.Enable)
+	case "disable":
+		err = ctl.setStatus(ctx, externalID, *version, ctl.walletService.Disable)
+	case "token":
+		err = ctl.token(ctx, externalID)
+	case "adjust":
+		err = ctl.adjust(ctx, externalID, *amount, *reason, *reference)
+	default:
+		log.Fatal(usage)
+	}
+	if err != nil {
+		ctl.out.Flush()
+		log.Fatalf("failed %s : %s", command, err)
+	}
+}
+
+func newWalletctl(cfg config.Config, db *sql.DB) (*walletctl, error) {
+	decryptionKeys, err := util.ParseAesKeys(cfg.AESDecryptionKeys)
+	if err != nil {
+		return nil, err
+	}
+	encryption, err := util.NewAesEncryption(
+		util.AesKey{ID: cfg.AESKeyID, Secret: cfg.AESSecret},
+		decryptionKeys...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := account.NewAccountRepo(db)
+	wallets := wallet.NewWalletRepository(db)
+	transactionRepo := transaction.NewAccountRepo(db)
+	ledgerRepo := ledger.NewLedgerRepository(db)
+	auditRepo := audit.NewAuditRepository(db)
+	txRepo := internal.NewTxRepository(db)
+	tokenService := token.NewTokenService(token.Config{
+		Encryption:      encryption,
+		TokenRepository: token.NewTokenRepository(db),
+		AccessTTL:       cfg.TokenAccessTTL,
+		RefreshTTL:      cfg.TokenRefreshTTL,
+	})
+	walletService := wallet.NewWalletService(wallet.Config{
+		AccountRepo:           accounts,
+		WalletRepository:      wallets,
+		TransactionRepository: transactionRepo,
+		TxRepository:          txRepo,
+		LedgerRepository:      ledgerRepo,
+		HoldRepository:        hold.NewHoldRepository(db),
+		OutboxRepository:      outbox.NewOutboxRepository(db),
+		AuditRepository:       auditRepo,
+		Validator:             util.NewValidator(),
+		TokenService:          tokenService,
+		TxTimeout:             cfg.TxTimeout,
+	})
+
+	return &walletctl{
+		accounts:        accounts,
+		wallets:         wallets,
+		transactionRepo: transactionRepo,
+		ledgerRepo:      ledgerRepo,
+		auditRepo:       auditRepo,
+		txRepo:          txRepo,
+		walletService:   walletService,
+		tokenService:    tokenService,
+		out:             tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0),
+	}, nil
+}
+
+func (c *walletctl) findAccount(ctx context.Context, externalID string) (model.Account, error) {
+	acc, err := c.accounts.Get(ctx, internal.AccountFilter{
+		ExternalIDs: []string{externalID},
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Account{}, fmt.Errorf("no account with external id %q", externalID)
+	}
+
+	return acc, err
+}
+
+func (c *walletctl) account(ctx context.Context, externalID string) error {
+	acc, err := c.findAccount(ctx, externalID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "id\t%s\n", acc.ID)
+	fmt.Fprintf(c.out, "external_id\t%s\n", acc.ExternalCustomerID)
+	fmt.Fprintf(c.out, "created_at\t%s\n", acc.CreatedAt.Format(timeFormat))
+	return nil
+}
+
+// wallet reads the repository directly, the service hides disabled wallets.
+// The balance is checked against the ledger, a drift fails the command.
+func (c *walletctl) wallet(ctx context.Context, externalID string) error {
+	acc, err := c.findAccount(ctx, externalID)
+	if err != nil {
+		return err
+	}
+
+	w, err := c.wallets.GetOne(ctx, internal.WalletFilter{
+		OwnedBies: []string{acc.ID.String()},
+	})
+	if err != nil {
+		return err
+	}
+
+	ledgerBalance, err := c.ledgerRepo.GetWalletBalance(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+
+	c.printWallet(w)
+	fmt.Fprintf(c.out, "ledger_balance\t%d\n", ledgerBalance)
+	if ledgerBalance != w.Balance {
+		return fmt.Errorf("balance %d drifted from the ledger balance %d", w.Balance, ledgerBalance)
+	}
+	return nil
+}
+
+func (c *walletctl) printWallet(w model.Wallet) {
+	fmt.Fprintf(c.out, "id\t%s\n", w.ID)
+	fmt.Fprintf(c.out, "owned_by\t%s\n", w.OwnedBy)
+	fmt.Fprintf(c.out, "status\t%s\n", w.Status)
+	fmt.Fprintf(c.out, "version\t%d\n", w.Version)
+	fmt.Fprintf(c.out, "enabled_at\t%s\n", formatTime(w.EnabledAt))
+	fmt.Fprintf(c.out, "disabled_at\t%s\n", formatTime(w.DisabledAt))
+	fmt.Fprintf(c.out, "balance\t%d\n", w.Balance)
+	fmt.Fprintf(c.out, "reserved\t%d\n", w.Reserved)
+	fmt.Fprintf(c.out, "available_balance\t%d\n", w.AvailableBalance())
+}
+
+func (c *walletctl) transactions(ctx context.Context, externalID string, filter internal.TransactionFilter, cursor string) error {
+	acc, err := c.findAccount(ctx, externalID)
+	if err != nil {
+		return err
+	}
+
+	w, err := c.wallets.GetOne(ctx, internal.WalletFilter{
+		OwnedBies: []string{acc.ID.String()},
+	})
+	if err != nil {
+		return err
+	}
+
+	if cursor != "" {
+		after, err := internal.DecodeTransactionCursor(cursor)
+		if err != nil {
+			return err
+		}
+		filter.After = &after
+	}
+	if filter.Limit < 1 {
+		return errors.New("limit must be positive")
+	}
+
+	// one extra row tells whether another page follows
+	limit := filter.Limit
+	filter.WalletIDs = []string{w.ID.String()}
+	filter.Limit = limit + 1
+	transactions, err := c.transactionRepo.List(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out, "ID\tTYPE\tSTATUS\tAMOUNT\tFEE\tREFERENCE\tTRANSACTED_AT\tREASON\tOPERATOR")
+	for i, t := range transactions {
+		if i == limit {
+			break
+		}
+		fmt.Fprintf(c.out, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\n",
+			t.ID, t.Type, t.Status, t.Amount, t.Fee, t.ReferenceID, formatTime(t.TransactedAt), t.Reason, t.Operator)
+	}
+
+	if len(transactions) > limit {
+		last := transactions[limit-1]
+		next := internal.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID.String()}
+		fmt.Fprintf(c.out, "\nnext page: -cursor %s\n", next.Encode())
+	}
+
+	return nil
+}
+
+// setStatus only lands on the version the operator has seen, zero would take
+// any version and skip the check.
+func (c *walletctl) setStatus(
+	ctx context.Context,
+	externalID string,
+	version int64,
+	change func(ctx context.Context, accountID uuid.UUID, version int64) (model.Wallet, error)) error {
+	if version < 1 {
+		return errors.New("-version is required, walletctl wallet shows it")
+	}
+	if util.OperatorFromContext(ctx) == "" {
+		return errors.New("-operator is required")
+	}
+
+	acc, err := c.findAccount(ctx, externalID)
+	if err != nil {
+		return err
+	}
+
+	w, err := change(ctx, acc.ID, version)
+	if err != nil {
+		return err
+	}
+
+	c.printWallet(w)
+	return nil
+}
+
+func (c *walletctl) audit(ctx context.Context, externalID string) error {
+	acc, err := c.findAccount(ctx, externalID)
+	if err != nil {
+		return err
+	}
+
+	w, err := c.wallets.GetOne(ctx, internal.WalletFilter{
+		OwnedBies: []string{acc.ID.String()},
+	})
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.auditRepo.List(ctx, w.ID.String())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out, "ID\tACTION\tOPERATOR\tDETAIL\tCREATED_AT")
+	for _, entry := range entries {
+		fmt.Fprintf(c.out, "%s\t%s\t%s\t%s\t%s\n",
+			entry.ID, entry.Action, entry.Operator, entry.Detail, entry.CreatedAt.Local().Format(timeFormat))
+	}
+
+	return nil
+}
+
+// token issues a token pair for the account, the pair is only printed once
+// the operator and the granted scopes are in the audit log of its wallet.
+func (c *walletctl) token(ctx context.Context, externalID string) error {
+	operator := util.OperatorFromContext(ctx)
+	if operator == "" {
+		return errors.New("-operator is required")
+	}
+
+	acc, err := c.findAccount(ctx, externalID)
+	if err != nil {
+		return err
+	}
+
+	w, err := c.wallets.GetOne(ctx, internal.WalletFilter{
+		OwnedBies: []string{acc.ID.String()},
+	})
+	if err != nil {
+		return err
+	}
+
+	tokens, err := c.tokenService.Issue(ctx, acc.ID)
+	if err != nil {
+		return err
+	}
+
+	issued, err := c.tokenService.Verify(ctx, tokens.AccessToken, model.TokenType.Access)
+	if err != nil {
+		return err
+	}
+
+	err = c.txRepo.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return c.auditRepo.CreateTx(ctx, tx, model.AuditEntry{
+			ID:        uuid.New(),
+			WalletID:  w.ID,
+			Action:    model.AuditAction.TokenIssued,
+			Operator:  operator,
+			Detail:    fmt.Sprintf("account %s scopes %s", acc.ID, strings.Join(issued.Scopes, ",")),
+			CreatedAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "token\t%s\n", tokens.AccessToken)
+	fmt.Fprintf(c.out, "token_expires_at\t%s\n", tokens.AccessExpiresAt.Local().Format(timeFormat))
+	fmt.Fprintf(c.out, "refresh_token\t%s\n", tokens.RefreshToken)
+	fmt.Fprintf(c.out, "refresh_token_expires_at\t%s\n", tokens.RefreshExpiresAt.Local().Format(timeFormat))
+	return nil
+}
+
+func (c *walletctl) adjust(ctx context.Context, externalID string, amount int64, reason string, reference string) error {
+	if amount == 0 {
+		return errors.New("-amount is required")
+	}
+	if strings.TrimSpace(reason) == "" {
+		return errors.New("-reason is required")
+	}
+	if util.OperatorFromContext(ctx) == "" {
+		return errors.New("-operator is required")
+	}
+
+	acc, err := c.findAccount(ctx, externalID)
+	if err != nil {
+		return err
+	}
+
+	adjustment := model.Transaction{
+		Type:        model.TransactionType.AdjustmentCredit,
+		Amount:      amount,
+		ReferenceID: reference,
+		Reason:      reason,
+	}
+	if amount < 0 {
+		adjustment.Type = model.TransactionType.AdjustmentDebit
+		adjustment.Amount = -amount
+	}
+
+	t, err := c.walletService.Adjust(ctx, acc.ID, adjustment)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.out, "id\t%s\n", t.ID)
+	fmt.Fprintf(c.out, "wallet_id\t%s\n", t.WalletID)
+	fmt.Fprintf(c.out, "type\t%s\n", t.Type)
+	fmt.Fprintf(c.out, "status\t%s\n", t.Status)
+	fmt.Fprintf(c.out, "amount\t%d\n", t.Amount)
+	fmt.Fprintf(c.out, "reference_id\t%s\n", t.ReferenceID)
+	fmt.Fprintf(c.out, "reason\t%s\n", t.Reason)
+	fmt.Fprintf(c.out, "operator\t%s\n", t.Operator)
+	fmt.Fprintf(c.out, "transacted_at\t%s\n", formatTime(t.TransactedAt))
+	return nil
+}
+
+func splitValues(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, strings.ToLower(v))
+		}
+	}
+
+	return values
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return t.Local().Format(timeFormat)
+}
@@ -8,12 +8,40 @@ import (
 	"github.com/kelseyhightower/envconfig"
 )
 
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+	StorageSQLite   = "sqlite"
+)
+
 type Config struct {
-	// REST SERVER
+	// STORAGE, postgres, sqlite or memory
+	Storage string `envconfig:"STORAGE" default:"postgres"`
+
+	// REST SERVER, trust proxy takes the client ip from X-Forwarded-For of proxies on private networks,
+	// drain delay keeps serving that long after /readyz turned not ready on shutdown
 	RestPORT             string        `envconfig:"REST_PORT"`
 	RestReadTimeOut      time.Duration `envconfig:"REST_READ_TIMEOUT"`
 	RestWriteTimeOut     time.Duration `envconfig:"REST_WRITE_TIMEOUT"`
 	RestShoutDownTimeOut time.Duration `envconfig:"REST_SHUTDOWN_TIMEOUT"`
+	RestTrustProxy       bool          `envconfig:"REST_TRUST_PROXY"`
+	RestDrainDelay       time.Duration `envconfig:"REST_DRAIN_DELAY" default:"0s"`
+
+	// RATE LIMIT, a token bucket per route and account, per ip for init and token refresh. Zero per minute means no limit
+	RateLimitInitPerMinute        float64 `envconfig:"RATE_LIMIT_INIT_PER_MINUTE" default:"10"`
+	RateLimitInitBurst            int     `envconfig:"RATE_LIMIT_INIT_BURST" default:"5"`
+	RateLimitTokenPerMinute       float64 `envconfig:"RATE_LIMIT_TOKEN_PER_MINUTE" default:"30"`
+	RateLimitTokenBurst           int     `envconfig:"RATE_LIMIT_TOKEN_BURST" default:"10"`
+	RateLimitWalletReadPerMinute  float64 `envconfig:"RATE_LIMIT_WALLET_READ_PER_MINUTE" default:"600"`
+	RateLimitWalletReadBurst      int     `envconfig:"RATE_LIMIT_WALLET_READ_BURST" default:"60"`
+	RateLimitWalletWritePerMinute float64 `envconfig:"RATE_LIMIT_WALLET_WRITE_PER_MINUTE" default:"120"`
+	RateLimitWalletWriteBurst     int     `envconfig:"RATE_LIMIT_WALLET_WRITE_BURST" default:"20"`
+
+	// GRPC SERVER, empty port leaves it off
+	GrpcPORT string `envconfig:"GRPC_PORT"`
+
+	// METRICS, served on a port of its own, empty port leaves them off
+	MetricsPORT string `envconfig:"METRICS_PORT"`
 
 	// POSTGRE
 	PostgreHost        string `envconfig:"POSTGRE_HOST"`
@@ -25,8 +53,74 @@ type Config struct {
 	PostgreMaxIdleConn int    `envconfig:"POSTGRE_MAX_IDLE_CONN"`
 	PostgreMaxOpenConn int    `envconfig:"POSTGRE_MAX_OPEN_CONN"`
 
+	// TRANSACTION, bounds every database transaction of a wallet operation, zero means no bound
+	TxTimeout time.Duration `envconfig:"TX_TIMEOUT"`
+
+	// SQLITE, the file is created when missing
+	SQLitePath        string        `envconfig:"SQLITE_PATH" default:"wallet.db"`
+	SQLiteBusyTimeout time.Duration `envconfig:"SQLITE_BUSY_TIMEOUT"`
+
+	// TRACE, exporter is otlp, stdout, file or none, empty is otlp with an endpoint and file without
+	TraceExporter     string  `envconfig:"TRACE_EXPORTER"`
+	TraceOTLPEndpoint string  `envconfig:"TRACE_OTLP_ENDPOINT"`
+	TraceOTLPInsecure bool    `envconfig:"TRACE_OTLP_INSECURE"`
+	TraceFile         string  `envconfig:"TRACE_FILE" default:"traces.json"`
+	TraceSampleRatio  float64 `envconfig:"TRACE_SAMPLE_RATIO" default:"1"`
+
+	// MIGRATION, refuse to start while migrations are pending
+	MigrationRequireLatest bool `envconfig:"MIGRATION_REQUIRE_LATEST"`
+
+	// HOLD
+	HoldExpiry        time.Duration `envconfig:"HOLD_EXPIRY"`
+	HoldSweepInterval time.Duration `envconfig:"HOLD_SWEEP_INTERVAL"`
+
+	// PENDING RECOVERY, pending transactions older than the age are resolved
+	PendingRecoveryAge      time.Duration `envconfig:"PENDING_RECOVERY_AGE"`
+	PendingRecoveryInterval time.Duration `envconfig:"PENDING_RECOVERY_INTERVAL"`
+
+	// LIMIT, zero means no limit, the withdrawal limits also cover outgoing
+	// transfers and captures
+	LimitDepositMaxAmount        int64 `envconfig:"LIMIT_DEPOSIT_MAX_AMOUNT"`
+	LimitDepositDailyAmount      int64 `envconfig:"LIMIT_DEPOSIT_DAILY_AMOUNT"`
+	LimitDepositMonthlyAmount    int64 `envconfig:"LIMIT_DEPOSIT_MONTHLY_AMOUNT"`
+	LimitDepositDailyCount       int   `envconfig:"LIMIT_DEPOSIT_DAILY_COUNT"`
+	LimitDepositMonthlyCount     int   `envconfig:"LIMIT_DEPOSIT_MONTHLY_COUNT"`
+	LimitWithdrawalMaxAmount     int64 `envconfig:"LIMIT_WITHDRAWAL_MAX_AMOUNT"`
+	LimitWithdrawalDailyAmount   int64 `envconfig:"LIMIT_WITHDRAWAL_DAILY_AMOUNT"`
+	LimitWithdrawalMonthlyAmount int64 `envconfig:"LIMIT_WITHDRAWAL_MONTHLY_AMOUNT"`
+	LimitWithdrawalDailyCount    int   `envconfig:"LIMIT_WITHDRAWAL_DAILY_COUNT"`
+	LimitWithdrawalMonthlyCount  int   `envconfig:"LIMIT_WITHDRAWAL_MONTHLY_COUNT"`
+	LimitMaxBalance              int64 `envconfig:"LIMIT_MAX_BALANCE"`
+
+	// FEE, type is flat, percentage or tiered, empty means no fee
+	FeeWithdrawalType        string `envconfig:"FEE_WITHDRAWAL_TYPE"`
+	FeeWithdrawalFlat        int64  `envconfig:"FEE_WITHDRAWAL_FLAT"`
+	FeeWithdrawalBasisPoints int64  `envconfig:"FEE_WITHDRAWAL_BASIS_POINTS"`
+	FeeWithdrawalTiers       string `envconfig:"FEE_WITHDRAWAL_TIERS"`
+	FeeWithdrawalMin         int64  `envconfig:"FEE_WITHDRAWAL_MIN"`
+	FeeWithdrawalMax         int64  `envconfig:"FEE_WITHDRAWAL_MAX"`
+	FeeTransferType          string `envconfig:"FEE_TRANSFER_TYPE"`
+	FeeTransferFlat          int64  `envconfig:"FEE_TRANSFER_FLAT"`
+	FeeTransferBasisPoints   int64  `envconfig:"FEE_TRANSFER_BASIS_POINTS"`
+	FeeTransferTiers         string `envconfig:"FEE_TRANSFER_TIERS"`
+	FeeTransferMin           int64  `envconfig:"FEE_TRANSFER_MIN"`
+	FeeTransferMax           int64  `envconfig:"FEE_TRANSFER_MAX"`
+
+	// WEBHOOK, endpoints is a comma separated list
+	WebhookEndpoints     []string      `envconfig:"WEBHOOK_ENDPOINTS"`
+	WebhookSecret        string        `envconfig:"WEBHOOK_SECRET"`
+	WebhookMaxAttempts   int           `envconfig:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookBaseBackoff   time.Duration `envconfig:"WEBHOOK_BASE_BACKOFF"`
+	WebhookMaxBackoff    time.Duration `envconfig:"WEBHOOK_MAX_BACKOFF"`
+	WebhookSweepInterval time.Duration `envconfig:"WEBHOOK_SWEEP_INTERVAL"`
+
 	// TOKEN
-	AESSecret string `envconfig:"AES_SECRET"`
+	AESSecret                    string        `envconfig:"AES_SECRET"`
+	AESKeyID                     string        `envconfig:"AES_KEY_ID" default:"1"`
+	AESDecryptionKeys            string        `envconfig:"AES_DECRYPTION_KEYS"`
+	TokenAccessTTL               time.Duration `envconfig:"TOKEN_ACCESS_TTL"`
+	TokenRefreshTTL              time.Duration `envconfig:"TOKEN_REFRESH_TTL"`
+	TokenRevocationSweepInterval time.Duration `envconfig:"TOKEN_REVOCATION_SWEEP_INTERVAL"`
 }
 
 var config Config
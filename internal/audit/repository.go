@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+const (
+	qCreate = `
+		INSERT INTO audit_logs (
+			id, wallet_id, action, operator, detail, created_at
+		) VALUES(
+			$1, $2, $3, $4, $5, $6
+		)
+	`
+
+	qList = `
+		SELECT
+			id, wallet_id, action, operator, detail, created_at
+		FROM audit_logs
+		WHERE wallet_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+)
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *auditRepository {
+	return &auditRepository{db: db}
+}
+
+func (a *auditRepository) CreateTx(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error {
+	stmt, err := tx.Prepare(qCreate)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(
+		ctx,
+		entry.ID,
+		entry.WalletID,
+		entry.Action,
+		entry.Operator,
+		entry.Detail,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// List returns the entries of the wallet, newest first.
+func (a *auditRepository) List(ctx context.Context, walletID string) ([]model.AuditEntry, error) {
+	rows, err := a.db.QueryContext(ctx, qList, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.AuditEntry{}
+	for rows.Next() {
+		entry := model.AuditEntry{}
+		err := rows.Scan(
+			&entry.ID,
+			&entry.WalletID,
+			&entry.Action,
+			&entry.Operator,
+			&entry.Detail,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepository(t *testing.T) {
+	t.Run("CreateTx", TestCreateTx)
+	t.Run("List", TestList)
+}
+
+func newEntry() model.AuditEntry {
+	return model.AuditEntry{
+		ID:        uuid.New(),
+		WalletID:  uuid.New(),
+		Action:    model.AuditAction.WalletDisabled,
+		Operator:  "alice",
+		Detail:    "ticket 123",
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestCreateTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		entry := newEntry()
+		mock.
+			ExpectPrepare(qCreate).
+			ExpectExec().
+			WithArgs(
+				entry.ID,
+				entry.WalletID,
+				entry.Action,
+				entry.Operator,
+				entry.Detail,
+				entry.CreatedAt,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &auditRepository{db: db}
+		err = repo.CreateTx(context.Background(), tx, entry)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Prepare", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		errExpected := errors.New("err")
+		mock.ExpectPrepare(qCreate).WillReturnError(errExpected)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &auditRepository{db: db}
+		err = repo.CreateTx(context.Background(), tx, newEntry())
+		assert.ErrorIs(t, err, errExpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestList(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		entry := newEntry()
+		mock.ExpectQuery(qList).
+			WithArgs(entry.WalletID.String()).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "wallet_id", "action", "operator", "detail", "created_at",
+			}).AddRow(
+				entry.ID,
+				entry.WalletID,
+				entry.Action,
+				entry.Operator,
+				entry.Detail,
+				entry.CreatedAt,
+			))
+
+		repo := &auditRepository{db: db}
+		entries, err := repo.List(context.Background(), entry.WalletID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, []model.AuditEntry{entry}, entries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Query", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errExpected := errors.New("err")
+		mock.ExpectQuery(qList).WillReturnError(errExpected)
+
+		repo := &auditRepository{db: db}
+		entries, err := repo.List(context.Background(), uuid.NewString())
+		assert.ErrorIs(t, err, errExpected)
+		assert.Nil(t, entries)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,13 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type AuditRepository interface {
+	CreateTx(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error
+	List(ctx context.Context, walletID string) ([]model.AuditEntry, error)
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	walletv1 "github.com/hokdre/mini-ewallet/pkg/pb/wallet/v1"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type WalletGrpcController struct {
+	walletv1.UnimplementedWalletServiceServer
+	walletService internal.WalletService
+}
+
+func NewWalletGrpcController(
+	walletService internal.WalletService,
+) *WalletGrpcController {
+	return &WalletGrpcController{
+		walletService: walletService,
+	}
+}
+
+func (w *WalletGrpcController) Init(ctx context.Context, req *walletv1.InitRequest) (*walletv1.InitResponse, error) {
+	tokens, err := w.walletService.Init(ctx, req.GetCustomerXid())
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	return &walletv1.InitResponse{
+		Token:                 tokens.AccessToken,
+		TokenExpiresAt:        timestamppb.New(tokens.AccessExpiresAt),
+		RefreshToken:          tokens.RefreshToken,
+		RefreshTokenExpiresAt: timestamppb.New(tokens.RefreshExpiresAt),
+	}, nil
+}
+
+func (w *WalletGrpcController) Enable(ctx context.Context, req *walletv1.EnableRequest) (*walletv1.EnableResponse, error) {
+	accountID, err := util.AccountIDFromContext(ctx)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	// the grpc api has no If-Match, the update still fails on a concurrent change
+	wallet, err := w.walletService.Enable(ctx, accountID, 0)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	return &walletv1.EnableResponse{Wallet: walletMessage(wallet)}, nil
+}
+
+func (w *WalletGrpcController) Disable(ctx context.Context, req *walletv1.DisableRequest) (*walletv1.DisableResponse, error) {
+	accountID, err := util.AccountIDFromContext(ctx)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	wallet, err := w.walletService.Disable(ctx, accountID, 0)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	return &walletv1.DisableResponse{Wallet: walletMessage(wallet)}, nil
+}
+
+func (w *WalletGrpcController) Get(ctx context.Context, req *walletv1.GetRequest) (*walletv1.GetResponse, error) {
+	accountID, err := util.AccountIDFromContext(ctx)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	wallet, err := w.walletService.Get(ctx, accountID)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	return &walletv1.GetResponse{Wallet: walletMessage(wallet)}, nil
+}
+
+func (w *WalletGrpcController) GetTransactions(ctx context.Context, req *walletv1.GetTransactionsRequest) (*walletv1.GetTransactionsResponse, error) {
+	accountID, err := util.AccountIDFromContext(ctx)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	filter, invalid := transactionFilterFromRequest(req)
+	if len(invalid) > 0 {
+		return nil, util.GrpcFailed(invalid)
+	}
+
+	transactions, next, err := w.walletService.GetTransactions(ctx, accountID, filter)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	res := &walletv1.GetTransactionsResponse{}
+	for _, t := range transactions {
+		res.Transactions = append(res.Transactions, transactionMessage(t))
+	}
+	if next != nil {
+		res.NextCursor = next.Encode()
+	}
+
+	return res, nil
+}
+
+// transactionFilterFromRequest mirrors parseTransactionFilter.
+func transactionFilterFromRequest(req *walletv1.GetTransactionsRequest) (internal.TransactionFilter, map[string]string) {
+	filter := internal.TransactionFilter{
+		Types:     lowerValues(req.GetTypes()),
+		Statuses:  lowerValues(req.GetStatuses()),
+		MinAmount: req.MinAmount,
+		MaxAmount: req.MaxAmount,
+		Limit:     int(req.GetLimit()),
+	}
+	invalid := map[string]string{}
+
+	if req.GetLimit() < 0 {
+		invalid["limit"] = "value is not valid"
+	}
+
+	if req.GetCursor() != "" {
+		cursor, err := internal.DecodeTransactionCursor(req.GetCursor())
+		if err != nil {
+			invalid["cursor"] = "value is not valid"
+		}
+		filter.After = &cursor
+	}
+
+	for key, ts := range map[string]*timestamppb.Timestamp{
+		"from": req.GetFrom(),
+		"to":   req.GetTo(),
+	} {
+		if ts == nil {
+			continue
+		}
+		if err := ts.CheckValid(); err != nil {
+			invalid[key] = "value is not valid"
+			continue
+		}
+
+		date := ts.AsTime()
+		if key == "from" {
+			filter.TransactedFrom = &date
+		} else {
+			filter.TransactedTo = &date
+		}
+	}
+
+	return filter, invalid
+}
+
+func (w *WalletGrpcController) Deposit(ctx context.Context, req *walletv1.DepositRequest) (*walletv1.DepositResponse, error) {
+	accountID, err := util.AccountIDFromContext(ctx)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	transaction, err := w.walletService.Deposit(ctx, accountID, model.Transaction{
+		Amount:      req.GetAmount(),
+		ReferenceID: req.GetReferenceId(),
+	})
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	return &walletv1.DepositResponse{Transaction: transactionMessage(transaction)}, nil
+}
+
+func (w *WalletGrpcController) Withdrawal(ctx context.Context, req *walletv1.WithdrawalRequest) (*walletv1.WithdrawalResponse, error) {
+	accountID, err := util.AccountIDFromContext(ctx)
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	transaction, err := w.walletService.Withdrawal(ctx, accountID, model.Transaction{
+		Amount:      req.GetAmount(),
+		ReferenceID: req.GetReferenceId(),
+	})
+	if err != nil {
+		return nil, util.GrpcError(err)
+	}
+
+	return &walletv1.WithdrawalResponse{Transaction: transactionMessage(transaction)}, nil
+}
+
+func walletMessage(wallet model.Wallet) *walletv1.Wallet {
+	return &walletv1.Wallet{
+		Id:               wallet.ID.String(),
+		OwnedBy:          wallet.OwnedBy.String(),
+		Status:           wallet.Status,
+		EnabledAt:        timestampMessage(wallet.EnabledAt),
+		DisabledAt:       timestampMessage(wallet.DisabledAt),
+		Balance:          wallet.Balance,
+		AvailableBalance: wallet.AvailableBalance(),
+	}
+}
+
+func transactionMessage(transaction model.Transaction) *walletv1.Transaction {
+	message := &walletv1.Transaction{
+		Id:             transaction.ID.String(),
+		WalletId:       transaction.WalletID.String(),
+		Type:           transaction.Type,
+		Status:         transaction.Status,
+		TransactedAt:   timestampMessage(transaction.TransactedAt),
+		Amount:         transaction.Amount,
+		Fee:            transaction.Fee,
+		GrossAmount:    transaction.GrossAmount(),
+		ReferenceId:    transaction.ReferenceID,
+		RefundedAmount: transaction.RefundedAmount,
+	}
+	if transaction.ParentID != nil {
+		message.ParentId = transaction.ParentID.String()
+	}
+
+	return message
+}
+
+func timestampMessage(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"github.com/labstack/echo/v4"
+)
+
+type TokenHttpController struct {
+	tokenService internal.TokenService
+}
+
+func NewTokenController(
+	tokenService internal.TokenService,
+) *TokenHttpController {
+	return &TokenHttpController{
+		tokenService: tokenService,
+	}
+}
+
+func (t *TokenHttpController) Refresh(ctx echo.Context) error {
+	payload := new(struct {
+		RefreshToken string `json:"refresh_token" form:"refresh_token"`
+	})
+	if err := ctx.Bind(payload); err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"body": err.Error(),
+			},
+		)
+	}
+
+	tokens, err := t.tokenService.Refresh(ctx.Request().Context(), payload.RefreshToken)
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	return util.SendSuccess(ctx, http.StatusOK, tokenPairResponse(tokens))
+}
+
+// Revoke accepts an access or a refresh token, whoever holds a token may
+// revoke it.
+func (t *TokenHttpController) Revoke(ctx echo.Context) error {
+	payload := new(struct {
+		Token string `json:"token" form:"token"`
+	})
+	if err := ctx.Bind(payload); err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"body": err.Error(),
+			},
+		)
+	}
+
+	err := t.tokenService.Revoke(ctx.Request().Context(), payload.Token)
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	return util.SendSuccess(ctx, http.StatusOK, map[string]interface{}{
+		"revoked": true,
+	})
+}
+
+func tokenPairResponse(tokens model.TokenPair) map[string]interface{} {
+	return map[string]interface{}{
+		"token":                    tokens.AccessToken,
+		"token_expires_at":         tokens.AccessExpiresAt,
+		"refresh_token":            tokens.RefreshToken,
+		"refresh_token_expires_at": tokens.RefreshExpiresAt,
+	}
+}
@@ -1,14 +1,26 @@
 package controller
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hokdre/mini-ewallet/internal"
 	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/internal/statement"
 	"github.com/hokdre/mini-ewallet/pkg/util"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+)
+
 type WalletHttpController struct {
 	walletService internal.WalletService
 }
@@ -35,18 +47,12 @@ func (w *WalletHttpController) Init(ctx echo.Context) error {
 		)
 	}
 
-	token, err := w.walletService.Init(ctx.Request().Context(), payload.CustomerXID)
+	tokens, err := w.walletService.Init(ctx.Request().Context(), payload.CustomerXID)
 	if err != nil {
 		return util.SendFailedOrError(ctx, err)
 	}
 
-	return util.SendSuccess(
-		ctx,
-		http.StatusOK,
-		map[string]interface{}{
-			"token": token,
-		},
-	)
+	return util.SendSuccess(ctx, http.StatusOK, tokenPairResponse(tokens))
 }
 
 func (w *WalletHttpController) Enable(ctx echo.Context) error {
@@ -55,18 +61,27 @@ func (w *WalletHttpController) Enable(ctx echo.Context) error {
 		return util.SendError(ctx, http.StatusUnauthorized, err)
 	}
 
-	wallet, err := w.walletService.Enable(ctx.Request().Context(), accountID)
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return util.SendFailed(ctx, http.StatusBadRequest, map[string]interface{}{
+			headerIfMatch: "value is not valid",
+		})
+	}
+
+	wallet, err := w.walletService.Enable(ctx.Request().Context(), accountID, version)
 	if err != nil {
 		return util.SendFailedOrError(ctx, err)
 	}
+	setWalletETag(ctx, wallet)
 
 	return util.SendSuccess(ctx, http.StatusOK, map[string]interface{}{
 		"wallet": map[string]interface{}{
-			"id":         wallet.ID,
-			"owned_by":   wallet.OwnedBy,
-			"status":     wallet.Status,
-			"enabled_at": wallet.EnabledAt,
-			"balance":    wallet.Balance,
+			"id":                wallet.ID,
+			"owned_by":          wallet.OwnedBy,
+			"status":            wallet.Status,
+			"enabled_at":        wallet.EnabledAt,
+			"balance":           wallet.Balance,
+			"available_balance": wallet.AvailableBalance(),
 		},
 	})
 }
@@ -77,18 +92,27 @@ func (w *WalletHttpController) Disable(ctx echo.Context) error {
 		return util.SendError(ctx, http.StatusUnauthorized, err)
 	}
 
-	wallet, err := w.walletService.Disable(ctx.Request().Context(), accountID)
+	version, ok := ifMatchVersion(ctx)
+	if !ok {
+		return util.SendFailed(ctx, http.StatusBadRequest, map[string]interface{}{
+			headerIfMatch: "value is not valid",
+		})
+	}
+
+	wallet, err := w.walletService.Disable(ctx.Request().Context(), accountID, version)
 	if err != nil {
 		return util.SendFailedOrError(ctx, err)
 	}
+	setWalletETag(ctx, wallet)
 
 	return util.SendSuccess(ctx, http.StatusOK, map[string]interface{}{
 		"wallet": map[string]interface{}{
-			"id":          wallet.ID,
-			"owned_by":    wallet.OwnedBy,
-			"status":      wallet.Status,
-			"disabled_at": wallet.DisabledAt,
-			"balance":     wallet.Balance,
+			"id":                wallet.ID,
+			"owned_by":          wallet.OwnedBy,
+			"status":            wallet.Status,
+			"disabled_at":       wallet.DisabledAt,
+			"balance":           wallet.Balance,
+			"available_balance": wallet.AvailableBalance(),
 		},
 	})
 }
@@ -100,17 +124,23 @@ func (w *WalletHttpController) Get(ctx echo.Context) error {
 	}
 
 	wallet, err := w.walletService.Get(ctx.Request().Context(), accountID)
+	if errors.Is(err, model.ErrWalletDisabled) {
+		// tagged all the same, the version is needed to enable the wallet
+		setWalletETag(ctx, wallet)
+	}
 	if err != nil {
 		return util.SendFailedOrError(ctx, err)
 	}
+	setWalletETag(ctx, wallet)
 
 	return util.SendSuccess(ctx, http.StatusOK, map[string]interface{}{
 		"wallet": map[string]interface{}{
-			"id":         wallet.ID,
-			"owned_by":   wallet.OwnedBy,
-			"status":     wallet.Status,
-			"enabled_at": wallet.EnabledAt,
-			"balance":    wallet.Balance,
+			"id":                wallet.ID,
+			"owned_by":          wallet.OwnedBy,
+			"status":            wallet.Status,
+			"enabled_at":        wallet.EnabledAt,
+			"balance":           wallet.Balance,
+			"available_balance": wallet.AvailableBalance(),
 		},
 	})
 }
@@ -121,7 +151,12 @@ func (w *WalletHttpController) GetTransactions(ctx echo.Context) error {
 		return util.SendError(ctx, http.StatusUnauthorized, err)
 	}
 
-	transactions, err := w.walletService.GetTransactions(ctx.Request().Context(), accountID)
+	filter, invalid := parseTransactionFilter(ctx)
+	if len(invalid) > 0 {
+		return util.SendFailed(ctx, http.StatusBadRequest, invalid)
+	}
+
+	transactions, next, err := w.walletService.GetTransactions(ctx.Request().Context(), accountID, filter)
 	if err != nil {
 		return util.SendFailedOrError(ctx, err)
 	}
@@ -129,20 +164,239 @@ func (w *WalletHttpController) GetTransactions(ctx echo.Context) error {
 	data := []interface{}{}
 	for _, t := range transactions {
 		data = append(data, map[string]interface{}{
-			"id":            t.ID,
-			"status":        t.Status,
-			"transacted_at": t.TransactedAt,
-			"type":          t.Type,
-			"amount":        t.Amount,
-			"reference_id":  t.ReferenceID,
+			"id":              t.ID,
+			"status":          t.Status,
+			"transacted_at":   t.TransactedAt,
+			"type":            t.Type,
+			"amount":          t.Amount,
+			"fee":             t.Fee,
+			"reference_id":    t.ReferenceID,
+			"parent_id":       t.ParentID,
+			"refunded_amount": t.RefundedAmount,
 		})
 	}
 
+	var nextCursor *string
+	if next != nil {
+		v := next.Encode()
+		nextCursor = &v
+	}
+
 	return util.SendSuccess(ctx, http.StatusOK, map[string]interface{}{
 		"transactions": data,
+		"next_cursor":  nextCursor,
 	})
 }
 
+// Statement streams the statement between the from and to query params as
+// json, csv or pdf. Headers go out with the first byte, so failures found
+// before that still get a regular error response.
+func (w *WalletHttpController) Statement(ctx echo.Context) error {
+	accountID, err := util.GetAccountID(ctx)
+	if err != nil {
+		return util.SendError(ctx, http.StatusUnauthorized, err)
+	}
+
+	invalid := map[string]interface{}{}
+	format := strings.ToLower(ctx.QueryParam("format"))
+	if format == "" {
+		format = statement.Format.JSON
+	}
+	if statement.ContentType(format) == "" {
+		invalid["format"] = "value is not valid"
+	}
+
+	period := map[string]time.Time{}
+	for _, key := range []string{"from", "to"} {
+		date, err := parseDate(ctx.QueryParam(key), key == "to")
+		if err != nil {
+			invalid[key] = "value is not valid"
+			continue
+		}
+		period[key] = date
+	}
+
+	if len(invalid) > 0 {
+		return util.SendFailed(ctx, http.StatusBadRequest, invalid)
+	}
+
+	res := &statementResponse{ctx: ctx, contentType: statement.ContentType(format)}
+	if format != statement.Format.JSON {
+		res.filename = fmt.Sprintf(
+			"statement-%s-%s.%s",
+			period["from"].Format(time.DateOnly),
+			period["to"].Format(time.DateOnly),
+			format,
+		)
+	}
+
+	writer, err := statement.NewWriter(format, res)
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	err = w.walletService.Statement(ctx.Request().Context(), accountID, period["from"], period["to"], writer)
+	if err != nil && ctx.Response().Committed {
+		// too late for an error response, the client gets a cut off body
+		ctx.Logger().Errorf("failed stream statement : %s \n", err)
+		return nil
+	}
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	return nil
+}
+
+// statementResponse sends the statement headers right before the first
+// write.
+type statementResponse struct {
+	ctx         echo.Context
+	contentType string
+	filename    string
+}
+
+func (s *statementResponse) Write(p []byte) (int, error) {
+	res := s.ctx.Response()
+	if !res.Committed {
+		res.Header().Set(echo.HeaderContentType, s.contentType)
+		if s.filename != "" {
+			res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", s.filename))
+		}
+		res.WriteHeader(http.StatusOK)
+	}
+
+	return res.Write(p)
+}
+
+// parseTransactionFilter reads the listing query params, multi value params
+// accept either repeated keys or a comma separated list.
+func parseTransactionFilter(ctx echo.Context) (internal.TransactionFilter, map[string]interface{}) {
+	filter := internal.TransactionFilter{
+		Types:    queryValues(ctx, "type"),
+		Statuses: queryValues(ctx, "status"),
+	}
+	invalid := map[string]interface{}{}
+
+	if v := ctx.QueryParam("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			invalid["limit"] = "value is not valid"
+		}
+		filter.Limit = limit
+	}
+
+	if v := ctx.QueryParam("cursor"); v != "" {
+		cursor, err := internal.DecodeTransactionCursor(v)
+		if err != nil {
+			invalid["cursor"] = "value is not valid"
+		}
+		filter.After = &cursor
+	}
+
+	for key, target := range map[string]**int64{
+		"min_amount": &filter.MinAmount,
+		"max_amount": &filter.MaxAmount,
+	} {
+		v := ctx.QueryParam(key)
+		if v == "" {
+			continue
+		}
+		amount, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			invalid[key] = "value is not valid"
+			continue
+		}
+		*target = &amount
+	}
+
+	for key, target := range map[string]**time.Time{
+		"from": &filter.TransactedFrom,
+		"to":   &filter.TransactedTo,
+	} {
+		v := ctx.QueryParam(key)
+		if v == "" {
+			continue
+		}
+		date, err := parseDate(v, key == "to")
+		if err != nil {
+			invalid[key] = "value is not valid"
+			continue
+		}
+		*target = &date
+	}
+
+	return filter, invalid
+}
+
+func queryValues(ctx echo.Context, key string) []string {
+	return lowerValues(ctx.QueryParams()[key])
+}
+
+// lowerValues splits comma separated values and lower cases them, nil when
+// nothing is left.
+func lowerValues(values []string) []string {
+	lowered := []string{}
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				lowered = append(lowered, strings.ToLower(part))
+			}
+		}
+	}
+
+	if len(lowered) == 0 {
+		return nil
+	}
+
+	return lowered
+}
+
+// parseDate accepts RFC3339 or a plain date, a plain date used as upper
+// bound covers the whole day.
+func parseDate(value string, endOfDay bool) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return date, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if endOfDay {
+		date = date.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	return date, nil
+}
+
+// setWalletETag tags the wallet response with its version, clients send it
+// back as If-Match to enable or disable only the wallet they have seen.
+func setWalletETag(ctx echo.Context, wallet model.Wallet) {
+	ctx.Response().Header().Set(headerETag, strconv.Quote(strconv.FormatInt(wallet.Version, 10)))
+}
+
+// ifMatchVersion reads the wallet version of If-Match, zero when the header
+// is missing or "*". A weak tag is taken as well, the version is the same.
+func ifMatchVersion(ctx echo.Context) (int64, bool) {
+	value := strings.TrimSpace(ctx.Request().Header.Get(headerIfMatch))
+	if value == "" || value == "*" {
+		return 0, true
+	}
+
+	tag, err := strconv.Unquote(strings.TrimPrefix(value, "W/"))
+	if err != nil {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+
+	return version, true
+}
+
 func (w *WalletHttpController) Deposit(ctx echo.Context) error {
 	accountID, err := util.GetAccountID(ctx)
 	if err != nil {
@@ -222,7 +476,241 @@ func (w *WalletHttpController) Withdrawal(ctx echo.Context) error {
 			"status":        transaction.Status,
 			"withdrawal_at": transaction.TransactedAt,
 			"amount":        transaction.Amount,
+			"gross_amount":  transaction.GrossAmount(),
+			"fee":           transaction.Fee,
+			"net_amount":    transaction.Amount,
 			"reference_id":  transaction.ReferenceID,
 		},
 	})
 }
+
+func (w *WalletHttpController) Transfer(ctx echo.Context) error {
+	accountID, err := util.GetAccountID(ctx)
+	if err != nil {
+		return util.SendError(ctx, http.StatusUnauthorized, err)
+	}
+
+	payload := new(struct {
+		ToWalletID  string `json:"to_wallet_id" form:"to_wallet_id"`
+		ReferenceID string `json:"reference_id" form:"reference_id"`
+		Amount      int64  `json:"amount" form:"amount"`
+	})
+	err = ctx.Bind(payload)
+	if err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"body": err.Error(),
+			},
+		)
+	}
+
+	receiverWalletID, err := uuid.Parse(payload.ToWalletID)
+	if err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"to_wallet_id": "value is not valid",
+			},
+		)
+	}
+
+	transaction := model.Transaction{
+		Amount:      payload.Amount,
+		ReferenceID: payload.ReferenceID,
+	}
+	transaction, err = w.walletService.Transfer(ctx.Request().Context(), accountID, receiverWalletID, transaction)
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	return util.SendSuccess(ctx, http.StatusCreated, map[string]interface{}{
+		"transfer": map[string]interface{}{
+			"id":             transaction.ID,
+			"transferred_by": accountID,
+			"to_wallet_id":   receiverWalletID,
+			"status":         transaction.Status,
+			"transferred_at": transaction.TransactedAt,
+			"amount":         transaction.Amount,
+			"gross_amount":   transaction.GrossAmount(),
+			"fee":            transaction.Fee,
+			"net_amount":     transaction.Amount,
+			"reference_id":   transaction.ReferenceID,
+		},
+	})
+}
+
+func (w *WalletHttpController) Refund(ctx echo.Context) error {
+	accountID, err := util.GetAccountID(ctx)
+	if err != nil {
+		return util.SendError(ctx, http.StatusUnauthorized, err)
+	}
+
+	transactionID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"id": "value is not valid",
+			},
+		)
+	}
+
+	payload := new(struct {
+		Amount int64 `json:"amount" form:"amount"`
+	})
+	err = ctx.Bind(payload)
+	if err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"body": err.Error(),
+			},
+		)
+	}
+
+	transaction, err := w.walletService.Refund(ctx.Request().Context(), accountID, transactionID, payload.Amount)
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	return util.SendSuccess(ctx, http.StatusCreated, map[string]interface{}{
+		"refund": map[string]interface{}{
+			"id":           transaction.ID,
+			"parent_id":    transaction.ParentID,
+			"refunded_by":  accountID,
+			"type":         transaction.Type,
+			"status":       transaction.Status,
+			"refunded_at":  transaction.TransactedAt,
+			"amount":       transaction.Amount,
+			"reference_id": transaction.ReferenceID,
+		},
+	})
+}
+
+func (w *WalletHttpController) Authorize(ctx echo.Context) error {
+	accountID, err := util.GetAccountID(ctx)
+	if err != nil {
+		return util.SendError(ctx, http.StatusUnauthorized, err)
+	}
+
+	payload := new(struct {
+		ReferenceID string `json:"reference_id" form:"reference_id"`
+		Amount      int64  `json:"amount" form:"amount"`
+	})
+	err = ctx.Bind(payload)
+	if err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"body": err.Error(),
+			},
+		)
+	}
+
+	hold := model.Hold{
+		Amount:      payload.Amount,
+		ReferenceID: payload.ReferenceID,
+	}
+	hold, err = w.walletService.Authorize(ctx.Request().Context(), accountID, hold)
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	return util.SendSuccess(ctx, http.StatusCreated, map[string]interface{}{
+		"hold": holdResponse(hold),
+	})
+}
+
+func (w *WalletHttpController) Capture(ctx echo.Context) error {
+	accountID, err := util.GetAccountID(ctx)
+	if err != nil {
+		return util.SendError(ctx, http.StatusUnauthorized, err)
+	}
+
+	holdID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"id": "value is not valid",
+			},
+		)
+	}
+
+	payload := new(struct {
+		Amount int64 `json:"amount" form:"amount"`
+	})
+	err = ctx.Bind(payload)
+	if err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"body": err.Error(),
+			},
+		)
+	}
+
+	transaction, err := w.walletService.Capture(ctx.Request().Context(), accountID, holdID, payload.Amount)
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	return util.SendSuccess(ctx, http.StatusCreated, map[string]interface{}{
+		"capture": map[string]interface{}{
+			"id":           transaction.ID,
+			"hold_id":      holdID,
+			"captured_by":  accountID,
+			"status":       transaction.Status,
+			"captured_at":  transaction.TransactedAt,
+			"amount":       transaction.Amount,
+			"reference_id": transaction.ReferenceID,
+		},
+	})
+}
+
+func (w *WalletHttpController) Void(ctx echo.Context) error {
+	accountID, err := util.GetAccountID(ctx)
+	if err != nil {
+		return util.SendError(ctx, http.StatusUnauthorized, err)
+	}
+
+	holdID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return util.SendFailed(
+			ctx,
+			http.StatusBadRequest,
+			map[string]interface{}{
+				"id": "value is not valid",
+			},
+		)
+	}
+
+	hold, err := w.walletService.Void(ctx.Request().Context(), accountID, holdID)
+	if err != nil {
+		return util.SendFailedOrError(ctx, err)
+	}
+
+	return util.SendSuccess(ctx, http.StatusOK, map[string]interface{}{
+		"hold": holdResponse(hold),
+	})
+}
+
+func holdResponse(hold model.Hold) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              hold.ID,
+		"wallet_id":       hold.WalletID,
+		"status":          hold.Status,
+		"amount":          hold.Amount,
+		"captured_amount": hold.CapturedAmount,
+		"reference_id":    hold.ReferenceID,
+		"expires_at":      hold.ExpiresAt,
+	}
+}
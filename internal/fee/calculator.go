@@ -0,0 +1,185 @@
+package fee
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hokdre/mini-ewallet/internal"
+)
+
+const basisPointsPerUnit = 10000
+
+var (
+	Type = struct {
+		Flat       string
+		Percentage string
+		Tiered     string
+	}{
+		Flat:       "flat",
+		Percentage: "percentage",
+		Tiered:     "tiered",
+	}
+
+	ErrUnknownType  = errors.New("unknown fee type")
+	ErrInvalidTiers = errors.New("invalid fee tiers")
+)
+
+// Config describes a fee, an empty Type means no fee is charged.
+type Config struct {
+	Type string
+	// Flat is the fixed fee of the flat type.
+	Flat int64
+	// BasisPoints is the rate of the percentage type, 100 is 1%.
+	BasisPoints int64
+	// Tiers of the tiered type, see ParseTiers.
+	Tiers string
+	// Min and Max bound the fee, a zero Max means no upper bound.
+	Min int64
+	Max int64
+}
+
+// New builds the calculator described by cfg, it returns nil when cfg has
+// no type.
+func New(cfg Config) (internal.FeeCalculator, error) {
+	var calculator internal.FeeCalculator
+	switch strings.ToLower(cfg.Type) {
+	case "":
+		return nil, nil
+	case Type.Flat:
+		calculator = NewFlat(cfg.Flat)
+	case Type.Percentage:
+		calculator = NewPercentage(cfg.BasisPoints)
+	case Type.Tiered:
+		tiers, err := ParseTiers(cfg.Tiers)
+		if err != nil {
+			return nil, err
+		}
+		calculator = NewTiered(tiers)
+	default:
+		return nil, fmt.Errorf("%w : %s", ErrUnknownType, cfg.Type)
+	}
+
+	if cfg.Min > 0 || cfg.Max > 0 {
+		calculator = NewBounded(calculator, cfg.Min, cfg.Max)
+	}
+
+	return calculator, nil
+}
+
+type flat struct {
+	amount int64
+}
+
+func NewFlat(amount int64) *flat {
+	return &flat{amount: amount}
+}
+
+func (f *flat) Calculate(amount int64) int64 {
+	return f.amount
+}
+
+type percentage struct {
+	basisPoints int64
+}
+
+func NewPercentage(basisPoints int64) *percentage {
+	return &percentage{basisPoints: basisPoints}
+}
+
+// Calculate rounds half up to the smallest unit.
+func (p *percentage) Calculate(amount int64) int64 {
+	return (amount*p.basisPoints + basisPointsPerUnit/2) / basisPointsPerUnit
+}
+
+// Tier applies to amounts from From up to the From of the next tier.
+type Tier struct {
+	From        int64
+	Flat        int64
+	BasisPoints int64
+}
+
+type tiered struct {
+	tiers []Tier
+}
+
+func NewTiered(tiers []Tier) *tiered {
+	sorted := append([]Tier{}, tiers...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].From < sorted[j].From
+	})
+
+	return &tiered{tiers: sorted}
+}
+
+func (t *tiered) Calculate(amount int64) int64 {
+	var fee int64
+	for _, tier := range t.tiers {
+		if amount < tier.From {
+			break
+		}
+		fee = tier.Flat + NewPercentage(tier.BasisPoints).Calculate(amount)
+	}
+
+	return fee
+}
+
+// ParseTiers reads comma separated "from:flat:basis_points" tiers, for
+// example "0:1000:0,1000000:0:10".
+func ParseTiers(value string) ([]Tier, error) {
+	tiers := []Tier{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		fields := strings.Split(part, ":")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%w : %s", ErrInvalidTiers, part)
+		}
+
+		numbers := make([]int64, len(fields))
+		for i, field := range fields {
+			number, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil || number < 0 {
+				return nil, fmt.Errorf("%w : %s", ErrInvalidTiers, part)
+			}
+			numbers[i] = number
+		}
+
+		tiers = append(tiers, Tier{From: numbers[0], Flat: numbers[1], BasisPoints: numbers[2]})
+	}
+
+	if len(tiers) == 0 {
+		return nil, ErrInvalidTiers
+	}
+
+	return tiers, nil
+}
+
+type bounded struct {
+	calculator internal.FeeCalculator
+	min        int64
+	max        int64
+}
+
+// NewBounded keeps the fee of calculator within min and max, a zero max
+// means no upper bound.
+func NewBounded(calculator internal.FeeCalculator, min int64, max int64) *bounded {
+	return &bounded{calculator: calculator, min: min, max: max}
+}
+
+func (b *bounded) Calculate(amount int64) int64 {
+	fee := b.calculator.Calculate(amount)
+	if fee < b.min {
+		fee = b.min
+	}
+	if b.max > 0 && fee > b.max {
+		fee = b.max
+	}
+
+	return fee
+}
@@ -0,0 +1,106 @@
+package fee
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculator(t *testing.T) {
+	t.Run("New", TestNew)
+	t.Run("Flat", TestFlat)
+	t.Run("Percentage", TestPercentage)
+	t.Run("Tiered", TestTiered)
+	t.Run("ParseTiers", TestParseTiers)
+	t.Run("Bounded", TestBounded)
+}
+
+func TestNew(t *testing.T) {
+	t.Run("no fee", func(t *testing.T) {
+		calculator, err := New(Config{})
+		assert.NoError(t, err)
+		assert.Nil(t, calculator)
+	})
+
+	t.Run("percentage with bounds", func(t *testing.T) {
+		calculator, err := New(Config{Type: "Percentage", BasisPoints: 100, Min: 500, Max: 2000})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(500), calculator.Calculate(1000))
+		assert.Equal(t, int64(1500), calculator.Calculate(150000))
+		assert.Equal(t, int64(2000), calculator.Calculate(1000000))
+	})
+
+	t.Run("tiered", func(t *testing.T) {
+		calculator, err := New(Config{Type: Type.Tiered, Tiers: "0:1000:0,100000:0:100"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1000), calculator.Calculate(50000))
+		assert.Equal(t, int64(1500), calculator.Calculate(150000))
+	})
+
+	t.Run("invalid tiers", func(t *testing.T) {
+		calculator, err := New(Config{Type: Type.Tiered, Tiers: "0:1000"})
+		assert.ErrorIs(t, err, ErrInvalidTiers)
+		assert.Nil(t, calculator)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		calculator, err := New(Config{Type: "free"})
+		assert.ErrorIs(t, err, ErrUnknownType)
+		assert.Nil(t, calculator)
+	})
+}
+
+func TestFlat(t *testing.T) {
+	calculator := NewFlat(2500)
+	assert.Equal(t, int64(2500), calculator.Calculate(1))
+	assert.Equal(t, int64(2500), calculator.Calculate(1000000))
+}
+
+func TestPercentage(t *testing.T) {
+	calculator := NewPercentage(150)
+	assert.Equal(t, int64(150), calculator.Calculate(10000))
+	// 0.5 rounds up, 0.45 rounds down
+	assert.Equal(t, int64(1), calculator.Calculate(34))
+	assert.Equal(t, int64(0), calculator.Calculate(30))
+}
+
+func TestTiered(t *testing.T) {
+	calculator := NewTiered([]Tier{
+		{From: 1000000, BasisPoints: 10},
+		{From: 0, Flat: 1000},
+		{From: 100000, Flat: 500, BasisPoints: 50},
+	})
+	assert.Equal(t, int64(1000), calculator.Calculate(99999))
+	assert.Equal(t, int64(1000), calculator.Calculate(100000))
+	assert.Equal(t, int64(1000), calculator.Calculate(1000000))
+	assert.Equal(t, int64(2000), calculator.Calculate(2000000))
+}
+
+func TestParseTiers(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		tiers, err := ParseTiers(" 0:1000:0, 100000:0:50 ,")
+		assert.NoError(t, err)
+		assert.Equal(t, []Tier{
+			{From: 0, Flat: 1000, BasisPoints: 0},
+			{From: 100000, Flat: 0, BasisPoints: 50},
+		}, tiers)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		tiers, err := ParseTiers("")
+		assert.ErrorIs(t, err, ErrInvalidTiers)
+		assert.Nil(t, tiers)
+	})
+
+	t.Run("negative", func(t *testing.T) {
+		tiers, err := ParseTiers("0:-1:0")
+		assert.ErrorIs(t, err, ErrInvalidTiers)
+		assert.Nil(t, tiers)
+	})
+}
+
+func TestBounded(t *testing.T) {
+	calculator := NewBounded(NewPercentage(100), 100, 0)
+	assert.Equal(t, int64(100), calculator.Calculate(100))
+	assert.Equal(t, int64(100000), calculator.Calculate(10000000))
+}
@@ -0,0 +1,6 @@
+package internal
+
+// FeeCalculator tells the fee charged on top of a transaction amount.
+type FeeCalculator interface {
+	Calculate(amount int64) int64
+}
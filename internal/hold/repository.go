@@ -0,0 +1,167 @@
+package hold
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/lib/pq"
+)
+
+const (
+	defaultOffset = 0
+
+	qCreate = `
+		INSERT INTO holds (
+			id, wallet_id, amount, captured_amount, status, reference_id, expires_at, created_at, updated_at
+		) VALUES(
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+	`
+
+	qList = `
+	   SELECT 
+	   	id, wallet_id, amount, captured_amount, status, reference_id, expires_at, created_at, updated_at
+	   FROM holds
+	   WHERE (id = ANY($1) or $1 IS NULL)
+	   AND (wallet_id = ANY($2) or $2 IS NULL)
+	   AND (reference_id = ANY($3) or $3 IS NULL)
+	   AND (status = ANY($4) or $4 IS NULL)
+	   AND (expires_at <= $5 or $5 IS NULL)
+	   ORDER BY expires_at ASC, id ASC
+	   LIMIT $6
+	   OFFSET $7
+	`
+
+	qUpdate = `
+		UPDATE holds SET
+			status = $1, captured_amount = $2, updated_at = $3
+		WHERE id = $4 AND status = $5
+	`
+)
+
+type holdRepository struct {
+	db *sql.DB
+}
+
+func NewHoldRepository(db *sql.DB) *holdRepository {
+	return &holdRepository{db: db}
+}
+
+func (h *holdRepository) GetOne(ctx context.Context, filter internal.HoldFilter) (model.Hold, error) {
+	filter.Limit = 1
+	holds, err := h.List(ctx, filter)
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	if len(holds) == 0 {
+		return model.Hold{}, sql.ErrNoRows
+	}
+
+	return holds[0], nil
+}
+
+func (h *holdRepository) List(ctx context.Context, filter internal.HoldFilter) ([]model.Hold, error) {
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	rows, err := h.db.QueryContext(
+		ctx,
+		qList,
+		pq.Array(filter.IDs),
+		pq.Array(filter.WalletIDs),
+		pq.Array(filter.ReferenceIDs),
+		pq.Array(filter.Statuses),
+		filter.ExpiresBefore,
+		limit,
+		defaultOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []model.Hold{}
+	for rows.Next() {
+		hold := model.Hold{}
+		err := rows.Scan(
+			&hold.ID,
+			&hold.WalletID,
+			&hold.Amount,
+			&hold.CapturedAmount,
+			&hold.Status,
+			&hold.ReferenceID,
+			&hold.ExpiresAt,
+			&hold.CreatedAt,
+			&hold.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		holds = append(holds, hold)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return holds, nil
+}
+
+func (h *holdRepository) CreateTx(ctx context.Context, tx *sql.Tx, newHold model.Hold) error {
+	stmt, err := tx.Prepare(qCreate)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(
+		ctx,
+		newHold.ID,
+		newHold.WalletID,
+		newHold.Amount,
+		newHold.CapturedAmount,
+		newHold.Status,
+		newHold.ReferenceID,
+		newHold.ExpiresAt,
+		newHold.CreatedAt,
+		newHold.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *holdRepository) UpdateTx(ctx context.Context, tx *sql.Tx, hold model.Hold) (int64, error) {
+	stmt, err := tx.Prepare(qUpdate)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(
+		ctx,
+		hold.Status,
+		hold.CapturedAmount,
+		hold.UpdatedAt,
+		hold.ID,
+		model.HoldStatus.Authorized,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
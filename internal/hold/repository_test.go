@@ -0,0 +1,187 @@
+package hold
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHoldRepository(t *testing.T) {
+	t.Run("CreateTx", TestCreateTx)
+	t.Run("GetOne", TestGetOne)
+	t.Run("UpdateTx", TestUpdateTx)
+}
+
+func newHold() model.Hold {
+	timestamp := time.Now()
+	return model.Hold{
+		ID:          uuid.New(),
+		WalletID:    uuid.New(),
+		Amount:      10000,
+		Status:      model.HoldStatus.Authorized,
+		ReferenceID: "abc",
+		ExpiresAt:   timestamp.Add(time.Hour),
+		CreatedAt:   timestamp,
+		UpdatedAt:   timestamp,
+	}
+}
+
+func TestCreateTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		hold := newHold()
+		mock.
+			ExpectPrepare(qCreate).
+			ExpectExec().
+			WithArgs(
+				hold.ID,
+				hold.WalletID,
+				hold.Amount,
+				hold.CapturedAmount,
+				hold.Status,
+				hold.ReferenceID,
+				hold.ExpiresAt,
+				hold.CreatedAt,
+				hold.UpdatedAt,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &holdRepository{db: db}
+		errCreate := repo.CreateTx(context.Background(), tx, hold)
+		assert.NoError(t, errCreate)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Execute", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		errExpected := errors.New("err")
+		mock.
+			ExpectPrepare(qCreate).
+			ExpectExec().
+			WillReturnError(errExpected)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &holdRepository{db: db}
+		errCreate := repo.CreateTx(context.Background(), tx, model.Hold{})
+		assert.ErrorIs(t, errCreate, errExpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetOne(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		hold := newHold()
+		filter := internal.HoldFilter{
+			IDs:       []string{hold.ID.String()},
+			WalletIDs: []string{hold.WalletID.String()},
+		}
+		mock.ExpectQuery(qList).WithArgs(
+			pq.Array(filter.IDs),
+			pq.Array(filter.WalletIDs),
+			pq.Array(filter.ReferenceIDs),
+			pq.Array(filter.Statuses),
+			filter.ExpiresBefore,
+			1,
+			0,
+		).WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"wallet_id",
+			"amount",
+			"captured_amount",
+			"status",
+			"reference_id",
+			"expires_at",
+			"created_at",
+			"updated_at",
+		}).AddRow(
+			hold.ID,
+			hold.WalletID,
+			hold.Amount,
+			hold.CapturedAmount,
+			hold.Status,
+			hold.ReferenceID,
+			hold.ExpiresAt,
+			hold.CreatedAt,
+			hold.UpdatedAt,
+		))
+
+		repo := &holdRepository{db: db}
+		result, err := repo.GetOne(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Equal(t, hold, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(qList).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		repo := &holdRepository{db: db}
+		result, err := repo.GetOne(context.Background(), internal.HoldFilter{})
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Equal(t, model.Hold{}, result)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		hold := newHold()
+		hold.Status = model.HoldStatus.Captured
+		hold.CapturedAmount = 5000
+		mock.
+			ExpectPrepare(qUpdate).
+			ExpectExec().
+			WithArgs(
+				hold.Status,
+				hold.CapturedAmount,
+				hold.UpdatedAt,
+				hold.ID,
+				model.HoldStatus.Authorized,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &holdRepository{db: db}
+		affected, errUpdate := repo.UpdateTx(context.Background(), tx, hold)
+		assert.NoError(t, errUpdate)
+		assert.Equal(t, int64(1), affected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
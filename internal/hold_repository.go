@@ -0,0 +1,27 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type HoldFilter struct {
+	WalletIDs     []string
+	IDs           []string
+	ReferenceIDs  []string
+	Statuses      []string
+	ExpiresBefore *time.Time
+	Limit         int
+}
+
+type HoldRepository interface {
+	GetOne(ctx context.Context, filter HoldFilter) (model.Hold, error)
+	List(ctx context.Context, filter HoldFilter) ([]model.Hold, error)
+	CreateTx(ctx context.Context, tx *sql.Tx, newHold model.Hold) error
+	// UpdateTx only touches holds that are still authorized, the affected
+	// rows tell whether the hold was settled concurrently.
+	UpdateTx(ctx context.Context, tx *sql.Tx, hold model.Hold) (int64, error)
+}
@@ -0,0 +1,134 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+const (
+	qCreateJournalEntry = `
+		INSERT INTO journal_entries (
+			id, transaction_id, created_at
+		) VALUES(
+			$1, $2, $3
+		)
+	`
+
+	qCreatePosting = `
+		INSERT INTO postings (
+			id, journal_entry_id, account, wallet_id, amount, created_at
+		) VALUES(
+			$1, $2, $3, $4, $5, $6
+		)
+	`
+
+	qGetEntry = `
+		SELECT
+			id,
+			transaction_id,
+			created_at
+		FROM journal_entries
+		WHERE transaction_id = $1
+	`
+
+	qWalletBalance = `
+		SELECT 
+			COALESCE(SUM(amount), 0)
+		FROM postings
+		WHERE account = $1
+		AND wallet_id = $2
+	`
+)
+
+type ledgerRepository struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) *ledgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (l *ledgerRepository) PostTx(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error {
+	if !entry.IsBalanced() {
+		return model.ErrUnbalancedJournalEntry
+	}
+
+	entryStmt, err := tx.Prepare(qCreateJournalEntry)
+	if err != nil {
+		return err
+	}
+	defer entryStmt.Close()
+
+	_, err = entryStmt.ExecContext(
+		ctx,
+		entry.ID,
+		entry.TransactionID,
+		entry.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	postingStmt, err := tx.Prepare(qCreatePosting)
+	if err != nil {
+		return err
+	}
+	defer postingStmt.Close()
+
+	for _, p := range entry.Postings {
+		_, err = postingStmt.ExecContext(
+			ctx,
+			p.ID,
+			entry.ID,
+			p.Account,
+			p.WalletID,
+			p.Amount,
+			entry.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *ledgerRepository) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	row := l.db.QueryRowContext(
+		ctx,
+		qWalletBalance,
+		model.LedgerAccount.Wallet,
+		walletID,
+	)
+
+	var balance int64
+	err := row.Scan(&balance)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
+
+func (l *ledgerRepository) GetEntryTx(ctx context.Context, tx *sql.Tx, transactionID uuid.UUID) (model.JournalEntry, error) {
+	stmt, err := tx.Prepare(qGetEntry)
+	if err != nil {
+		return model.JournalEntry{}, err
+	}
+	defer stmt.Close()
+
+	entry := model.JournalEntry{}
+	err = stmt.QueryRowContext(ctx, transactionID).Scan(
+		&entry.ID,
+		&entry.TransactionID,
+		&entry.CreatedAt,
+	)
+	if err != nil {
+		return model.JournalEntry{}, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,190 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedgerRepository(t *testing.T) {
+	t.Run("PostTx", TestPostTx)
+	t.Run("GetWalletBalance", TestGetWalletBalance)
+	t.Run("GetEntryTx", TestGetEntryTx)
+}
+
+func newEntry() model.JournalEntry {
+	walletID := uuid.New()
+	return model.JournalEntry{
+		ID:            uuid.New(),
+		TransactionID: uuid.New(),
+		CreatedAt:     time.Now(),
+		Postings: []model.Posting{
+			{
+				ID:       uuid.New(),
+				Account:  model.LedgerAccount.Wallet,
+				WalletID: &walletID,
+				Amount:   1000,
+			},
+			{
+				ID:      uuid.New(),
+				Account: model.LedgerAccount.CashIn,
+				Amount:  -1000,
+			},
+		},
+	}
+}
+
+func TestPostTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		entry := newEntry()
+		mock.
+			ExpectPrepare(qCreateJournalEntry).
+			ExpectExec().
+			WithArgs(entry.ID, entry.TransactionID, entry.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		postingStmt := mock.ExpectPrepare(qCreatePosting)
+		for _, p := range entry.Postings {
+			postingStmt.
+				ExpectExec().
+				WithArgs(p.ID, entry.ID, p.Account, p.WalletID, p.Amount, entry.CreatedAt).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &ledgerRepository{db: db}
+		errPost := repo.PostTx(context.Background(), tx, entry)
+		assert.NoError(t, errPost)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unbalanced entry", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		entry := newEntry()
+		entry.Postings[1].Amount = -999
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &ledgerRepository{db: db}
+		errPost := repo.PostTx(context.Background(), tx, entry)
+		assert.ErrorIs(t, errPost, model.ErrUnbalancedJournalEntry)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed insert posting", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		errExpected := errors.New("err")
+		entry := newEntry()
+		mock.
+			ExpectPrepare(qCreateJournalEntry).
+			ExpectExec().
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.
+			ExpectPrepare(qCreatePosting).
+			ExpectExec().
+			WillReturnError(errExpected)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &ledgerRepository{db: db}
+		errPost := repo.PostTx(context.Background(), tx, entry)
+		assert.ErrorIs(t, errPost, errExpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetWalletBalance(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		walletID := uuid.New()
+		mock.ExpectQuery(qWalletBalance).
+			WithArgs(model.LedgerAccount.Wallet, walletID).
+			WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(int64(2500)))
+
+		repo := &ledgerRepository{db: db}
+		balance, err := repo.GetWalletBalance(context.Background(), walletID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2500), balance)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestGetEntryTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		entry := model.JournalEntry{
+			ID:            uuid.New(),
+			TransactionID: uuid.New(),
+			CreatedAt:     time.Now(),
+		}
+		mock.
+			ExpectPrepare(qGetEntry).
+			ExpectQuery().
+			WithArgs(entry.TransactionID).
+			WillReturnRows(
+				sqlmock.NewRows([]string{"id", "transaction_id", "created_at"}).
+					AddRow(entry.ID, entry.TransactionID, entry.CreatedAt),
+			)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &ledgerRepository{db: db}
+		found, err := repo.GetEntryTx(context.Background(), tx, entry.TransactionID)
+		assert.NoError(t, err)
+		assert.Equal(t, entry, found)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		transactionID := uuid.New()
+		mock.
+			ExpectPrepare(qGetEntry).
+			ExpectQuery().
+			WithArgs(transactionID).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "transaction_id", "created_at"}))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &ledgerRepository{db: db}
+		_, err = repo.GetEntryTx(context.Background(), tx, transactionID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
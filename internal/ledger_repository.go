@@ -0,0 +1,17 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type LedgerRepository interface {
+	PostTx(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error
+	GetWalletBalance(ctx context.Context, walletID uuid.UUID) (int64, error)
+	// GetEntryTx returns the journal entry booked for the transaction without
+	// its postings, sql.ErrNoRows when the transaction never reached the ledger.
+	GetEntryTx(ctx context.Context, tx *sql.Tx, transactionID uuid.UUID) (model.JournalEntry, error)
+}
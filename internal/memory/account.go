@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type accountRepository struct {
+	store *Store
+}
+
+func NewAccountRepository(store *Store) *accountRepository {
+	return &accountRepository{store: store}
+}
+
+func (a *accountRepository) Get(ctx context.Context, filter internal.AccountFilter) (model.Account, error) {
+	found := model.Account{}
+	err := a.store.run(ctx, func(tx *memoryTx) error {
+		for _, acc := range a.store.accounts {
+			if in(filter.IDs, acc.ID.String()) && in(filter.ExternalIDs, acc.ExternalCustomerID) {
+				found = acc
+				return nil
+			}
+		}
+
+		return sql.ErrNoRows
+	})
+
+	return found, err
+}
+
+func (a *accountRepository) CreateTx(ctx context.Context, _ *sql.Tx, newAcc model.Account) error {
+	return a.store.run(ctx, func(tx *memoryTx) error {
+		for id, acc := range a.store.accounts {
+			if id == newAcc.ID || acc.ExternalCustomerID == newAcc.ExternalCustomerID {
+				return errDuplicateKey
+			}
+		}
+
+		put(tx, a.store.accounts, newAcc.ID, newAcc)
+		return nil
+	})
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type auditRepository struct {
+	store *Store
+}
+
+func NewAuditRepository(store *Store) *auditRepository {
+	return &auditRepository{store: store}
+}
+
+func (a *auditRepository) CreateTx(ctx context.Context, _ *sql.Tx, entry model.AuditEntry) error {
+	return a.store.run(ctx, func(tx *memoryTx) error {
+		if _, ok := a.store.auditEntries[entry.ID]; ok {
+			return errDuplicateKey
+		}
+
+		put(tx, a.store.auditEntries, entry.ID, entry)
+		return nil
+	})
+}
+
+func (a *auditRepository) List(ctx context.Context, walletID string) ([]model.AuditEntry, error) {
+	entries := []model.AuditEntry{}
+	err := a.store.run(ctx, func(tx *memoryTx) error {
+		for _, entry := range a.store.auditEntries {
+			if entry.WalletID.String() == walletID {
+				entries = append(entries, entry)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID.String() > entries[j].ID.String()
+	})
+
+	return entries, nil
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type holdRepository struct {
+	store *Store
+}
+
+func NewHoldRepository(store *Store) *holdRepository {
+	return &holdRepository{store: store}
+}
+
+func (h *holdRepository) GetOne(ctx context.Context, filter internal.HoldFilter) (model.Hold, error) {
+	filter.Limit = 1
+	holds, err := h.List(ctx, filter)
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	if len(holds) == 0 {
+		return model.Hold{}, sql.ErrNoRows
+	}
+
+	return holds[0], nil
+}
+
+func (h *holdRepository) List(ctx context.Context, filter internal.HoldFilter) ([]model.Hold, error) {
+	holds := []model.Hold{}
+	err := h.store.run(ctx, func(tx *memoryTx) error {
+		for _, hold := range h.store.holds {
+			if !in(filter.IDs, hold.ID.String()) ||
+				!in(filter.WalletIDs, hold.WalletID.String()) ||
+				!in(filter.ReferenceIDs, hold.ReferenceID) ||
+				!in(filter.Statuses, hold.Status) {
+				continue
+			}
+			if filter.ExpiresBefore != nil && hold.ExpiresAt.After(*filter.ExpiresBefore) {
+				continue
+			}
+
+			holds = append(holds, hold)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(holds, func(i, j int) bool {
+		if !holds[i].ExpiresAt.Equal(holds[j].ExpiresAt) {
+			return holds[i].ExpiresAt.Before(holds[j].ExpiresAt)
+		}
+		return holds[i].ID.String() < holds[j].ID.String()
+	})
+	if filter.Limit > 0 && len(holds) > filter.Limit {
+		holds = holds[:filter.Limit]
+	}
+
+	return holds, nil
+}
+
+func (h *holdRepository) CreateTx(ctx context.Context, _ *sql.Tx, newHold model.Hold) error {
+	return h.store.run(ctx, func(tx *memoryTx) error {
+		for id, hold := range h.store.holds {
+			if id == newHold.ID || (hold.WalletID == newHold.WalletID && hold.ReferenceID == newHold.ReferenceID) {
+				return errDuplicateKey
+			}
+		}
+
+		put(tx, h.store.holds, newHold.ID, newHold)
+		return nil
+	})
+}
+
+func (h *holdRepository) UpdateTx(ctx context.Context, _ *sql.Tx, hold model.Hold) (int64, error) {
+	var affected int64
+	err := h.store.run(ctx, func(tx *memoryTx) error {
+		current, ok := h.store.holds[hold.ID]
+		if !ok || current.Status != model.HoldStatus.Authorized {
+			return nil
+		}
+
+		current.Status = hold.Status
+		current.CapturedAmount = hold.CapturedAmount
+		current.UpdatedAt = hold.UpdatedAt
+		put(tx, h.store.holds, current.ID, current)
+		affected = 1
+		return nil
+	})
+
+	return affected, err
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type ledgerRepository struct {
+	store *Store
+}
+
+func NewLedgerRepository(store *Store) *ledgerRepository {
+	return &ledgerRepository{store: store}
+}
+
+func (l *ledgerRepository) PostTx(ctx context.Context, _ *sql.Tx, entry model.JournalEntry) error {
+	if !entry.IsBalanced() {
+		return model.ErrUnbalancedJournalEntry
+	}
+
+	return l.store.run(ctx, func(tx *memoryTx) error {
+		for id, posted := range l.store.journalEntries {
+			if id == entry.ID || posted.TransactionID == entry.TransactionID {
+				return errDuplicateKey
+			}
+		}
+
+		postings := make([]model.Posting, len(entry.Postings))
+		for i, p := range entry.Postings {
+			p.JournalEntryID = entry.ID
+			p.CreatedAt = entry.CreatedAt
+			postings[i] = p
+		}
+		entry.Postings = postings
+
+		put(tx, l.store.journalEntries, entry.ID, entry)
+		return nil
+	})
+}
+
+func (l *ledgerRepository) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	var balance int64
+	err := l.store.run(ctx, func(tx *memoryTx) error {
+		for _, entry := range l.store.journalEntries {
+			for _, p := range entry.Postings {
+				if p.Account == model.LedgerAccount.Wallet && p.WalletID != nil && *p.WalletID == walletID {
+					balance += p.Amount
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return balance, err
+}
+
+func (l *ledgerRepository) GetEntryTx(ctx context.Context, _ *sql.Tx, transactionID uuid.UUID) (model.JournalEntry, error) {
+	entry := model.JournalEntry{}
+	err := l.store.run(ctx, func(tx *memoryTx) error {
+		for _, posted := range l.store.journalEntries {
+			if posted.TransactionID == transactionID {
+				entry = model.JournalEntry{
+					ID:            posted.ID,
+					TransactionID: posted.TransactionID,
+					CreatedAt:     posted.CreatedAt,
+				}
+				return nil
+			}
+		}
+
+		return sql.ErrNoRows
+	})
+
+	return entry, err
+}
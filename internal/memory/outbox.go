@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type outboxRepository struct {
+	store *Store
+}
+
+func NewOutboxRepository(store *Store) *outboxRepository {
+	return &outboxRepository{store: store}
+}
+
+func (o *outboxRepository) CreateTx(ctx context.Context, _ *sql.Tx, event model.Event) error {
+	return o.store.run(ctx, func(tx *memoryTx) error {
+		if _, ok := o.store.events[event.ID]; ok {
+			return errDuplicateKey
+		}
+
+		event.LastError = nil
+		event.DeliveredAt = nil
+		put(tx, o.store.events, event.ID, event)
+		return nil
+	})
+}
+
+func (o *outboxRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Event, error) {
+	events := []model.Event{}
+	err := o.store.run(ctx, func(tx *memoryTx) error {
+		for _, event := range o.store.events {
+			if event.Status == model.EventStatus.Pending && !event.NextAttemptAt.After(now) {
+				events = append(events, event)
+			}
+		}
+
+		sort.Slice(events, func(i, j int) bool {
+			if !events[i].NextAttemptAt.Equal(events[j].NextAttemptAt) {
+				return events[i].NextAttemptAt.Before(events[j].NextAttemptAt)
+			}
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		})
+		if len(events) > limit {
+			events = events[:limit]
+		}
+
+		for i := range events {
+			events[i].NextAttemptAt = now.Add(lease)
+			events[i].UpdatedAt = now
+			put(tx, o.store.events, events[i].ID, events[i])
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (o *outboxRepository) Update(ctx context.Context, event model.Event) error {
+	return o.store.run(ctx, func(tx *memoryTx) error {
+		current, ok := o.store.events[event.ID]
+		if !ok {
+			return nil
+		}
+
+		current.Status = event.Status
+		current.Attempts = event.Attempts
+		current.NextAttemptAt = event.NextAttemptAt
+		current.LastError = event.LastError
+		current.DeliveredAt = event.DeliveredAt
+		current.UpdatedAt = event.UpdatedAt
+		put(tx, o.store.events, current.ID, current)
+		return nil
+	})
+}
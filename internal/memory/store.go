@@ -0,0 +1,130 @@
+// Package memory implements the repositories on top of plain maps, for tests
+// and local runs without a database.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+var errDuplicateKey = errors.New("duplicate key")
+
+// Store holds every table. Transactions run one at a time under the store
+// lock and roll back through an undo log, so they behave as serializable
+// database transactions. Repositories built on the same store share it.
+type Store struct {
+	mu sync.Mutex
+
+	accounts       map[uuid.UUID]model.Account
+	wallets        map[uuid.UUID]model.Wallet
+	transactions   map[uuid.UUID]model.Transaction
+	journalEntries map[uuid.UUID]model.JournalEntry
+	holds          map[uuid.UUID]model.Hold
+	events         map[uuid.UUID]model.Event
+	revokedTokens  map[uuid.UUID]model.Token
+	auditEntries   map[uuid.UUID]model.AuditEntry
+}
+
+func NewStore() *Store {
+	return &Store{
+		accounts:       map[uuid.UUID]model.Account{},
+		wallets:        map[uuid.UUID]model.Wallet{},
+		transactions:   map[uuid.UUID]model.Transaction{},
+		journalEntries: map[uuid.UUID]model.JournalEntry{},
+		holds:          map[uuid.UUID]model.Hold{},
+		events:         map[uuid.UUID]model.Event{},
+		revokedTokens:  map[uuid.UUID]model.Token{},
+		auditEntries:   map[uuid.UUID]model.AuditEntry{},
+	}
+}
+
+type txKey struct{}
+
+type memoryTx struct {
+	store *Store
+	undo  []func()
+}
+
+// Process implements internal.TxRepository. f receives a nil *sql.Tx, the
+// transaction travels in the context it is given, so repository calls
+// inside f must use that context. The options are ignored, transactions run
+// one at a time and so never conflict.
+func (s *Store) Process(ctx context.Context, f func(context.Context, *sql.Tx) error, _ ...internal.TxOption) error {
+	return s.run(ctx, func(tx *memoryTx) error {
+		return f(context.WithValue(ctx, txKey{}, tx), nil)
+	})
+}
+
+// run calls fn inside the transaction carried by ctx, or inside a new one
+// that commits when fn succeeds.
+func (s *Store) run(ctx context.Context, fn func(tx *memoryTx) error) (err error) {
+	if tx, ok := ctx.Value(txKey{}).(*memoryTx); ok && tx.store == s {
+		return fn(tx)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &memoryTx{store: s}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.rollback()
+			panic(r)
+		}
+		if err != nil {
+			tx.rollback()
+		}
+	}()
+
+	return fn(tx)
+}
+
+func (tx *memoryTx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+}
+
+// put writes row under key and remembers how to take it back.
+func put[K comparable, V any](tx *memoryTx, table map[K]V, key K, row V) {
+	old, existed := table[key]
+	tx.undo = append(tx.undo, func() {
+		if existed {
+			table[key] = old
+		} else {
+			delete(table, key)
+		}
+	})
+	table[key] = row
+}
+
+func remove[K comparable, V any](tx *memoryTx, table map[K]V, key K) {
+	old, existed := table[key]
+	if !existed {
+		return
+	}
+	tx.undo = append(tx.undo, func() { table[key] = old })
+	delete(table, key)
+}
+
+// in mirrors "column = ANY($1) OR $1 IS NULL", a nil filter matches all.
+func in(values []string, value string) bool {
+	if values == nil {
+		return true
+	}
+
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	t.Run("Commit", TestCommit)
+	t.Run("Rollback", TestRollback)
+	t.Run("RollbackOnPanic", TestRollbackOnPanic)
+	t.Run("ConcurrentDecrement", TestConcurrentDecrement)
+}
+
+func newWallet(t *testing.T, store *Store, balance int64) model.Wallet {
+	timestamp := time.Now()
+	wallet := model.Wallet{
+		ID:        uuid.New(),
+		OwnedBy:   uuid.New(),
+		Balance:   balance,
+		Status:    model.WalletStatus.Enabled,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+	}
+	err := NewWalletRepository(store).CreateTx(context.Background(), nil, wallet)
+	assert.NoError(t, err)
+
+	return wallet
+}
+
+func TestCommit(t *testing.T) {
+	store := NewStore()
+	walletRepo := NewWalletRepository(store)
+	wallet := newWallet(t, store, 1000)
+
+	err := store.Process(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		_, err := walletRepo.Increment(ctx, tx, wallet, 500, 0)
+		assert.NoError(t, err)
+
+		// reads inside the transaction see its own writes
+		current, err := walletRepo.GetOne(ctx, internal.WalletFilter{IDs: []string{wallet.ID.String()}})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1500), current.Balance)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	current, err := walletRepo.GetOne(context.Background(), internal.WalletFilter{IDs: []string{wallet.ID.String()}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500), current.Balance)
+}
+
+func TestRollback(t *testing.T) {
+	store := NewStore()
+	walletRepo := NewWalletRepository(store)
+	transactionRepo := NewTransactionRepository(store)
+	wallet := newWallet(t, store, 1000)
+
+	errExpected := errors.New("err")
+	err := store.Process(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+		_, err := walletRepo.Increment(ctx, tx, wallet, 500, 0)
+		assert.NoError(t, err)
+
+		err = transactionRepo.CreateTx(ctx, tx, model.Transaction{
+			ID:          uuid.New(),
+			WalletID:    wallet.ID,
+			Type:        model.TransactionType.Deposit,
+			Status:      model.TransactionStatus.Success,
+			Amount:      500,
+			ReferenceID: "abc",
+		})
+		assert.NoError(t, err)
+		return errExpected
+	})
+	assert.ErrorIs(t, err, errExpected)
+
+	current, err := walletRepo.GetOne(context.Background(), internal.WalletFilter{IDs: []string{wallet.ID.String()}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), current.Balance)
+
+	transactions, err := transactionRepo.List(context.Background(), internal.TransactionFilter{})
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func TestRollbackOnPanic(t *testing.T) {
+	store := NewStore()
+	walletRepo := NewWalletRepository(store)
+	wallet := newWallet(t, store, 1000)
+
+	assert.Panics(t, func() {
+		_ = store.Process(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+			_, _ = walletRepo.Decrement(ctx, tx, wallet, 1000)
+			panic("boom")
+		})
+	})
+
+	// the lock was released and the decrement undone
+	current, err := walletRepo.GetOne(context.Background(), internal.WalletFilter{IDs: []string{wallet.ID.String()}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), current.Balance)
+}
+
+func TestConcurrentDecrement(t *testing.T) {
+	store := NewStore()
+	walletRepo := NewWalletRepository(store)
+	wallet := newWallet(t, store, 1000)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	succeeded := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = store.Process(context.Background(), func(ctx context.Context, tx *sql.Tx) error {
+				affected, err := walletRepo.Decrement(ctx, tx, wallet, 100)
+				if err != nil || affected == 0 {
+					return model.ErrInsufficientBalance
+				}
+
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 10, succeeded)
+	current, err := walletRepo.GetOne(context.Background(), internal.WalletFilter{IDs: []string{wallet.ID.String()}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), current.Balance)
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type tokenRepository struct {
+	store *Store
+}
+
+func NewTokenRepository(store *Store) *tokenRepository {
+	return &tokenRepository{store: store}
+}
+
+func (t *tokenRepository) Revoke(ctx context.Context, token model.Token) (bool, error) {
+	revoked := false
+	err := t.store.run(ctx, func(tx *memoryTx) error {
+		if _, ok := t.store.revokedTokens[token.ID]; ok {
+			return nil
+		}
+
+		put(tx, t.store.revokedTokens, token.ID, token)
+		revoked = true
+		return nil
+	})
+
+	return revoked, err
+}
+
+func (t *tokenRepository) IsRevoked(ctx context.Context, tokenID uuid.UUID) (bool, error) {
+	revoked := false
+	err := t.store.run(ctx, func(tx *memoryTx) error {
+		_, revoked = t.store.revokedTokens[tokenID]
+		return nil
+	})
+
+	return revoked, err
+}
+
+func (t *tokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	err := t.store.run(ctx, func(tx *memoryTx) error {
+		for id, token := range t.store.revokedTokens {
+			if token.ExpiresAt.Before(now) {
+				remove(tx, t.store.revokedTokens, id)
+				deleted++
+			}
+		}
+
+		return nil
+	})
+
+	return deleted, err
+}
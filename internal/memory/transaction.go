@@ -0,0 +1,198 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type transactionRepository struct {
+	store *Store
+}
+
+func NewTransactionRepository(store *Store) *transactionRepository {
+	return &transactionRepository{store: store}
+}
+
+func (t *transactionRepository) List(ctx context.Context, filter internal.TransactionFilter) ([]model.Transaction, error) {
+	transactions := []model.Transaction{}
+	err := t.store.run(ctx, func(tx *memoryTx) error {
+		for _, transaction := range t.store.transactions {
+			if matchTransaction(filter, transaction) {
+				transactions = append(transactions, transaction)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(transactions, func(i, j int) bool {
+		if filter.Ascending {
+			return before(transactions[i], transactions[j].CreatedAt, transactions[j].ID.String())
+		}
+		return before(transactions[j], transactions[i].CreatedAt, transactions[i].ID.String())
+	})
+	if filter.Limit > 0 && len(transactions) > filter.Limit {
+		transactions = transactions[:filter.Limit]
+	}
+
+	return transactions, nil
+}
+
+// Summarize counts and sums the transactions matching the wallet, type,
+// status and transacted_at filters, other filters are ignored.
+func (t *transactionRepository) Summarize(ctx context.Context, filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+	filter = internal.TransactionFilter{
+		WalletIDs:      filter.WalletIDs,
+		Types:          filter.Types,
+		Statuses:       filter.Statuses,
+		TransactedFrom: filter.TransactedFrom,
+		TransactedTo:   filter.TransactedTo,
+	}
+
+	summary := internal.TransactionSummary{}
+	err := t.store.run(ctx, func(tx *memoryTx) error {
+		for _, transaction := range t.store.transactions {
+			if matchTransaction(filter, transaction) {
+				summary.Count++
+				summary.Amount += transaction.Amount
+			}
+		}
+
+		return nil
+	})
+
+	return summary, err
+}
+
+func (t *transactionRepository) SummarizeTx(
+	ctx context.Context,
+	_ *sql.Tx,
+	filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+	return t.Summarize(ctx, filter)
+}
+
+func (t *transactionRepository) Create(ctx context.Context, newTransaction model.Transaction) error {
+	newTransaction.TransactedAt = nil
+	newTransaction.ParentID = nil
+	return t.CreateTx(ctx, nil, newTransaction)
+}
+
+func (t *transactionRepository) CreateTx(ctx context.Context, _ *sql.Tx, newTransaction model.Transaction) error {
+	return t.store.run(ctx, func(tx *memoryTx) error {
+		for id, transaction := range t.store.transactions {
+			if id == newTransaction.ID {
+				return errDuplicateKey
+			}
+			if transaction.WalletID == newTransaction.WalletID && transaction.ReferenceID == newTransaction.ReferenceID {
+				return model.ErrDuplicateReferenceID
+			}
+		}
+
+		newTransaction.RefundedAmount = 0
+		put(tx, t.store.transactions, newTransaction.ID, newTransaction)
+		return nil
+	})
+}
+
+// UpdateTx mirrors the status guard of the sql update, a missing transaction
+// or a status that may not move returns ErrInvalidStatusTransition.
+func (t *transactionRepository) UpdateTx(ctx context.Context, _ *sql.Tx, transaction model.Transaction) error {
+	return t.store.run(ctx, func(tx *memoryTx) error {
+		current, ok := t.store.transactions[transaction.ID]
+		if !ok || !current.CanTransitionTo(transaction.Status) {
+			return model.ErrInvalidStatusTransition
+		}
+
+		current.Status = transaction.Status
+		current.TransactedAt = transaction.TransactedAt
+		current.UpdatedAt = transaction.UpdatedAt
+		put(tx, t.store.transactions, current.ID, current)
+		return nil
+	})
+}
+
+// AddRefundedTx books amount as refunded on the transaction. Nothing is
+// updated when the transaction did not succeed or the amount goes past what
+// is left to refund.
+func (t *transactionRepository) AddRefundedTx(ctx context.Context, _ *sql.Tx, transaction model.Transaction, amount int64) (int64, error) {
+	var affected int64
+	err := t.store.run(ctx, func(tx *memoryTx) error {
+		current, ok := t.store.transactions[transaction.ID]
+		if !ok || current.Status != model.TransactionStatus.Success || current.RefundedAmount+amount > current.Amount {
+			return nil
+		}
+
+		current.RefundedAmount += amount
+		current.UpdatedAt = transaction.UpdatedAt
+		put(tx, t.store.transactions, current.ID, current)
+		affected = 1
+		return nil
+	})
+
+	return affected, err
+}
+
+func matchTransaction(filter internal.TransactionFilter, t model.Transaction) bool {
+	if !in(filter.IDs, t.ID.String()) ||
+		!in(filter.WalletIDs, t.WalletID.String()) ||
+		!in(filter.ReferenceIDs, t.ReferenceID) ||
+		!in(filter.Types, t.Type) ||
+		!in(filter.Statuses, t.Status) {
+		return false
+	}
+
+	if filter.MinAmount != nil && t.Amount < *filter.MinAmount {
+		return false
+	}
+	if filter.MaxAmount != nil && t.Amount > *filter.MaxAmount {
+		return false
+	}
+
+	if filter.TransactedFrom != nil && (t.TransactedAt == nil || t.TransactedAt.Before(*filter.TransactedFrom)) {
+		return false
+	}
+	if filter.TransactedTo != nil && (t.TransactedAt == nil || t.TransactedAt.After(*filter.TransactedTo)) {
+		return false
+	}
+
+	if filter.CreatedBefore != nil && !t.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+
+	if filter.After != nil {
+		if filter.Ascending && !after(t, filter.After.CreatedAt, filter.After.ID) {
+			return false
+		}
+		if !filter.Ascending && !before(t, filter.After.CreatedAt, filter.After.ID) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// before mirrors "(created_at, id) < ($1, $2)".
+func before(t model.Transaction, createdAt time.Time, id string) bool {
+	if !t.CreatedAt.Equal(createdAt) {
+		return t.CreatedAt.Before(createdAt)
+	}
+
+	return t.ID.String() < id
+}
+
+// after mirrors "(created_at, id) > ($1, $2)".
+func after(t model.Transaction, createdAt time.Time, id string) bool {
+	if !t.CreatedAt.Equal(createdAt) {
+		return t.CreatedAt.After(createdAt)
+	}
+
+	return t.ID.String() > id
+}
@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type walletRepository struct {
+	store *Store
+}
+
+func NewWalletRepository(store *Store) *walletRepository {
+	return &walletRepository{store: store}
+}
+
+func (w *walletRepository) GetOne(ctx context.Context, filter internal.WalletFilter) (model.Wallet, error) {
+	found := model.Wallet{}
+	err := w.store.run(ctx, func(tx *memoryTx) error {
+		for _, wallet := range w.store.wallets {
+			if in(filter.IDs, wallet.ID.String()) && in(filter.OwnedBies, wallet.OwnedBy.String()) {
+				found = wallet
+				return nil
+			}
+		}
+
+		return sql.ErrNoRows
+	})
+
+	return found, err
+}
+
+func (w *walletRepository) Update(ctx context.Context, wallet model.Wallet) error {
+	return w.UpdateTx(ctx, nil, wallet)
+}
+
+// UpdateTx only writes the status columns, balances move through
+// Increment, Decrement, Reserve, Release and Capture.
+func (w *walletRepository) UpdateTx(ctx context.Context, _ *sql.Tx, wallet model.Wallet) error {
+	affected, err := w.update(ctx, wallet, func(current *model.Wallet) bool {
+		if current.Version != wallet.Version {
+			return false
+		}
+		current.Version++
+		current.Status = wallet.Status
+		current.EnabledAt = wallet.EnabledAt
+		current.DisabledAt = wallet.DisabledAt
+		current.UpdatedAt = wallet.UpdatedAt
+		return true
+	})
+	if err == nil && affected == 0 {
+		return model.ErrWalletVersionConflict
+	}
+
+	return err
+}
+
+func (w *walletRepository) CreateTx(ctx context.Context, _ *sql.Tx, newWallet model.Wallet) error {
+	return w.store.run(ctx, func(tx *memoryTx) error {
+		for id, wallet := range w.store.wallets {
+			if id == newWallet.ID || wallet.OwnedBy == newWallet.OwnedBy {
+				return errDuplicateKey
+			}
+		}
+
+		newWallet.Reserved = 0
+		put(tx, w.store.wallets, newWallet.ID, newWallet)
+		return nil
+	})
+}
+
+// LockTx reads the wallet, transactions of the store run one at a time so
+// it needs no lock of its own.
+func (w *walletRepository) LockTx(ctx context.Context, _ *sql.Tx, walletID uuid.UUID) (model.Wallet, error) {
+	return w.GetOne(ctx, internal.WalletFilter{IDs: []string{walletID.String()}})
+}
+
+func (w *walletRepository) Increment(
+	ctx context.Context,
+	_ *sql.Tx,
+	wallet model.Wallet,
+	amount int64,
+	maxBalance int64) (int64, error) {
+	return w.update(ctx, wallet, func(current *model.Wallet) bool {
+		if maxBalance > 0 && current.Balance+amount > maxBalance {
+			return false
+		}
+		current.Balance += amount
+		current.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (w *walletRepository) Decrement(ctx context.Context, _ *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+	return w.update(ctx, wallet, func(current *model.Wallet) bool {
+		if current.AvailableBalance() < amount {
+			return false
+		}
+		current.Balance -= amount
+		current.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (w *walletRepository) Reserve(ctx context.Context, _ *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+	return w.update(ctx, wallet, func(current *model.Wallet) bool {
+		if current.AvailableBalance() < amount {
+			return false
+		}
+		current.Reserved += amount
+		current.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (w *walletRepository) Release(ctx context.Context, _ *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+	return w.update(ctx, wallet, func(current *model.Wallet) bool {
+		if current.Reserved < amount {
+			return false
+		}
+		current.Reserved -= amount
+		current.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+func (w *walletRepository) Capture(ctx context.Context, _ *sql.Tx, wallet model.Wallet, reserved int64, amount int64) (int64, error) {
+	return w.update(ctx, wallet, func(current *model.Wallet) bool {
+		if current.Reserved < reserved || current.Balance < amount {
+			return false
+		}
+		current.Reserved -= reserved
+		current.Balance -= amount
+		current.UpdatedAt = time.Now()
+		return true
+	})
+}
+
+// update applies change to the stored wallet and returns the affected rows,
+// change reports false when its guard does not hold.
+func (w *walletRepository) update(ctx context.Context, wallet model.Wallet, change func(current *model.Wallet) bool) (int64, error) {
+	var affected int64
+	err := w.store.run(ctx, func(tx *memoryTx) error {
+		current, ok := w.store.wallets[wallet.ID]
+		if !ok || !change(&current) {
+			return nil
+		}
+
+		put(tx, w.store.wallets, current.ID, current)
+		affected = 1
+		return nil
+	})
+
+	return affected, err
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/fee"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/internal/token"
+	"github.com/hokdre/mini-ewallet/internal/wallet"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWalletServiceEndToEnd runs the real wallet service on the memory
+// store, the ledger has to agree with the cached balances at the end.
+func TestWalletServiceEndToEnd(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore()
+	encryption, err := util.NewAesEncryption(util.AesKey{ID: "1", Secret: "1111222233334444"})
+	assert.NoError(t, err)
+
+	tokenService := token.NewTokenService(token.Config{
+		Encryption:      encryption,
+		TokenRepository: NewTokenRepository(store),
+	})
+	ledgerRepo := NewLedgerRepository(store)
+	walletRepo := NewWalletRepository(store)
+	auditRepo := NewAuditRepository(store)
+	service := wallet.NewWalletService(wallet.Config{
+		AccountRepo:           NewAccountRepository(store),
+		WalletRepository:      walletRepo,
+		TransactionRepository: NewTransactionRepository(store),
+		TxRepository:          store,
+		LedgerRepository:      ledgerRepo,
+		HoldRepository:        NewHoldRepository(store),
+		OutboxRepository:      NewOutboxRepository(store),
+		AuditRepository:       auditRepo,
+		TokenService:          tokenService,
+		Validator:             util.NewValidator(),
+		WithdrawalFee:         fee.NewFlat(100),
+	})
+
+	open := func(externalID string) (uuid.UUID, model.Wallet) {
+		tokens, err := service.Init(ctx, externalID)
+		assert.NoError(t, err)
+		claims, err := tokenService.Verify(ctx, tokens.AccessToken, model.TokenType.Access)
+		assert.NoError(t, err)
+
+		w, err := service.Enable(ctx, claims.AccountID, 0)
+		assert.NoError(t, err)
+		return claims.AccountID, w
+	}
+	alice, aliceWallet := open(uuid.New().String())
+	bob, bobWallet := open(uuid.New().String())
+
+	_, err = service.Deposit(ctx, alice, model.Transaction{ReferenceID: "deposit-1", Amount: 10000})
+	assert.NoError(t, err)
+
+	_, err = service.Withdrawal(ctx, alice, model.Transaction{ReferenceID: "withdrawal-1", Amount: 1000})
+	assert.NoError(t, err)
+
+	_, err = service.Transfer(ctx, alice, bobWallet.ID, model.Transaction{ReferenceID: "transfer-1", Amount: 2000})
+	assert.NoError(t, err)
+
+	// an uncovered withdrawal leaves nothing but a failed transaction behind
+	failed, err := service.Withdrawal(ctx, bob, model.Transaction{ReferenceID: "withdrawal-2", Amount: 5000})
+	assert.NoError(t, err)
+	assert.Equal(t, model.TransactionStatus.Failed, failed.Status)
+
+	// concurrent withdrawals never overdraw
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = service.Withdrawal(ctx, alice, model.Transaction{ReferenceID: uuid.New().String(), Amount: 900})
+		}()
+	}
+	wg.Wait()
+
+	for _, w := range []model.Wallet{aliceWallet, bobWallet} {
+		current, err := walletRepo.GetOne(ctx, internal.WalletFilter{IDs: []string{w.ID.String()}})
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, current.Balance, int64(0))
+
+		ledgerBalance, err := ledgerRepo.GetWalletBalance(ctx, w.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, ledgerBalance, current.Balance)
+	}
+
+	bobCurrent, err := service.Get(ctx, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000), bobCurrent.Balance)
+
+	// 10000 - 1100 - 2000 leaves 6900, enough for exactly 6 withdrawals of 1000
+	aliceCurrent, err := service.Get(ctx, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(900), aliceCurrent.Balance)
+
+	// balance moves keep the version bob's wallet was enabled with
+	assert.Equal(t, bobWallet.Version, bobCurrent.Version)
+
+	// of concurrent disables only one lands
+	var disabled atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.Disable(ctx, bob, bobCurrent.Version); err == nil {
+				disabled.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), disabled.Load())
+
+	// the disabled wallet still reports the version to enable it with
+	bobDisabled, err := service.Get(ctx, bob)
+	assert.ErrorIs(t, err, model.ErrWalletDisabled)
+	assert.Equal(t, bobCurrent.Version+1, bobDisabled.Version)
+
+	_, err = service.Enable(ctx, bob, bobCurrent.Version)
+	assert.ErrorIs(t, err, model.ErrWalletVersionConflict)
+	_, err = service.Enable(util.ContextWithOperator(ctx, "ops"), bob, bobDisabled.Version)
+	assert.NoError(t, err)
+
+	// every status change is audited, the operator only when one acted
+	entries, err := auditRepo.List(ctx, bobWallet.ID.String())
+	assert.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, model.AuditAction.WalletEnabled, entries[0].Action)
+	assert.Equal(t, "ops", entries[0].Operator)
+	assert.Equal(t, model.AuditAction.WalletDisabled, entries[1].Action)
+	assert.Equal(t, "", entries[1].Operator)
+}
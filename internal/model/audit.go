@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var AuditAction = struct {
+	WalletEnabled  string
+	WalletDisabled string
+	TokenIssued    string
+}{
+	WalletEnabled:  "wallet_enabled",
+	WalletDisabled: "wallet_disabled",
+	TokenIssued:    "token_issued",
+}
+
+// AuditEntry records who changed the status of a wallet or issued a token
+// for its owner, Operator is empty when the account holder changed it
+// through the api. Detail says what was done beyond the action.
+type AuditEntry struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	WalletID  uuid.UUID `json:"wallet_id" db:"wallet_id"`
+	Action    string    `json:"action" db:"action"`
+	Operator  string    `json:"operator" db:"operator"`
+	Detail    string    `json:"detail" db:"detail"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
@@ -6,10 +6,75 @@ import (
 )
 
 var (
-	ErrBussiness             = errors.New("Bussiness Error")
-	ErrWalletAlreadyEnabled  = fmt.Errorf("%w : Already Enabled", ErrBussiness)
-	ErrWalletAlreadyDisabled = fmt.Errorf("%w : Already Disabled", ErrBussiness)
-	ErrWalletDisabled        = fmt.Errorf("%w : Wallet Disabled", ErrBussiness)
+	ErrBussiness              = errors.New("Bussiness Error")
+	ErrWalletAlreadyEnabled   = fmt.Errorf("%w : Already Enabled", ErrBussiness)
+	ErrWalletAlreadyDisabled  = fmt.Errorf("%w : Already Disabled", ErrBussiness)
+	ErrWalletDisabled         = fmt.Errorf("%w : Wallet Disabled", ErrBussiness)
+	ErrReceiverWalletDisabled = fmt.Errorf("%w : Receiver Wallet Disabled", ErrBussiness)
+	ErrTransferToOwnWallet    = fmt.Errorf("%w : Cannot Transfer To Own Wallet", ErrBussiness)
+	ErrInsufficientBalance    = fmt.Errorf("%w : Insufficient Balance", ErrBussiness)
+	ErrHoldNotAuthorized      = fmt.Errorf("%w : Hold Is Not Authorized", ErrBussiness)
+	ErrHoldExpired            = fmt.Errorf("%w : Hold Expired", ErrBussiness)
+	ErrCaptureExceedsHold     = fmt.Errorf("%w : Capture Amount Exceeds Hold", ErrBussiness)
+	// ErrReservedMismatch is a hold the reserved balance of its wallet no
+	// longer covers.
+	ErrReservedMismatch       = fmt.Errorf("%w : Reserved Balance Does Not Cover The Hold", ErrBussiness)
+	ErrNotRefundable          = fmt.Errorf("%w : Transaction Is Not Refundable", ErrBussiness)
+	ErrRefundExceedsRemaining = fmt.Errorf("%w : Refund Amount Exceeds Refundable Amount", ErrBussiness)
+	ErrNotAnAdjustment        = fmt.Errorf("%w : Transaction Is Not An Adjustment", ErrBussiness)
+	ErrAdjustmentNoReason     = fmt.Errorf("%w : Adjustment Needs A Reason", ErrBussiness)
+	ErrAdjustmentNoOperator   = fmt.Errorf("%w : Adjustment Needs An Operator", ErrBussiness)
+	ErrInvalidStatementPeriod = fmt.Errorf("%w : Statement Period Ends Before It Starts", ErrBussiness)
+
+	ErrAmountLimitExceeded        = newCodeError("amount_limit_exceeded", "Amount Exceeds Single Transaction Limit")
+	ErrDailyAmountLimitExceeded   = newCodeError("daily_amount_limit_exceeded", "Daily Amount Limit Exceeded")
+	ErrMonthlyAmountLimitExceeded = newCodeError("monthly_amount_limit_exceeded", "Monthly Amount Limit Exceeded")
+	ErrDailyCountLimitExceeded    = newCodeError("daily_count_limit_exceeded", "Daily Transaction Count Limit Exceeded")
+	ErrMonthlyCountLimitExceeded  = newCodeError("monthly_count_limit_exceeded", "Monthly Transaction Count Limit Exceeded")
+	ErrBalanceLimitExceeded       = newCodeError("balance_limit_exceeded", "Wallet Balance Limit Exceeded")
+
+	ErrConflict            = errors.New("Conflict")
+	ErrReferenceIDConflict = fmt.Errorf("%w : Reference ID Already Used With Different Payload", ErrConflict)
+	// ErrDuplicateReferenceID is a reference stored concurrently, it lost the
+	// race against a request the replay lookup did not see yet.
+	ErrDuplicateReferenceID = fmt.Errorf("%w : Duplicate Reference ID", ErrConflict)
+	// ErrInvalidStatusTransition is returned when a transaction already left
+	// the status it was read with, usually because it was resolved concurrently.
+	ErrInvalidStatusTransition = fmt.Errorf("%w : Transaction Status Can Not Change", ErrConflict)
+	// ErrWalletVersionConflict is returned when the wallet is no longer at the
+	// version it was read with or the client asked for with If-Match.
+	ErrWalletVersionConflict = fmt.Errorf("%w : Wallet Was Changed By Another Request", ErrConflict)
+
+	ErrUnbalancedJournalEntry = errors.New("Unbalanced Journal Entry")
 
 	ErrLoginInfoUknown = errors.New("Login info unknown")
+	ErrTokenInvalid    = fmt.Errorf("%w : Token Invalid", ErrLoginInfoUknown)
+	ErrTokenExpired    = fmt.Errorf("%w : Token Expired", ErrLoginInfoUknown)
+	ErrTokenRevoked    = fmt.Errorf("%w : Token Revoked", ErrLoginInfoUknown)
+
+	ErrForbidden         = errors.New("Forbidden")
+	ErrInsufficientScope = fmt.Errorf("%w : Insufficient Scope", ErrForbidden)
+
+	ErrRateLimited = errors.New("Too Many Requests")
 )
+
+// CodeError is a business error carrying a machine readable code for clients.
+type CodeError struct {
+	Code string
+	err  error
+}
+
+func newCodeError(code string, message string) *CodeError {
+	return &CodeError{
+		Code: code,
+		err:  fmt.Errorf("%w : %s", ErrBussiness, message),
+	}
+}
+
+func (e *CodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *CodeError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	EventType = struct {
+		WalletEnabled        string
+		WalletDisabled       string
+		TransactionSucceeded string
+		TransactionFailed    string
+	}{
+		WalletEnabled:        "wallet.enabled",
+		WalletDisabled:       "wallet.disabled",
+		TransactionSucceeded: "transaction.succeeded",
+		TransactionFailed:    "transaction.failed",
+	}
+
+	EventStatus = struct {
+		Pending   string
+		Delivered string
+		Dead      string
+	}{
+		Pending:   "pending",
+		Delivered: "delivered",
+		Dead:      "dead",
+	}
+)
+
+// Event is a domain event stored in the outbox in the same database
+// transaction as the change it describes, it is delivered as a webhook later.
+type Event struct {
+	ID            uuid.UUID       `json:"id" db:"id"`
+	Type          string          `json:"type" db:"type"`
+	WalletID      uuid.UUID       `json:"wallet_id" db:"wallet_id"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	Status        string          `json:"status" db:"status"`
+	Attempts      int             `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     *string         `json:"last_error" db:"last_error"`
+	DeliveredAt   *time.Time      `json:"delivered_at" db:"delivered_at"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var HoldStatus = struct {
+	Authorized string
+	Captured   string
+	Voided     string
+	Expired    string
+}{
+	Authorized: "authorized",
+	Captured:   "captured",
+	Voided:     "voided",
+	Expired:    "expired",
+}
+
+// Hold reserves Amount on a wallet until it is captured, voided or expires.
+type Hold struct {
+	ID             uuid.UUID `json:"id" db:"id" validate:"required"`
+	WalletID       uuid.UUID `json:"wallet_id" db:"wallet_id" validate:"required"`
+	Amount         int64     `json:"amount" db:"amount" validate:"gte=1"`
+	CapturedAmount int64     `json:"captured_amount" db:"captured_amount" validate:"gte=0"`
+	Status         string    `json:"status" db:"status" validate:"enumHoldStatus"`
+	ReferenceID    string    `json:"reference_id" db:"reference_id" validate:"required"`
+	ExpiresAt      time.Time `json:"expires_at" db:"expires_at" validate:"required"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at" validate:"required"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at" validate:"required"`
+}
+
+func (h Hold) IsExpired(now time.Time) bool {
+	return h.Status == HoldStatus.Authorized && !now.Before(h.ExpiresAt)
+}
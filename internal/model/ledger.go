@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerAccount lists the accounts postings can be booked against. Wallet
+// postings also carry the wallet id, the others are system accounts.
+var LedgerAccount = struct {
+	Wallet           string
+	CashIn           string
+	CashOut          string
+	Fees             string
+	TransferClearing string
+	Adjustments      string
+}{
+	Wallet:           "wallet",
+	CashIn:           "cash-in",
+	CashOut:          "cash-out",
+	Fees:             "fees",
+	TransferClearing: "transfer-clearing",
+	Adjustments:      "adjustments",
+}
+
+type JournalEntry struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	TransactionID uuid.UUID `json:"transaction_id" db:"transaction_id"`
+	Postings      []Posting `json:"postings"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// Posting moves Amount into Account, a negative amount moves it out.
+type Posting struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	JournalEntryID uuid.UUID  `json:"journal_entry_id" db:"journal_entry_id"`
+	Account        string     `json:"account" db:"account"`
+	WalletID       *uuid.UUID `json:"wallet_id" db:"wallet_id"`
+	Amount         int64      `json:"amount" db:"amount"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsBalanced reports whether the postings of the entry cancel each other out.
+func (j JournalEntry) IsBalanced() bool {
+	if len(j.Postings) < 2 {
+		return false
+	}
+
+	var total int64
+	for _, p := range j.Postings {
+		total += p.Amount
+	}
+
+	return total == 0
+}
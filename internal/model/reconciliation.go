@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var OrphanKind = struct {
+	MissingJournalEntry   string
+	UnsettledJournalEntry string
+}{
+	// a successful transaction that never reached the ledger
+	MissingJournalEntry: "missing_journal_entry",
+	// a ledger entry booked for a transaction that did not succeed
+	UnsettledJournalEntry: "unsettled_journal_entry",
+}
+
+// WalletBalance is the stored balance of a wallet next to the balance
+// recomputed from its successful transactions and from its ledger postings.
+// Each drift is the stored balance minus the matching recomputed balance.
+type WalletBalance struct {
+	WalletID           uuid.UUID `json:"wallet_id"`
+	Balance            int64     `json:"balance"`
+	TransactionBalance int64     `json:"transaction_balance"`
+	LedgerBalance      int64     `json:"ledger_balance"`
+	TransactionDrift   int64     `json:"transaction_drift"`
+	LedgerDrift        int64     `json:"ledger_drift"`
+}
+
+func (b WalletBalance) IsConsistent() bool {
+	return b.Balance == b.TransactionBalance && b.Balance == b.LedgerBalance
+}
+
+// Orphan is a transaction whose ledger entry does not match its status.
+type Orphan struct {
+	Kind          string    `json:"kind"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	WalletID      uuid.UUID `json:"wallet_id"`
+	Type          string    `json:"type"`
+	Status        string    `json:"status"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ReconciliationReport struct {
+	CheckedAt    time.Time       `json:"checked_at"`
+	Wallets      int             `json:"wallets"`
+	Drifts       []WalletBalance `json:"drifts"`
+	StuckPending []Transaction   `json:"stuck_pending"`
+	Orphans      []Orphan        `json:"orphans"`
+}
+
+func (r ReconciliationReport) IsClean() bool {
+	return len(r.Drifts) == 0 && len(r.StuckPending) == 0 && len(r.Orphans) == 0
+}
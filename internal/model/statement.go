@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statement covers the successful transactions of a wallet transacted
+// between From and To, both included.
+type Statement struct {
+	WalletID       uuid.UUID `json:"wallet_id"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	OpeningBalance int64     `json:"opening_balance"`
+	ClosingBalance int64     `json:"closing_balance"`
+	GeneratedAt    time.Time `json:"generated_at"`
+}
+
+// StatementLine is a transaction of the statement with the balance right
+// after it.
+type StatementLine struct {
+	Transaction Transaction
+	Balance     int64
+}
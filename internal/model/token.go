@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var TokenType = struct {
+	Access  string
+	Refresh string
+}{
+	Access:  "access",
+	Refresh: "refresh",
+}
+
+var TokenScope = struct {
+	WalletRead  string
+	WalletWrite string
+}{
+	WalletRead:  "wallet:read",
+	WalletWrite: "wallet:write",
+}
+
+// Token is the claim set sealed inside an access or refresh token.
+type Token struct {
+	ID        uuid.UUID `json:"jti"`
+	AccountID uuid.UUID `json:"sub"`
+	Type      string    `json:"typ"`
+	Scopes    []string  `json:"scp"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"exp"`
+}
+
+func (t Token) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+func (t Token) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
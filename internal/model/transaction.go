@@ -8,11 +8,27 @@ import (
 
 var (
 	TransactionType = struct {
-		Withdrawal string
-		Deposit    string
+		Withdrawal       string
+		Deposit          string
+		TransferOut      string
+		TransferIn       string
+		Capture          string
+		Refund           string
+		Reversal         string
+		Fee              string
+		AdjustmentCredit string
+		AdjustmentDebit  string
 	}{
-		Withdrawal: "withdrawal",
-		Deposit:    "deposit",
+		Withdrawal:       "withdrawal",
+		Deposit:          "deposit",
+		TransferOut:      "transfer_out",
+		TransferIn:       "transfer_in",
+		Capture:          "capture",
+		Refund:           "refund",
+		Reversal:         "reversal",
+		Fee:              "fee",
+		AdjustmentCredit: "adjustment_credit",
+		AdjustmentDebit:  "adjustment_debit",
 	}
 
 	TransactionStatus = struct {
@@ -24,16 +40,102 @@ var (
 		Success: "success",
 		Failed:  "failed",
 	}
+
+	// CreditTransactionTypes add their amount to the wallet balance, every
+	// other type takes it off.
+	CreditTransactionTypes = []string{
+		TransactionType.Deposit,
+		TransactionType.TransferIn,
+		TransactionType.Refund,
+		TransactionType.AdjustmentCredit,
+	}
+
+	DebitTransactionTypes = []string{
+		TransactionType.Withdrawal,
+		TransactionType.TransferOut,
+		TransactionType.Capture,
+		TransactionType.Reversal,
+		TransactionType.Fee,
+		TransactionType.AdjustmentDebit,
+	}
+
+	// transactionTransitions lists every status change a transaction may
+	// make, success and failed are final.
+	transactionTransitions = []struct {
+		from string
+		to   string
+	}{
+		{from: TransactionStatus.Pending, to: TransactionStatus.Success},
+		{from: TransactionStatus.Pending, to: TransactionStatus.Failed},
+	}
 )
 
+// TransactionStatusesBefore lists the statuses a transaction may leave for
+// status.
+func TransactionStatusesBefore(status string) []string {
+	statuses := []string{}
+	for _, transition := range transactionTransitions {
+		if transition.to == status {
+			statuses = append(statuses, transition.from)
+		}
+	}
+
+	return statuses
+}
+
 type Transaction struct {
-	ID           uuid.UUID  `json:"id" db:"id" validate:"required"`
-	WalletID     uuid.UUID  `json:"wallet_id" db:"wallet_id" validate:"required"`
-	Type         string     `json:"type" db:"type" validate:"enumTransactionType"`
-	Status       string     `json:"status" db:"status" validate:"enumTransactionStatus"`
-	TransactedAt *time.Time `json:"transacted_at" db:"transacted_at"`
-	Amount       int64      `json:"amount" db:"amount" validate:"gte=1"`
-	ReferenceID  string     `json:"reference_id" db:"reference_id" validate:"required"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at" validate:"required"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at" validate:"required"`
+	ID             uuid.UUID  `json:"id" db:"id" validate:"required"`
+	WalletID       uuid.UUID  `json:"wallet_id" db:"wallet_id" validate:"required"`
+	Type           string     `json:"type" db:"type" validate:"enumTransactionType"`
+	Status         string     `json:"status" db:"status" validate:"enumTransactionStatus"`
+	TransactedAt   *time.Time `json:"transacted_at" db:"transacted_at"`
+	Amount         int64      `json:"amount" db:"amount" validate:"gte=1"`
+	Fee            int64      `json:"fee" db:"fee" validate:"gte=0"`
+	ReferenceID    string     `json:"reference_id" db:"reference_id" validate:"required"`
+	ParentID       *uuid.UUID `json:"parent_id" db:"parent_id"`
+	RefundedAmount int64      `json:"refunded_amount" db:"refunded_amount" validate:"gte=0"`
+	Reason         string     `json:"reason,omitempty" db:"reason"`
+	Operator       string     `json:"operator,omitempty" db:"operator"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at" validate:"required"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at" validate:"required"`
+}
+
+// RefundableAmount is what is left to refund, only a successful deposit or
+// withdrawal can be given back.
+func (t Transaction) RefundableAmount() int64 {
+	if t.Status != TransactionStatus.Success {
+		return 0
+	}
+	if t.Type != TransactionType.Deposit && t.Type != TransactionType.Withdrawal {
+		return 0
+	}
+
+	return t.Amount - t.RefundedAmount
+}
+
+func (t Transaction) CanTransitionTo(status string) bool {
+	for _, from := range TransactionStatusesBefore(status) {
+		if from == t.Status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SignedAmount is what the transaction adds to the wallet balance once it
+// succeeded, negative for a debit.
+func (t Transaction) SignedAmount() int64 {
+	for _, credit := range CreditTransactionTypes {
+		if t.Type == credit {
+			return t.Amount
+		}
+	}
+
+	return -t.Amount
+}
+
+// GrossAmount is what the wallet is charged, the amount plus its fee.
+func (t Transaction) GrossAmount() int64 {
+	return t.Amount + t.Fee
 }
@@ -18,9 +18,19 @@ type Wallet struct {
 	ID         uuid.UUID  `json:"id" db:"id"`
 	OwnedBy    uuid.UUID  `json:"user_id" db:"user_id" validate:"required"`
 	Balance    int64      `json:"balance" db:"balance" validate:"gte=0"`
+	Reserved   int64      `json:"reserved" db:"reserved" validate:"gte=0"`
 	Status     string     `json:"status" db:"status" validate:"required,enumWalletStatus"`
 	EnabledAt  *time.Time `json:"enabled_at" db:"enabled_at"`
 	DisabledAt *time.Time `json:"disabled_at" db:"disabled_at"`
 	CreatedAt  time.Time  `json:"created_at" db:"created_at" validate:"required"`
 	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at" validate:"required"`
+	// Version goes up with every status change of the wallet, balance moves
+	// leave it alone. A status update only lands on the version it was read
+	// with.
+	Version int64 `json:"version" db:"version"`
+}
+
+// AvailableBalance is the part of the balance not reserved by holds.
+func (w Wallet) AvailableBalance() int64 {
+	return w.Balance - w.Reserved
 }
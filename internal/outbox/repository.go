@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+const (
+	qCreate = `
+		INSERT INTO outbox_events (
+			id, type, wallet_id, payload, status, attempts, next_attempt_at, created_at, updated_at
+		) VALUES(
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		)
+	`
+
+	qClaim = `
+		UPDATE outbox_events SET
+			next_attempt_at = $2, updated_at = $1
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE status = 'pending'
+			AND next_attempt_at <= $1
+			ORDER BY next_attempt_at ASC, created_at ASC
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING 
+			id, type, wallet_id, payload, status, attempts, next_attempt_at, last_error, delivered_at, created_at, updated_at
+	`
+
+	qUpdate = `
+		UPDATE outbox_events SET
+			status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5, updated_at = $6
+		WHERE id = $7
+	`
+)
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *outboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (o *outboxRepository) CreateTx(ctx context.Context, tx *sql.Tx, event model.Event) error {
+	stmt, err := tx.Prepare(qCreate)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(
+		ctx,
+		event.ID,
+		event.Type,
+		event.WalletID,
+		[]byte(event.Payload),
+		event.Status,
+		event.Attempts,
+		event.NextAttemptAt,
+		event.CreatedAt,
+		event.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (o *outboxRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Event, error) {
+	rows, err := o.db.QueryContext(ctx, qClaim, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []model.Event{}
+	for rows.Next() {
+		event := model.Event{}
+		var payload []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.WalletID,
+			&payload,
+			&event.Status,
+			&event.Attempts,
+			&event.NextAttemptAt,
+			&event.LastError,
+			&event.DeliveredAt,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (o *outboxRepository) Update(ctx context.Context, event model.Event) error {
+	stmt, err := o.db.Prepare(qUpdate)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(
+		ctx,
+		event.Status,
+		event.Attempts,
+		event.NextAttemptAt,
+		event.LastError,
+		event.DeliveredAt,
+		event.UpdatedAt,
+		event.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	t.Run("CreateTx", TestCreateTx)
+	t.Run("Claim", TestClaim)
+	t.Run("Update", TestUpdate)
+}
+
+func newEvent() model.Event {
+	timestamp := time.Now()
+	return model.Event{
+		ID:            uuid.New(),
+		Type:          model.EventType.WalletEnabled,
+		WalletID:      uuid.New(),
+		Payload:       json.RawMessage(`{"status":"enabled"}`),
+		Status:        model.EventStatus.Pending,
+		NextAttemptAt: timestamp,
+		CreatedAt:     timestamp,
+		UpdatedAt:     timestamp,
+	}
+}
+
+func TestCreateTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		event := newEvent()
+		mock.
+			ExpectPrepare(qCreate).
+			ExpectExec().
+			WithArgs(
+				event.ID,
+				event.Type,
+				event.WalletID,
+				[]byte(event.Payload),
+				event.Status,
+				event.Attempts,
+				event.NextAttemptAt,
+				event.CreatedAt,
+				event.UpdatedAt,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &outboxRepository{db: db}
+		err = repo.CreateTx(context.Background(), tx, event)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Prepare", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		errExpected := errors.New("err")
+		mock.ExpectPrepare(qCreate).WillReturnError(errExpected)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &outboxRepository{db: db}
+		err = repo.CreateTx(context.Background(), tx, newEvent())
+		assert.ErrorIs(t, err, errExpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestClaim(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		now := time.Now()
+		event := newEvent()
+		event.NextAttemptAt = now.Add(time.Minute)
+		mock.ExpectQuery(qClaim).
+			WithArgs(now, now.Add(time.Minute), 10).
+			WillReturnRows(sqlmock.NewRows([]string{
+				"id", "type", "wallet_id", "payload", "status", "attempts", "next_attempt_at",
+				"last_error", "delivered_at", "created_at", "updated_at",
+			}).AddRow(
+				event.ID,
+				event.Type,
+				event.WalletID,
+				[]byte(event.Payload),
+				event.Status,
+				event.Attempts,
+				event.NextAttemptAt,
+				nil,
+				nil,
+				event.CreatedAt,
+				event.UpdatedAt,
+			))
+
+		repo := &outboxRepository{db: db}
+		events, err := repo.Claim(context.Background(), now, time.Minute, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Event{event}, events)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Query", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errExpected := errors.New("err")
+		mock.ExpectQuery(qClaim).WillReturnError(errExpected)
+
+		repo := &outboxRepository{db: db}
+		events, err := repo.Claim(context.Background(), time.Now(), time.Minute, 10)
+		assert.ErrorIs(t, err, errExpected)
+		assert.Nil(t, events)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		event := newEvent()
+		event.Status = model.EventStatus.Delivered
+		event.Attempts = 1
+		event.DeliveredAt = &event.UpdatedAt
+		mock.
+			ExpectPrepare(qUpdate).
+			ExpectExec().
+			WithArgs(
+				event.Status,
+				event.Attempts,
+				event.NextAttemptAt,
+				event.LastError,
+				event.DeliveredAt,
+				event.UpdatedAt,
+				event.ID,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := &outboxRepository{db: db}
+		err = repo.Update(context.Background(), event)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Execute", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errExpected := errors.New("err")
+		mock.
+			ExpectPrepare(qUpdate).
+			ExpectExec().
+			WillReturnError(errExpected)
+
+		repo := &outboxRepository{db: db}
+		err = repo.Update(context.Background(), newEvent())
+		assert.ErrorIs(t, err, errExpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,17 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type OutboxRepository interface {
+	CreateTx(ctx context.Context, tx *sql.Tx, event model.Event) error
+	// Claim returns up to limit pending events due at now and pushes their
+	// next attempt to now plus lease, so concurrent dispatchers skip them.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Event, error)
+	Update(ctx context.Context, event model.Event) error
+}
@@ -0,0 +1,95 @@
+package reconciliation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+const (
+	kindBalanceDrift = "balance_drift"
+	kindStuckPending = "stuck_pending"
+)
+
+var csvHeader = []string{
+	"kind",
+	"wallet_id",
+	"transaction_id",
+	"type",
+	"status",
+	"amount",
+	"balance",
+	"transaction_balance",
+	"ledger_balance",
+	"transaction_drift",
+	"ledger_drift",
+	"created_at",
+}
+
+func WriteJSON(w io.Writer, report model.ReconciliationReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteCSV writes one row per finding, the kind column tells balance drifts,
+// stuck pending transactions and orphans apart. Columns a kind does not use
+// are left empty, a clean report is the header alone.
+func WriteCSV(w io.Writer, report model.ReconciliationReport) error {
+	writer := csv.NewWriter(w)
+	rows := [][]string{csvHeader}
+
+	for _, b := range report.Drifts {
+		rows = append(rows, []string{
+			kindBalanceDrift,
+			b.WalletID.String(),
+			"", "", "", "",
+			formatInt(b.Balance),
+			formatInt(b.TransactionBalance),
+			formatInt(b.LedgerBalance),
+			formatInt(b.TransactionDrift),
+			formatInt(b.LedgerDrift),
+			"",
+		})
+	}
+
+	for _, t := range report.StuckPending {
+		rows = append(rows, []string{
+			kindStuckPending,
+			t.WalletID.String(),
+			t.ID.String(),
+			t.Type,
+			t.Status,
+			formatInt(t.Amount),
+			"", "", "", "", "",
+			formatTime(t.CreatedAt),
+		})
+	}
+
+	for _, o := range report.Orphans {
+		rows = append(rows, []string{
+			o.Kind,
+			o.WalletID.String(),
+			o.TransactionID.String(),
+			o.Type,
+			o.Status,
+			formatInt(o.Amount),
+			"", "", "", "", "",
+			formatTime(o.CreatedAt),
+		})
+	}
+
+	return writer.WriteAll(rows)
+}
+
+func formatInt(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
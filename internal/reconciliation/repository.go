@@ -0,0 +1,225 @@
+package reconciliation
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/lib/pq"
+)
+
+const (
+	qBalances = `
+		SELECT
+			w.id,
+			w.balance,
+			COALESCE(t.balance, 0),
+			COALESCE(p.balance, 0)
+		FROM wallets w
+		LEFT JOIN (
+			SELECT
+				wallet_id,
+				SUM(CASE WHEN type = ANY($1) THEN amount ELSE -amount END) AS balance
+			FROM transactions
+			WHERE status = $2
+			AND is_active = true
+			GROUP BY wallet_id
+		) t ON t.wallet_id = w.id
+		LEFT JOIN (
+			SELECT
+				wallet_id,
+				SUM(amount) AS balance
+			FROM postings
+			WHERE account = $3
+			GROUP BY wallet_id
+		) p ON p.wallet_id = w.id
+		ORDER BY w.id
+	`
+
+	qPending = `
+		SELECT
+			id,
+			wallet_id,
+			type,
+			status,
+			reference_id,
+			amount,
+			fee,
+			created_at,
+			updated_at
+		FROM transactions
+		WHERE status = $1
+		AND created_at < $2
+		AND is_active = true
+		ORDER BY created_at, id
+	`
+
+	qMissingJournalEntries = `
+		SELECT
+			t.id,
+			t.wallet_id,
+			t.type,
+			t.status,
+			t.amount,
+			t.created_at
+		FROM transactions t
+		LEFT JOIN journal_entries j ON j.transaction_id = t.id
+		WHERE t.status = $1
+		AND t.is_active = true
+		AND j.id IS NULL
+		ORDER BY t.created_at, t.id
+	`
+
+	qUnsettledJournalEntries = `
+		SELECT
+			t.id,
+			t.wallet_id,
+			t.type,
+			t.status,
+			t.amount,
+			t.created_at
+		FROM journal_entries j
+		JOIN transactions t ON t.id = j.transaction_id
+		WHERE t.status <> $1
+		ORDER BY t.created_at, t.id
+	`
+)
+
+type reconciliationRepository struct {
+	db *sql.DB
+}
+
+func NewReconciliationRepository(db *sql.DB) *reconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+func (r *reconciliationRepository) BalancesTx(ctx context.Context, tx *sql.Tx) ([]model.WalletBalance, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		qBalances,
+		pq.Array(model.CreditTransactionTypes),
+		model.TransactionStatus.Success,
+		model.LedgerAccount.Wallet,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []model.WalletBalance{}
+	for rows.Next() {
+		b := model.WalletBalance{}
+		err := rows.Scan(
+			&b.WalletID,
+			&b.Balance,
+			&b.TransactionBalance,
+			&b.LedgerBalance,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		balances = append(balances, b)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func (r *reconciliationRepository) PendingTx(ctx context.Context, tx *sql.Tx, before time.Time) ([]model.Transaction, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		qPending,
+		model.TransactionStatus.Pending,
+		before,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []model.Transaction{}
+	for rows.Next() {
+		t := model.Transaction{}
+		err := rows.Scan(
+			&t.ID,
+			&t.WalletID,
+			&t.Type,
+			&t.Status,
+			&t.ReferenceID,
+			&t.Amount,
+			&t.Fee,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		transactions = append(transactions, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// OrphansTx lists successful transactions without a journal entry, then
+// journal entries of transactions that did not succeed.
+func (r *reconciliationRepository) OrphansTx(ctx context.Context, tx *sql.Tx) ([]model.Orphan, error) {
+	orphans := []model.Orphan{}
+	for _, check := range []struct {
+		kind  string
+		query string
+	}{
+		{kind: model.OrphanKind.MissingJournalEntry, query: qMissingJournalEntries},
+		{kind: model.OrphanKind.UnsettledJournalEntry, query: qUnsettledJournalEntries},
+	} {
+		found, err := r.orphansTx(ctx, tx, check.kind, check.query)
+		if err != nil {
+			return nil, err
+		}
+
+		orphans = append(orphans, found...)
+	}
+
+	return orphans, nil
+}
+
+func (r *reconciliationRepository) orphansTx(ctx context.Context, tx *sql.Tx, kind string, query string) ([]model.Orphan, error) {
+	rows, err := tx.QueryContext(ctx, query, model.TransactionStatus.Success)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orphans := []model.Orphan{}
+	for rows.Next() {
+		o := model.Orphan{Kind: kind}
+		err := rows.Scan(
+			&o.TransactionID,
+			&o.WalletID,
+			&o.Type,
+			&o.Status,
+			&o.Amount,
+			&o.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		orphans = append(orphans, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orphans, nil
+}
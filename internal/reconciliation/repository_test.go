@@ -0,0 +1,190 @@
+package reconciliation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciliationRepository(t *testing.T) {
+	t.Run("Balances", TestBalances)
+	t.Run("Pending", TestPending)
+	t.Run("Orphans", TestOrphans)
+}
+
+func TestBalances(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		balance := model.WalletBalance{
+			WalletID:           uuid.New(),
+			Balance:            1000,
+			TransactionBalance: 900,
+			LedgerBalance:      1000,
+		}
+		mock.ExpectBegin()
+		mock.ExpectQuery(qBalances).WithArgs(
+			pq.Array(model.CreditTransactionTypes),
+			model.TransactionStatus.Success,
+			model.LedgerAccount.Wallet,
+		).WillReturnRows(
+			sqlmock.NewRows([]string{"id", "balance", "transaction_balance", "ledger_balance"}).
+				AddRow(balance.WalletID, balance.Balance, balance.TransactionBalance, balance.LedgerBalance),
+		)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &reconciliationRepository{db: db}
+		balances, err := repo.BalancesTx(context.Background(), tx)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.WalletBalance{balance}, balances)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Query", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(qBalances).WillReturnError(errors.New("failed"))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &reconciliationRepository{db: db}
+		_, err = repo.BalancesTx(context.Background(), tx)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPending(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	timestamp := time.Now().Add(-time.Hour)
+	pending := model.Transaction{
+		ID:          uuid.New(),
+		WalletID:    uuid.New(),
+		Type:        model.TransactionType.Deposit,
+		Status:      model.TransactionStatus.Pending,
+		ReferenceID: "abc",
+		Amount:      10000,
+		CreatedAt:   timestamp,
+		UpdatedAt:   timestamp,
+	}
+	before := time.Now().Add(-time.Minute)
+	mock.ExpectBegin()
+	mock.ExpectQuery(qPending).WithArgs(
+		model.TransactionStatus.Pending,
+		before,
+	).WillReturnRows(
+		sqlmock.NewRows([]string{
+			"id",
+			"wallet_id",
+			"type",
+			"status",
+			"reference_id",
+			"amount",
+			"fee",
+			"created_at",
+			"updated_at",
+		}).AddRow(
+			pending.ID,
+			pending.WalletID,
+			pending.Type,
+			pending.Status,
+			pending.ReferenceID,
+			pending.Amount,
+			pending.Fee,
+			pending.CreatedAt,
+			pending.UpdatedAt,
+		),
+	)
+
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	repo := &reconciliationRepository{db: db}
+	transactions, err := repo.PendingTx(context.Background(), tx, before)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.Transaction{pending}, transactions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOrphans(t *testing.T) {
+	columns := []string{"id", "wallet_id", "type", "status", "amount", "created_at"}
+	newOrphan := func(kind string, status string) model.Orphan {
+		return model.Orphan{
+			Kind:          kind,
+			TransactionID: uuid.New(),
+			WalletID:      uuid.New(),
+			Type:          model.TransactionType.Withdrawal,
+			Status:        status,
+			Amount:        500,
+			CreatedAt:     time.Now(),
+		}
+	}
+	addRow := func(rows *sqlmock.Rows, o model.Orphan) *sqlmock.Rows {
+		return rows.AddRow(o.TransactionID, o.WalletID, o.Type, o.Status, o.Amount, o.CreatedAt)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		missing := newOrphan(model.OrphanKind.MissingJournalEntry, model.TransactionStatus.Success)
+		unsettled := newOrphan(model.OrphanKind.UnsettledJournalEntry, model.TransactionStatus.Failed)
+		mock.ExpectBegin()
+		mock.ExpectQuery(qMissingJournalEntries).
+			WithArgs(model.TransactionStatus.Success).
+			WillReturnRows(addRow(sqlmock.NewRows(columns), missing))
+		mock.ExpectQuery(qUnsettledJournalEntries).
+			WithArgs(model.TransactionStatus.Success).
+			WillReturnRows(addRow(sqlmock.NewRows(columns), unsettled))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &reconciliationRepository{db: db}
+		orphans, err := repo.OrphansTx(context.Background(), tx)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Orphan{missing, unsettled}, orphans)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Query", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(qMissingJournalEntries).
+			WithArgs(model.TransactionStatus.Success).
+			WillReturnRows(sqlmock.NewRows(columns))
+		mock.ExpectQuery(qUnsettledJournalEntries).
+			WithArgs(model.TransactionStatus.Success).
+			WillReturnError(errors.New("failed"))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &reconciliationRepository{db: db}
+		_, err = repo.OrphansTx(context.Background(), tx)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,86 @@
+package reconciliation
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+const defaultPendingAge = 5 * time.Minute
+
+type Config struct {
+	TxRepository             internal.TxRepository
+	ReconciliationRepository internal.ReconciliationRepository
+
+	// PendingAge is how long a transaction may stay pending before it is
+	// reported as stuck.
+	PendingAge time.Duration
+}
+
+type reconciliationService struct {
+	cfg Config
+}
+
+func NewReconciliationService(cfg Config) *reconciliationService {
+	if cfg.PendingAge <= 0 {
+		cfg.PendingAge = defaultPendingAge
+	}
+
+	return &reconciliationService{cfg: cfg}
+}
+
+// Reconcile checks every wallet balance against its successful transactions
+// and its ledger postings, and looks for transactions left pending and for
+// transactions whose ledger entry does not match their status. Every check
+// reads the same read only snapshot, so writes landing in between can not
+// show up as drifts.
+func (r *reconciliationService) Reconcile(ctx context.Context, now time.Time) (model.ReconciliationReport, error) {
+	report := model.ReconciliationReport{
+		CheckedAt:    now,
+		Drifts:       []model.WalletBalance{},
+		StuckPending: []model.Transaction{},
+		Orphans:      []model.Orphan{},
+	}
+
+	var balances []model.WalletBalance
+	var pending []model.Transaction
+	var orphans []model.Orphan
+	err := r.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		balances, err = r.cfg.ReconciliationRepository.BalancesTx(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		pending, err = r.cfg.ReconciliationRepository.PendingTx(ctx, tx, now.Add(-r.cfg.PendingAge))
+		if err != nil {
+			return err
+		}
+
+		orphans, err = r.cfg.ReconciliationRepository.OrphansTx(ctx, tx)
+		return err
+	}, internal.WithIsolation(sql.LevelRepeatableRead), internal.WithReadOnly())
+	if err != nil {
+		return model.ReconciliationReport{}, err
+	}
+
+	report.Wallets = len(balances)
+	for _, b := range balances {
+		if b.IsConsistent() {
+			continue
+		}
+
+		b.TransactionDrift = b.Balance - b.TransactionBalance
+		b.LedgerDrift = b.Balance - b.LedgerBalance
+		report.Drifts = append(report.Drifts, b)
+	}
+
+	report.StuckPending = append(report.StuckPending, pending...)
+
+	report.Orphans = append(report.Orphans, orphans...)
+
+	return report, nil
+}
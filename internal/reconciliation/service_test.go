@@ -0,0 +1,150 @@
+package reconciliation
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	mock "github.com/hokdre/mini-ewallet/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciliationService(t *testing.T) {
+	t.Run("Reconcile", TestReconcile)
+	t.Run("WriteCSV", TestWriteCSV)
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	newTxRepo := func(ctrl *gomock.Controller) *mock.MockTxRepository {
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, opts ...internal.TxOption) error {
+			assert.True(t, internal.NewTxOptions(opts...).ReadOnly)
+			return fn(ctx, nil)
+		}).Times(1)
+		return txRepo
+	}
+
+	t.Run("Clean", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mock.NewMockReconciliationRepository(ctrl)
+		s := NewReconciliationService(Config{
+			TxRepository:             newTxRepo(ctrl),
+			ReconciliationRepository: repo,
+		})
+
+		repo.EXPECT().BalancesTx(gomock.Any(), gomock.Any()).Return([]model.WalletBalance{
+			{WalletID: uuid.New(), Balance: 100, TransactionBalance: 100, LedgerBalance: 100},
+		}, nil)
+		repo.EXPECT().PendingTx(gomock.Any(), gomock.Any(), now.Add(-defaultPendingAge)).Return(nil, nil)
+		repo.EXPECT().OrphansTx(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		report, err := s.Reconcile(context.Background(), now)
+		assert.NoError(t, err)
+		assert.True(t, report.IsClean())
+		assert.Equal(t, 1, report.Wallets)
+		assert.Equal(t, now, report.CheckedAt)
+		assert.NotNil(t, report.Orphans)
+	})
+
+	t.Run("Findings", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mock.NewMockReconciliationRepository(ctrl)
+		s := NewReconciliationService(Config{
+			TxRepository:             newTxRepo(ctrl),
+			ReconciliationRepository: repo,
+			PendingAge:               time.Hour,
+		})
+
+		consistent := model.WalletBalance{WalletID: uuid.New(), Balance: 100, TransactionBalance: 100, LedgerBalance: 100}
+		drifted := model.WalletBalance{WalletID: uuid.New(), Balance: 150, TransactionBalance: 100, LedgerBalance: 150}
+		ledgerOnly := model.WalletBalance{WalletID: uuid.New(), Balance: 100, TransactionBalance: 100, LedgerBalance: 90}
+		stuck := model.Transaction{ID: uuid.New(), Status: model.TransactionStatus.Pending}
+		orphan := model.Orphan{Kind: model.OrphanKind.MissingJournalEntry, TransactionID: uuid.New()}
+
+		repo.EXPECT().BalancesTx(gomock.Any(), gomock.Any()).Return([]model.WalletBalance{consistent, drifted, ledgerOnly}, nil)
+		repo.EXPECT().PendingTx(gomock.Any(), gomock.Any(), now.Add(-time.Hour)).Return([]model.Transaction{stuck}, nil)
+		repo.EXPECT().OrphansTx(gomock.Any(), gomock.Any()).Return([]model.Orphan{orphan}, nil)
+
+		report, err := s.Reconcile(context.Background(), now)
+		assert.NoError(t, err)
+		assert.False(t, report.IsClean())
+		assert.Equal(t, 3, report.Wallets)
+
+		drifted.TransactionDrift = 50
+		ledgerOnly.LedgerDrift = 10
+		assert.Equal(t, []model.WalletBalance{drifted, ledgerOnly}, report.Drifts)
+		assert.Equal(t, []model.Transaction{stuck}, report.StuckPending)
+		assert.Equal(t, []model.Orphan{orphan}, report.Orphans)
+	})
+
+	t.Run("Failed Balances", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		repo := mock.NewMockReconciliationRepository(ctrl)
+		s := NewReconciliationService(Config{
+			TxRepository:             newTxRepo(ctrl),
+			ReconciliationRepository: repo,
+		})
+
+		repo.EXPECT().BalancesTx(gomock.Any(), gomock.Any()).Return(nil, errors.New("failed"))
+
+		_, err := s.Reconcile(context.Background(), now)
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteCSV(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	report := model.ReconciliationReport{
+		Drifts: []model.WalletBalance{
+			{WalletID: uuid.New(), Balance: 150, TransactionBalance: 100, LedgerBalance: 120, TransactionDrift: 50, LedgerDrift: 30},
+		},
+		StuckPending: []model.Transaction{
+			{
+				ID:        uuid.New(),
+				WalletID:  uuid.New(),
+				Type:      model.TransactionType.Deposit,
+				Status:    model.TransactionStatus.Pending,
+				Amount:    700,
+				CreatedAt: createdAt,
+			},
+		},
+		Orphans: []model.Orphan{
+			{
+				Kind:          model.OrphanKind.UnsettledJournalEntry,
+				TransactionID: uuid.New(),
+				WalletID:      uuid.New(),
+				Type:          model.TransactionType.Withdrawal,
+				Status:        model.TransactionStatus.Failed,
+				Amount:        300,
+				CreatedAt:     createdAt,
+			},
+		},
+	}
+
+	out := bytes.Buffer{}
+	assert.NoError(t, WriteCSV(&out, report))
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		csvHeader,
+		{"balance_drift", report.Drifts[0].WalletID.String(), "", "", "", "", "150", "100", "120", "50", "30", ""},
+		{
+			"stuck_pending", report.StuckPending[0].WalletID.String(), report.StuckPending[0].ID.String(),
+			"deposit", "pending", "700", "", "", "", "", "", "2024-05-01T10:00:00Z",
+		},
+		{
+			"unsettled_journal_entry", report.Orphans[0].WalletID.String(), report.Orphans[0].TransactionID.String(),
+			"withdrawal", "failed", "300", "", "", "", "", "", "2024-05-01T10:00:00Z",
+		},
+	}, rows)
+}
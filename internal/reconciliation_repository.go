@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// ReconciliationRepository reads the storage as a whole to cross check it,
+// the checks run in one read only transaction so they see the same snapshot.
+type ReconciliationRepository interface {
+	// BalancesTx recomputes the balance of every wallet, the drifts are left unset.
+	BalancesTx(ctx context.Context, tx *sql.Tx) ([]model.WalletBalance, error)
+	// PendingTx lists the transactions still pending that were created before.
+	PendingTx(ctx context.Context, tx *sql.Tx, before time.Time) ([]model.Transaction, error)
+	OrphansTx(ctx context.Context, tx *sql.Tx) ([]model.Orphan, error)
+}
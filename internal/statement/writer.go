@@ -0,0 +1,231 @@
+// Package statement renders wallet statements, every writer outputs lines
+// as they come so a statement is never built up in memory.
+package statement
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/pdf"
+)
+
+var ErrUnknownFormat = errors.New("unknown statement format")
+
+var Format = struct {
+	CSV  string
+	JSON string
+	PDF  string
+}{
+	CSV:  "csv",
+	JSON: "json",
+	PDF:  "pdf",
+}
+
+var contentTypes = map[string]string{
+	Format.CSV:  "text/csv",
+	Format.JSON: "application/json",
+	Format.PDF:  "application/pdf",
+}
+
+// ContentType is the media type of format, empty when the format is unknown.
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+func NewWriter(format string, w io.Writer) (internal.StatementWriter, error) {
+	switch format {
+	case Format.CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case Format.JSON:
+		return &jsonWriter{w: w}, nil
+	case Format.PDF:
+		return &pdfWriter{w: pdf.NewWriter(w)}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// csvWriter writes a single table, the opening and closing balances are rows
+// of their own dated at the start and the end of the period.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Begin(statement model.Statement) error {
+	return c.write(
+		[]string{"transacted_at", "transaction_id", "type", "reference_id", "amount", "balance"},
+		[]string{formatTime(statement.From), "", "opening_balance", "", "", formatInt(statement.OpeningBalance)},
+	)
+}
+
+func (c *csvWriter) Line(line model.StatementLine) error {
+	t := line.Transaction
+	return c.write([]string{
+		formatTime(*t.TransactedAt),
+		t.ID.String(),
+		t.Type,
+		t.ReferenceID,
+		formatInt(t.SignedAmount()),
+		formatInt(line.Balance),
+	})
+}
+
+func (c *csvWriter) End(statement model.Statement) error {
+	return c.write([]string{formatTime(statement.To), "", "closing_balance", "", "", formatInt(statement.ClosingBalance)})
+}
+
+func (c *csvWriter) write(records ...[]string) error {
+	for _, record := range records {
+		if err := c.w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter writes the same envelope as util.SendSuccess, the transactions
+// array is written one element at a time.
+type jsonWriter struct {
+	w     io.Writer
+	lines int
+}
+
+type jsonLine struct {
+	ID           uuid.UUID  `json:"id"`
+	TransactedAt *time.Time `json:"transacted_at"`
+	Type         string     `json:"type"`
+	ReferenceID  string     `json:"reference_id"`
+	Reason       string     `json:"reason,omitempty"`
+	Amount       int64      `json:"amount"`
+	Balance      int64      `json:"balance"`
+}
+
+func (j *jsonWriter) Begin(statement model.Statement) error {
+	header, err := json.Marshal(struct {
+		WalletID       string    `json:"wallet_id"`
+		From           time.Time `json:"from"`
+		To             time.Time `json:"to"`
+		GeneratedAt    time.Time `json:"generated_at"`
+		OpeningBalance int64     `json:"opening_balance"`
+	}{
+		WalletID:       statement.WalletID.String(),
+		From:           statement.From,
+		To:             statement.To,
+		GeneratedAt:    statement.GeneratedAt,
+		OpeningBalance: statement.OpeningBalance,
+	})
+	if err != nil {
+		return err
+	}
+
+	// leave the data object open for the transactions
+	_, err = fmt.Fprintf(j.w, `{"message":"Success","data":%s,"transactions":[`, header[:len(header)-1])
+	return err
+}
+
+func (j *jsonWriter) Line(line model.StatementLine) error {
+	t := line.Transaction
+	encoded, err := json.Marshal(jsonLine{
+		ID:           t.ID,
+		TransactedAt: t.TransactedAt,
+		Type:         t.Type,
+		ReferenceID:  t.ReferenceID,
+		Reason:       t.Reason,
+		Amount:       t.SignedAmount(),
+		Balance:      line.Balance,
+	})
+	if err != nil {
+		return err
+	}
+
+	if j.lines > 0 {
+		encoded = append([]byte(","), encoded...)
+	}
+	j.lines++
+
+	_, err = j.w.Write(encoded)
+	return err
+}
+
+func (j *jsonWriter) End(statement model.Statement) error {
+	_, err := fmt.Fprintf(j.w, `],"closing_balance":%d}}`+"\n", statement.ClosingBalance)
+	return err
+}
+
+const pdfLineFormat = "%-16s  %-17s  %-26s  %14s  %14s"
+
+// pdfWriter lays the statement out as a fixed width table.
+type pdfWriter struct {
+	w *pdf.Writer
+}
+
+func (p *pdfWriter) Begin(statement model.Statement) error {
+	return p.lines(
+		"Wallet Statement",
+		"",
+		"Wallet     "+statement.WalletID.String(),
+		"Period     "+formatDate(statement.From)+" - "+formatDate(statement.To)+" UTC",
+		"Generated  "+formatDate(statement.GeneratedAt)+" UTC",
+		"",
+		fmt.Sprintf(pdfLineFormat, "TRANSACTED AT", "TYPE", "REFERENCE", "AMOUNT", "BALANCE"),
+		fmt.Sprintf(pdfLineFormat, formatDate(statement.From), "opening balance", "", "", formatInt(statement.OpeningBalance)),
+	)
+}
+
+func (p *pdfWriter) Line(line model.StatementLine) error {
+	t := line.Transaction
+	reference := t.ReferenceID
+	if len(reference) > 26 {
+		reference = reference[:25] + "~"
+	}
+
+	return p.lines(fmt.Sprintf(
+		pdfLineFormat,
+		formatDate(*t.TransactedAt),
+		t.Type,
+		reference,
+		formatInt(t.SignedAmount()),
+		formatInt(line.Balance),
+	))
+}
+
+func (p *pdfWriter) End(statement model.Statement) error {
+	err := p.lines(fmt.Sprintf(pdfLineFormat, formatDate(statement.To), "closing balance", "", "", formatInt(statement.ClosingBalance)))
+	if err != nil {
+		return err
+	}
+
+	return p.w.Close()
+}
+
+func (p *pdfWriter) lines(lines ...string) error {
+	for _, line := range lines {
+		if err := p.w.Line(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func formatInt(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04")
+}
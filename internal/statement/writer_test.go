@@ -0,0 +1,158 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	t.Run("CSV", TestCSVWriter)
+	t.Run("JSON", TestJSONWriter)
+	t.Run("PDF", TestPDFWriter)
+	t.Run("UnknownFormat", TestUnknownFormat)
+}
+
+func newStatement() (model.Statement, []model.StatementLine) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 31, 23, 59, 59, 0, time.UTC)
+	depositedAt := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	withdrawnAt := time.Date(2024, 5, 3, 11, 30, 0, 0, time.UTC)
+
+	statement := model.Statement{
+		WalletID:       uuid.New(),
+		From:           from,
+		To:             to,
+		OpeningBalance: 1000,
+		ClosingBalance: 1300,
+		GeneratedAt:    to,
+	}
+	lines := []model.StatementLine{
+		{
+			Transaction: model.Transaction{
+				ID:           uuid.New(),
+				Type:         model.TransactionType.Deposit,
+				TransactedAt: &depositedAt,
+				Amount:       500,
+				ReferenceID:  "deposit-1",
+			},
+			Balance: 1500,
+		},
+		{
+			Transaction: model.Transaction{
+				ID:           uuid.New(),
+				Type:         model.TransactionType.Withdrawal,
+				TransactedAt: &withdrawnAt,
+				Amount:       200,
+				ReferenceID:  "withdrawal-1",
+			},
+			Balance: 1300,
+		},
+	}
+
+	return statement, lines
+}
+
+func write(t *testing.T, writer internal.StatementWriter, statement model.Statement, lines []model.StatementLine) {
+	assert.NoError(t, writer.Begin(statement))
+	for _, line := range lines {
+		assert.NoError(t, writer.Line(line))
+	}
+	assert.NoError(t, writer.End(statement))
+}
+
+func TestCSVWriter(t *testing.T) {
+	statement, lines := newStatement()
+	out := bytes.Buffer{}
+	writer, err := NewWriter(Format.CSV, &out)
+	assert.NoError(t, err)
+	write(t, writer, statement, lines)
+
+	records, err := csv.NewReader(&out).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"transacted_at", "transaction_id", "type", "reference_id", "amount", "balance"},
+		{"2024-05-01T00:00:00Z", "", "opening_balance", "", "", "1000"},
+		{"2024-05-02T10:00:00Z", lines[0].Transaction.ID.String(), "deposit", "deposit-1", "500", "1500"},
+		{"2024-05-03T11:30:00Z", lines[1].Transaction.ID.String(), "withdrawal", "withdrawal-1", "-200", "1300"},
+		{"2024-05-31T23:59:59Z", "", "closing_balance", "", "", "1300"},
+	}, records)
+}
+
+func TestJSONWriter(t *testing.T) {
+	t.Run("Transactions", func(t *testing.T) {
+		statement, lines := newStatement()
+		out := bytes.Buffer{}
+		writer, err := NewWriter(Format.JSON, &out)
+		assert.NoError(t, err)
+		write(t, writer, statement, lines)
+
+		res := struct {
+			Message string `json:"message"`
+			Data    struct {
+				WalletID       uuid.UUID `json:"wallet_id"`
+				From           time.Time `json:"from"`
+				OpeningBalance int64     `json:"opening_balance"`
+				ClosingBalance int64     `json:"closing_balance"`
+				Transactions   []struct {
+					ID      uuid.UUID `json:"id"`
+					Amount  int64     `json:"amount"`
+					Balance int64     `json:"balance"`
+				} `json:"transactions"`
+			} `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &res))
+		assert.Equal(t, "Success", res.Message)
+		assert.Equal(t, statement.WalletID, res.Data.WalletID)
+		assert.True(t, statement.From.Equal(res.Data.From))
+		assert.Equal(t, int64(1000), res.Data.OpeningBalance)
+		assert.Equal(t, int64(1300), res.Data.ClosingBalance)
+		assert.Len(t, res.Data.Transactions, 2)
+		assert.Equal(t, lines[1].Transaction.ID, res.Data.Transactions[1].ID)
+		assert.Equal(t, int64(-200), res.Data.Transactions[1].Amount)
+		assert.Equal(t, int64(1300), res.Data.Transactions[1].Balance)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		statement, _ := newStatement()
+		out := bytes.Buffer{}
+		writer, err := NewWriter(Format.JSON, &out)
+		assert.NoError(t, err)
+		write(t, writer, statement, nil)
+
+		res := struct {
+			Data map[string]interface{} `json:"data"`
+		}{}
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &res))
+		assert.Equal(t, []interface{}{}, res.Data["transactions"])
+	})
+}
+
+func TestPDFWriter(t *testing.T) {
+	statement, lines := newStatement()
+	out := bytes.Buffer{}
+	writer, err := NewWriter(Format.PDF, &out)
+	assert.NoError(t, err)
+	write(t, writer, statement, lines)
+
+	document := out.String()
+	assert.Contains(t, document, "%PDF-1.4")
+	assert.Contains(t, document, "(Wallet     "+statement.WalletID.String()+") Tj")
+	assert.Contains(t, document, "2024-05-03 11:30  withdrawal         withdrawal-1                          -200            1300")
+	assert.Contains(t, document, "closing balance")
+	assert.Contains(t, document, "%%EOF")
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter("xlsx", &bytes.Buffer{})
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+	assert.Equal(t, "", ContentType("xlsx"))
+	assert.Equal(t, "application/pdf", ContentType(Format.PDF))
+}
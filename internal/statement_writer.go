@@ -0,0 +1,14 @@
+package internal
+
+import (
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// StatementWriter renders a statement as it is read, Begin gets the opening
+// balance, Line is called once per transaction oldest first and End gets the
+// closing balance.
+type StatementWriter interface {
+	Begin(statement model.Statement) error
+	Line(line model.StatementLine) error
+	End(statement model.Statement) error
+}
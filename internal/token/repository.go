@@ -0,0 +1,88 @@
+package token
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+const (
+	qRevoke = `
+		INSERT INTO revoked_tokens (
+			id, account_id, expires_at, revoked_at
+		) VALUES(
+			$1, $2, $3, $4
+		)
+		ON CONFLICT (id) DO NOTHING
+	`
+
+	qIsRevoked = `
+		SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE id = $1)
+	`
+
+	qDeleteExpired = `
+		DELETE FROM revoked_tokens WHERE expires_at < $1
+	`
+)
+
+type tokenRepository struct {
+	db *sql.DB
+}
+
+func NewTokenRepository(db *sql.DB) *tokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (t *tokenRepository) Revoke(ctx context.Context, token model.Token) (bool, error) {
+	stmt, err := t.db.PrepareContext(ctx, qRevoke)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(
+		ctx,
+		token.ID,
+		token.AccountID,
+		token.ExpiresAt,
+		time.Now(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (t *tokenRepository) IsRevoked(ctx context.Context, tokenID uuid.UUID) (bool, error) {
+	var revoked bool
+	err := t.db.QueryRowContext(ctx, qIsRevoked, tokenID).Scan(&revoked)
+	if err != nil {
+		return false, err
+	}
+
+	return revoked, nil
+}
+
+func (t *tokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	stmt, err := t.db.PrepareContext(ctx, qDeleteExpired)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,140 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenRepository(t *testing.T) {
+	t.Run("Revoke", TestRevoke)
+	t.Run("IsRevoked", TestIsRevoked)
+	t.Run("DeleteExpired", TestDeleteExpired)
+}
+
+func newToken() model.Token {
+	timestamp := time.Now()
+	return model.Token{
+		ID:        uuid.New(),
+		AccountID: uuid.New(),
+		Type:      model.TokenType.Refresh,
+		Scopes:    []string{model.TokenScope.WalletRead},
+		IssuedAt:  timestamp,
+		ExpiresAt: timestamp.Add(time.Hour),
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		token := newToken()
+		mock.
+			ExpectPrepare(qRevoke).
+			ExpectExec().
+			WithArgs(token.ID, token.AccountID, token.ExpiresAt, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		repo := &tokenRepository{db: db}
+		revoked, err := repo.Revoke(context.Background(), token)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Revoked", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.
+			ExpectPrepare(qRevoke).
+			ExpectExec().
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		repo := &tokenRepository{db: db}
+		revoked, err := repo.Revoke(context.Background(), newToken())
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Execute", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errExpected := errors.New("err")
+		mock.
+			ExpectPrepare(qRevoke).
+			ExpectExec().
+			WillReturnError(errExpected)
+
+		repo := &tokenRepository{db: db}
+		revoked, err := repo.Revoke(context.Background(), newToken())
+		assert.ErrorIs(t, err, errExpected)
+		assert.False(t, revoked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestIsRevoked(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		tokenID := uuid.New()
+		mock.ExpectQuery(qIsRevoked).
+			WithArgs(tokenID).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+		repo := &tokenRepository{db: db}
+		revoked, err := repo.IsRevoked(context.Background(), tokenID)
+		assert.NoError(t, err)
+		assert.True(t, revoked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Query", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errExpected := errors.New("err")
+		mock.ExpectQuery(qIsRevoked).WillReturnError(errExpected)
+
+		repo := &tokenRepository{db: db}
+		revoked, err := repo.IsRevoked(context.Background(), uuid.New())
+		assert.ErrorIs(t, err, errExpected)
+		assert.False(t, revoked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	defer db.Close()
+
+	now := time.Now()
+	mock.
+		ExpectPrepare(qDeleteExpired).
+		ExpectExec().
+		WithArgs(now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	repo := &tokenRepository{db: db}
+	deleted, err := repo.DeleteExpired(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
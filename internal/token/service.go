@@ -0,0 +1,184 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+)
+
+const (
+	defaultAccessTTL     = 15 * time.Minute
+	defaultRefreshTTL    = 30 * 24 * time.Hour
+	defaultSweepInterval = time.Hour
+)
+
+type Config struct {
+	Encryption      util.Encryption
+	TokenRepository internal.TokenRepository
+
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// Scopes are granted to every issued token, empty grants all scopes.
+	Scopes []string
+}
+
+type tokenService struct {
+	cfg Config
+}
+
+func NewTokenService(cfg Config) *tokenService {
+	if cfg.AccessTTL <= 0 {
+		cfg.AccessTTL = defaultAccessTTL
+	}
+	if cfg.RefreshTTL <= 0 {
+		cfg.RefreshTTL = defaultRefreshTTL
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{
+			model.TokenScope.WalletRead,
+			model.TokenScope.WalletWrite,
+		}
+	}
+
+	return &tokenService{cfg: cfg}
+}
+
+func (t *tokenService) Issue(ctx context.Context, accountID uuid.UUID) (model.TokenPair, error) {
+	return t.issue(accountID, t.cfg.Scopes, time.Now())
+}
+
+func (t *tokenService) issue(accountID uuid.UUID, scopes []string, now time.Time) (model.TokenPair, error) {
+	access := model.Token{
+		ID:        uuid.New(),
+		AccountID: accountID,
+		Type:      model.TokenType.Access,
+		Scopes:    scopes,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(t.cfg.AccessTTL),
+	}
+	accessToken, err := t.seal(access)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	refresh := access
+	refresh.ID = uuid.New()
+	refresh.Type = model.TokenType.Refresh
+	refresh.ExpiresAt = now.Add(t.cfg.RefreshTTL)
+	refreshToken, err := t.seal(refresh)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	return model.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  access.ExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
+
+func (t *tokenService) Refresh(ctx context.Context, refreshToken string) (model.TokenPair, error) {
+	token, err := t.Verify(ctx, refreshToken, model.TokenType.Refresh)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+
+	revoked, err := t.cfg.TokenRepository.Revoke(ctx, token)
+	if err != nil {
+		return model.TokenPair{}, err
+	}
+	if !revoked {
+		// lost the race against another refresh of the same token
+		return model.TokenPair{}, model.ErrTokenRevoked
+	}
+
+	return t.issue(token.AccountID, token.Scopes, time.Now())
+}
+
+func (t *tokenService) Verify(ctx context.Context, rawToken string, tokenType string) (model.Token, error) {
+	token, err := t.open(rawToken)
+	if err != nil {
+		return model.Token{}, err
+	}
+	if token.Type != tokenType {
+		return model.Token{}, model.ErrTokenInvalid
+	}
+	if token.IsExpired(time.Now()) {
+		return model.Token{}, model.ErrTokenExpired
+	}
+
+	revoked, err := t.cfg.TokenRepository.IsRevoked(ctx, token.ID)
+	if err != nil {
+		return model.Token{}, err
+	}
+	if revoked {
+		return model.Token{}, model.ErrTokenRevoked
+	}
+
+	return token, nil
+}
+
+func (t *tokenService) Revoke(ctx context.Context, rawToken string) error {
+	token, err := t.open(rawToken)
+	if err != nil {
+		return err
+	}
+	if token.IsExpired(time.Now()) {
+		return nil
+	}
+
+	_, err = t.cfg.TokenRepository.Revoke(ctx, token)
+	return err
+}
+
+// RunRevocationSweep prunes revocations of expired tokens every interval
+// until ctx is done.
+func (t *tokenService) RunRevocationSweep(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := t.cfg.TokenRepository.DeleteExpired(ctx, now); err != nil {
+				log.Printf("failed delete expired revocations : %s \n", err)
+			}
+		}
+	}
+}
+
+func (t *tokenService) seal(token model.Token) (string, error) {
+	claims, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	return t.cfg.Encryption.Encrypt(string(claims))
+}
+
+func (t *tokenService) open(rawToken string) (model.Token, error) {
+	claims, err := t.cfg.Encryption.Decrypt(rawToken)
+	if err != nil {
+		return model.Token{}, model.ErrTokenInvalid
+	}
+
+	token := model.Token{}
+	err = json.Unmarshal([]byte(claims), &token)
+	if err != nil || token.ID == uuid.Nil || token.AccountID == uuid.Nil {
+		return model.Token{}, model.ErrTokenInvalid
+	}
+
+	return token, nil
+}
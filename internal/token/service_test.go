@@ -0,0 +1,215 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	mock "github.com/hokdre/mini-ewallet/pkg/mocks"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenService(t *testing.T) {
+	t.Run("Issue", TestIssue)
+	t.Run("Verify", TestVerify)
+	t.Run("Refresh", TestRefresh)
+	t.Run("RevokeToken", TestRevokeToken)
+}
+
+func newService(t *testing.T, tokenRepo *mock.MockTokenRepository) *tokenService {
+	encryption, err := util.NewAesEncryption(util.AesKey{ID: "1", Secret: "1111222233334444"})
+	assert.NoError(t, err)
+
+	return NewTokenService(Config{
+		Encryption:      encryption,
+		TokenRepository: tokenRepo,
+		AccessTTL:       time.Minute,
+		RefreshTTL:      time.Hour,
+	})
+}
+
+func TestIssue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	accountID := uuid.New()
+	s := newService(t, mock.NewMockTokenRepository(ctrl))
+
+	tokens, err := s.Issue(context.Background(), accountID)
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), tokens.AccessExpiresAt, time.Second)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), tokens.RefreshExpiresAt, time.Second)
+
+	access, err := s.open(tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, accountID, access.AccountID)
+	assert.Equal(t, model.TokenType.Access, access.Type)
+	assert.True(t, access.HasScope(model.TokenScope.WalletRead))
+	assert.True(t, access.HasScope(model.TokenScope.WalletWrite))
+
+	refresh, err := s.open(tokens.RefreshToken)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TokenType.Refresh, refresh.Type)
+	assert.NotEqual(t, access.ID, refresh.ID)
+}
+
+func TestVerify(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		tokenRepo := mock.NewMockTokenRepository(ctrl)
+		tokenRepo.EXPECT().IsRevoked(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		s := newService(t, tokenRepo)
+
+		accountID := uuid.New()
+		tokens, err := s.Issue(context.Background(), accountID)
+		assert.NoError(t, err)
+
+		token, err := s.Verify(context.Background(), tokens.AccessToken, model.TokenType.Access)
+		assert.NoError(t, err)
+		assert.Equal(t, accountID, token.AccountID)
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		s := newService(t, mock.NewMockTokenRepository(ctrl))
+
+		tokens, err := s.Issue(context.Background(), uuid.New())
+		assert.NoError(t, err)
+
+		_, err = s.Verify(context.Background(), tokens.RefreshToken, model.TokenType.Access)
+		assert.ErrorIs(t, err, model.ErrTokenInvalid)
+		assert.ErrorIs(t, err, model.ErrLoginInfoUknown)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		s := newService(t, mock.NewMockTokenRepository(ctrl))
+
+		tokens, err := s.issue(uuid.New(), s.cfg.Scopes, time.Now().Add(-2*time.Minute))
+		assert.NoError(t, err)
+
+		_, err = s.Verify(context.Background(), tokens.AccessToken, model.TokenType.Access)
+		assert.ErrorIs(t, err, model.ErrTokenExpired)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		tokenRepo := mock.NewMockTokenRepository(ctrl)
+		tokenRepo.EXPECT().IsRevoked(gomock.Any(), gomock.Any()).Return(true, nil).Times(1)
+		s := newService(t, tokenRepo)
+
+		tokens, err := s.Issue(context.Background(), uuid.New())
+		assert.NoError(t, err)
+
+		_, err = s.Verify(context.Background(), tokens.AccessToken, model.TokenType.Access)
+		assert.ErrorIs(t, err, model.ErrTokenRevoked)
+	})
+
+	t.Run("legacy or garbage token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		s := newService(t, mock.NewMockTokenRepository(ctrl))
+
+		legacy, err := s.cfg.Encryption.Encrypt(uuid.New().String())
+		assert.NoError(t, err)
+
+		for _, raw := range []string{legacy, "garbage", ""} {
+			_, err = s.Verify(context.Background(), raw, model.TokenType.Access)
+			assert.ErrorIs(t, err, model.ErrTokenInvalid)
+		}
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	t.Run("Success rotates refresh token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		tokenRepo := mock.NewMockTokenRepository(ctrl)
+		s := newService(t, tokenRepo)
+
+		accountID := uuid.New()
+		tokens, err := s.Issue(context.Background(), accountID)
+		assert.NoError(t, err)
+		refresh, err := s.open(tokens.RefreshToken)
+		assert.NoError(t, err)
+
+		tokenRepo.EXPECT().IsRevoked(gomock.Any(), refresh.ID).Return(false, nil).Times(1)
+		tokenRepo.EXPECT().Revoke(gomock.Any(), refresh).Return(true, nil).Times(1)
+
+		refreshed, err := s.Refresh(context.Background(), tokens.RefreshToken)
+		assert.NoError(t, err)
+		assert.NotEqual(t, tokens.RefreshToken, refreshed.RefreshToken)
+
+		access, err := s.open(refreshed.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, accountID, access.AccountID)
+		assert.Equal(t, refresh.Scopes, access.Scopes)
+	})
+
+	t.Run("access token is not a refresh token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		s := newService(t, mock.NewMockTokenRepository(ctrl))
+
+		tokens, err := s.Issue(context.Background(), uuid.New())
+		assert.NoError(t, err)
+
+		_, err = s.Refresh(context.Background(), tokens.AccessToken)
+		assert.ErrorIs(t, err, model.ErrTokenInvalid)
+	})
+
+	t.Run("concurrent refresh", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		tokenRepo := mock.NewMockTokenRepository(ctrl)
+		tokenRepo.EXPECT().IsRevoked(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		tokenRepo.EXPECT().Revoke(gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+		s := newService(t, tokenRepo)
+
+		tokens, err := s.Issue(context.Background(), uuid.New())
+		assert.NoError(t, err)
+
+		refreshed, err := s.Refresh(context.Background(), tokens.RefreshToken)
+		assert.ErrorIs(t, err, model.ErrTokenRevoked)
+		assert.Equal(t, model.TokenPair{}, refreshed)
+	})
+}
+
+func TestRevokeToken(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		tokenRepo := mock.NewMockTokenRepository(ctrl)
+		tokenRepo.EXPECT().Revoke(gomock.Any(), gomock.Any()).Return(true, nil).Times(1)
+		s := newService(t, tokenRepo)
+
+		tokens, err := s.Issue(context.Background(), uuid.New())
+		assert.NoError(t, err)
+
+		err = s.Revoke(context.Background(), tokens.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired token needs no revocation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		s := newService(t, mock.NewMockTokenRepository(ctrl))
+
+		tokens, err := s.issue(uuid.New(), s.cfg.Scopes, time.Now().Add(-2*time.Hour))
+		assert.NoError(t, err)
+
+		err = s.Revoke(context.Background(), tokens.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("failed revoke", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		errExpected := errors.New("err")
+		tokenRepo := mock.NewMockTokenRepository(ctrl)
+		tokenRepo.EXPECT().Revoke(gomock.Any(), gomock.Any()).Return(false, errExpected).Times(1)
+		s := newService(t, tokenRepo)
+
+		tokens, err := s.Issue(context.Background(), uuid.New())
+		assert.NoError(t, err)
+
+		err = s.Revoke(context.Background(), tokens.RefreshToken)
+		assert.ErrorIs(t, err, errExpected)
+	})
+}
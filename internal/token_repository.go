@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type TokenRepository interface {
+	// Revoke adds the token to the revocation list and reports false when it
+	// was already there, so a refresh token can only be rotated once.
+	Revoke(ctx context.Context, token model.Token) (bool, error)
+	IsRevoked(ctx context.Context, tokenID uuid.UUID) (bool, error)
+	// DeleteExpired drops revocations of tokens that expired before now,
+	// those tokens are rejected on their expiry anyway.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
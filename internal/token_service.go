@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type TokenService interface {
+	Issue(ctx context.Context, accountID uuid.UUID) (model.TokenPair, error)
+	// Refresh trades a refresh token for a new pair, the refresh token is
+	// revoked so it can not be used twice.
+	Refresh(ctx context.Context, refreshToken string) (model.TokenPair, error)
+	// Verify opens a token of the given type and rejects it when expired or
+	// revoked.
+	Verify(ctx context.Context, token string, tokenType string) (model.Token, error)
+	Revoke(ctx context.Context, token string) error
+}
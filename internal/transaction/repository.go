@@ -3,6 +3,8 @@ package transaction
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
 	"github.com/hokdre/mini-ewallet/internal"
 	"github.com/hokdre/mini-ewallet/internal/model"
@@ -10,9 +12,7 @@ import (
 )
 
 const (
-	defaultOrderDirection = "DESC"
-	defaultOffset         = 0
-	defaultOrderColumn    = "created_at"
+	errCodeUniqueViolation = "23505"
 
 	qCreate = `INSERT INTO transactions(
 		id, 
@@ -21,14 +21,33 @@ const (
 		status, 
 		reference_id, 
 		amount, 
+		fee,
 		transacted_at, 
 		created_at, 
 		updated_at, 
 		deleted_at,
 		is_active
-	) VALUES($1,$2,$3,$4,$5,$6, null, $7,$8,null,true)`
+	) VALUES($1,$2,$3,$4,$5,$6,$7, null, $8,$9,null,true)`
 
-	qList = `
+	qCreateTx = `INSERT INTO transactions(
+		id, 
+		wallet_id, 
+		type, 
+		status, 
+		reference_id, 
+		amount, 
+		fee,
+		transacted_at, 
+		parent_id,
+		reason,
+		operator,
+		created_at, 
+		updated_at, 
+		deleted_at,
+		is_active
+	) VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,null,true)`
+
+	qListSelect = `
 	   SELECT 
 	   	id, 
 		wallet_id, 
@@ -36,14 +55,50 @@ const (
 		status, 
 		reference_id, 
 		amount, 
+		fee,
 		transacted_at, 
+		parent_id,
+		refunded_amount,
+		reason,
+		operator,
 		created_at, 
 		updated_at 
 	   FROM transactions
 	   WHERE (id = ANY($1) or $1 IS NULL)
 	   AND ( wallet_id = ANY($2) or $2 IS NULL)
+	   AND ( reference_id = ANY($3) or $3 IS NULL)
+	   AND ( type = ANY($4) or $4 IS NULL)
+	   AND ( status = ANY($5) or $5 IS NULL)
+	   AND ( amount >= $6 or $6 IS NULL)
+	   AND ( amount <= $7 or $7 IS NULL)
+	   AND ( transacted_at >= $8 or $8 IS NULL)
+	   AND ( transacted_at <= $9 or $9 IS NULL)
+	   AND ( created_at < $12 or $12 IS NULL)
+	   AND is_active = true`
+
+	qList = qListSelect + `
+	   AND ( (created_at, id) < ($10, $11) or $10 IS NULL)
+	   ORDER BY created_at DESC, id DESC
+	   LIMIT $13
+	`
+
+	qListAscending = qListSelect + `
+	   AND ( (created_at, id) > ($10, $11) or $10 IS NULL)
+	   ORDER BY created_at, id
+	   LIMIT $13
+	`
+
+	qSummarize = `
+	   SELECT 
+	   	COUNT(id),
+		COALESCE(SUM(amount), 0)
+	   FROM transactions
+	   WHERE ( wallet_id = ANY($1) or $1 IS NULL)
+	   AND ( type = ANY($2) or $2 IS NULL)
+	   AND ( status = ANY($3) or $3 IS NULL)
+	   AND ( transacted_at >= $4 or $4 IS NULL)
+	   AND ( transacted_at <= $5 or $5 IS NULL)
 	   AND is_active = true
-	   ORDER BY $3 DESC
 	`
 
 	qUpdate = `
@@ -55,6 +110,19 @@ const (
 		updated_at = $3
 	WHERE 
 		id = $4
+	AND status = ANY($5)
+	`
+
+	qAddRefunded = `
+	UPDATE 
+		transactions
+	SET 
+		refunded_amount = refunded_amount + $1,
+		updated_at = $2
+	WHERE 
+		id = $3
+	AND status = 'success'
+	AND refunded_amount + $1 <= amount
 	`
 )
 
@@ -67,16 +135,44 @@ func NewAccountRepo(db *sql.DB) *transactionRepository {
 }
 
 func (a *transactionRepository) List(ctx context.Context, filter internal.TransactionFilter) ([]model.Transaction, error) {
+	var afterCreatedAt *time.Time
+	var afterID *string
+	if filter.After != nil {
+		afterCreatedAt = &filter.After.CreatedAt
+		afterID = &filter.After.ID
+	}
+
+	var limit *int
+	if filter.Limit > 0 {
+		limit = &filter.Limit
+	}
+
+	query := qList
+	if filter.Ascending {
+		query = qListAscending
+	}
+
 	rows, err := a.db.QueryContext(
 		ctx,
-		qList,
+		query,
 		pq.Array(filter.IDs),
 		pq.Array(filter.WalletIDs),
-		defaultOrderColumn,
+		pq.Array(filter.ReferenceIDs),
+		pq.Array(filter.Types),
+		pq.Array(filter.Statuses),
+		filter.MinAmount,
+		filter.MaxAmount,
+		filter.TransactedFrom,
+		filter.TransactedTo,
+		afterCreatedAt,
+		afterID,
+		filter.CreatedBefore,
+		limit,
 	)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	transactions := []model.Transaction{}
 	for rows.Next() {
@@ -88,7 +184,12 @@ func (a *transactionRepository) List(ctx context.Context, filter internal.Transa
 			&t.Status,
 			&t.ReferenceID,
 			&t.Amount,
+			&t.Fee,
 			&t.TransactedAt,
+			&t.ParentID,
+			&t.RefundedAmount,
+			&t.Reason,
+			&t.Operator,
 			&t.CreatedAt,
 			&t.UpdatedAt,
 		)
@@ -99,9 +200,47 @@ func (a *transactionRepository) List(ctx context.Context, filter internal.Transa
 		transactions = append(transactions, t)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return transactions, nil
 }
 
+// Summarize counts and sums the transactions matching the wallet, type,
+// status and transacted_at filters, other filters are ignored.
+func (a *transactionRepository) Summarize(ctx context.Context, filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+	return scanSummary(a.db.QueryRowContext(ctx, qSummarize, summarizeArgs(filter)...))
+}
+
+// SummarizeTx is Summarize within tx, it sees the writes of tx.
+func (a *transactionRepository) SummarizeTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+	return scanSummary(tx.QueryRowContext(ctx, qSummarize, summarizeArgs(filter)...))
+}
+
+func summarizeArgs(filter internal.TransactionFilter) []interface{} {
+	return []interface{}{
+		pq.Array(filter.WalletIDs),
+		pq.Array(filter.Types),
+		pq.Array(filter.Statuses),
+		filter.TransactedFrom,
+		filter.TransactedTo,
+	}
+}
+
+func scanSummary(row *sql.Row) (internal.TransactionSummary, error) {
+	summary := internal.TransactionSummary{}
+	err := row.Scan(&summary.Count, &summary.Amount)
+	if err != nil {
+		return internal.TransactionSummary{}, err
+	}
+
+	return summary, nil
+}
+
 func (a *transactionRepository) Create(ctx context.Context, newAcc model.Transaction) (err error) {
 
 	stmt, err := a.db.Prepare(qCreate)
@@ -118,16 +257,51 @@ func (a *transactionRepository) Create(ctx context.Context, newAcc model.Transac
 		newAcc.Status,
 		newAcc.ReferenceID,
 		newAcc.Amount,
+		newAcc.Fee,
 		newAcc.CreatedAt,
 		newAcc.UpdatedAt,
 	)
+	if err != nil {
+		return translateError(err)
+	}
+
+	return nil
+}
+
+func (a *transactionRepository) CreateTx(ctx context.Context, tx *sql.Tx, newTransaction model.Transaction) (err error) {
+
+	stmt, err := tx.Prepare(qCreateTx)
 	if err != nil {
 		return err
 	}
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(
+		ctx,
+		newTransaction.ID,
+		newTransaction.WalletID,
+		newTransaction.Type,
+		newTransaction.Status,
+		newTransaction.ReferenceID,
+		newTransaction.Amount,
+		newTransaction.Fee,
+		newTransaction.TransactedAt,
+		newTransaction.ParentID,
+		newTransaction.Reason,
+		newTransaction.Operator,
+		newTransaction.CreatedAt,
+		newTransaction.UpdatedAt,
+	)
+	if err != nil {
+		return translateError(err)
+	}
 
 	return nil
 }
 
+// UpdateTx moves the transaction to its new status, ErrInvalidStatusTransition
+// is returned when the stored status may not move there, a final status is
+// never left.
 func (a *transactionRepository) UpdateTx(ctx context.Context, tx *sql.Tx, transaction model.Transaction) (err error) {
 
 	stmt, err := tx.Prepare(qUpdate)
@@ -136,16 +310,62 @@ func (a *transactionRepository) UpdateTx(ctx context.Context, tx *sql.Tx, transa
 	}
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(
+	result, err := stmt.ExecContext(
 		ctx,
 		transaction.Status,
 		transaction.TransactedAt,
 		transaction.UpdatedAt,
 		transaction.ID,
+		pq.Array(model.TransactionStatusesBefore(transaction.Status)),
 	)
 	if err != nil {
 		return err
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return model.ErrInvalidStatusTransition
+	}
+
 	return nil
 }
+
+// AddRefundedTx books amount as refunded on the transaction. Nothing is
+// updated when the transaction did not succeed or the amount goes past what
+// is left to refund.
+func (a *transactionRepository) AddRefundedTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	transaction model.Transaction,
+	amount int64) (int64, error) {
+
+	stmt, err := tx.Prepare(qAddRefunded)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	result, err := stmt.ExecContext(
+		ctx,
+		amount,
+		transaction.UpdatedAt,
+		transaction.ID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == errCodeUniqueViolation {
+		return model.ErrDuplicateReferenceID
+	}
+
+	return err
+}
@@ -18,8 +18,11 @@ import (
 
 func TestAccountRepository(t *testing.T) {
 	t.Run("Create", TestCreate)
+	t.Run("CreateTx", TestCreateTx)
 	t.Run("UpdateTx", TestUpdateTx)
 	t.Run("List", TestList)
+	t.Run("AddRefundedTx", TestAddRefundedTx)
+	t.Run("Summarize", TestSummarize)
 }
 
 func TestCreate(t *testing.T) {
@@ -49,6 +52,7 @@ func TestCreate(t *testing.T) {
 				newAcc.Status,
 				newAcc.ReferenceID,
 				newAcc.Amount,
+				newAcc.Fee,
 				newAcc.CreatedAt,
 				newAcc.UpdatedAt,
 			).
@@ -105,6 +109,7 @@ func TestCreate(t *testing.T) {
 				newAcc.Status,
 				newAcc.ReferenceID,
 				newAcc.Amount,
+				newAcc.Fee,
 				newAcc.CreatedAt,
 				newAcc.UpdatedAt,
 			).
@@ -115,6 +120,94 @@ func TestCreate(t *testing.T) {
 		assert.Error(t, errCreate, errExpect)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("Duplicate Reference", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		newAcc := model.Transaction{}
+		mock.
+			ExpectPrepare(qCreate).
+			ExpectExec().
+			WillReturnError(&pq.Error{Code: errCodeUniqueViolation})
+
+		repo := &transactionRepository{db: db}
+		errCreate := repo.Create(context.Background(), newAcc)
+		assert.ErrorIs(t, errCreate, model.ErrDuplicateReferenceID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCreateTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		timestamp := time.Now()
+		parentID := uuid.New()
+		newTransaction := model.Transaction{
+			ID:           uuid.New(),
+			WalletID:     uuid.New(),
+			ParentID:     &parentID,
+			Type:         model.TransactionType.TransferIn,
+			Status:       model.TransactionStatus.Success,
+			ReferenceID:  "abc",
+			Amount:       10000,
+			TransactedAt: &timestamp,
+			CreatedAt:    timestamp,
+			UpdatedAt:    timestamp,
+		}
+		mock.
+			ExpectPrepare(qCreateTx).
+			ExpectExec().
+			WithArgs(
+				newTransaction.ID,
+				newTransaction.WalletID,
+				newTransaction.Type,
+				newTransaction.Status,
+				newTransaction.ReferenceID,
+				newTransaction.Amount,
+				newTransaction.Fee,
+				newTransaction.TransactedAt,
+				newTransaction.ParentID,
+				newTransaction.Reason,
+				newTransaction.Operator,
+				newTransaction.CreatedAt,
+				newTransaction.UpdatedAt,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &transactionRepository{db: db}
+		errCreate := repo.CreateTx(context.Background(), tx, newTransaction)
+		assert.NoError(t, errCreate)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Prepare", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		errExpected := errors.New("err")
+		mock.
+			ExpectPrepare(qCreateTx).
+			WillReturnError(errExpected)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &transactionRepository{db: db}
+		errCreate := repo.CreateTx(context.Background(), tx, model.Transaction{})
+		assert.Error(t, errCreate, errExpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestUpdateTx(t *testing.T) {
@@ -129,7 +222,7 @@ func TestUpdateTx(t *testing.T) {
 			ID:          uuid.New(),
 			WalletID:    uuid.New(),
 			Type:        model.TransactionType.Deposit,
-			Status:      model.TransactionStatus.Pending,
+			Status:      model.TransactionStatus.Success,
 			ReferenceID: "abc",
 			Amount:      10000,
 			CreatedAt:   timestamp,
@@ -143,6 +236,7 @@ func TestUpdateTx(t *testing.T) {
 				newAcc.TransactedAt,
 				newAcc.UpdatedAt,
 				newAcc.ID,
+				pq.Array(model.TransactionStatusesBefore(newAcc.Status)),
 			).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -155,6 +249,38 @@ func TestUpdateTx(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("Final Status", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		newAcc := model.Transaction{
+			ID:        uuid.New(),
+			Status:    model.TransactionStatus.Failed,
+			UpdatedAt: time.Now(),
+		}
+		mock.
+			ExpectPrepare(qUpdate).
+			ExpectExec().
+			WithArgs(
+				newAcc.Status,
+				newAcc.TransactedAt,
+				newAcc.UpdatedAt,
+				newAcc.ID,
+				pq.Array([]string{model.TransactionStatus.Pending}),
+			).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &transactionRepository{db: db}
+		errUpdate := repo.UpdateTx(context.Background(), tx, newAcc)
+		assert.ErrorIs(t, errUpdate, model.ErrInvalidStatusTransition)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Failed Prepare", func(t *testing.T) {
 		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
 		assert.NoError(t, err)
@@ -192,6 +318,7 @@ func TestUpdateTx(t *testing.T) {
 				newAcc.TransactedAt,
 				newAcc.UpdatedAt,
 				newAcc.ID,
+				pq.Array(model.TransactionStatusesBefore(newAcc.Status)),
 			).
 			WillReturnError(err)
 
@@ -230,7 +357,12 @@ func TestList(t *testing.T) {
 			"status",
 			"reference_id",
 			"amount",
+			"fee",
 			"transacted_at",
+			"parent_id",
+			"refunded_amount",
+			"reason",
+			"operator",
 			"created_at",
 			"updated_at",
 		}).AddRow(
@@ -240,7 +372,12 @@ func TestList(t *testing.T) {
 			acc.Status,
 			acc.ReferenceID,
 			acc.Amount,
+			acc.Fee,
 			acc.TransactedAt,
+			acc.ParentID,
+			acc.RefundedAmount,
+			acc.Reason,
+			acc.Operator,
 			acc.CreatedAt,
 			acc.UpdatedAt,
 		)
@@ -252,7 +389,17 @@ func TestList(t *testing.T) {
 		mock.ExpectQuery(qList).WithArgs(
 			pq.Array(filter.IDs),
 			pq.Array(filter.WalletIDs),
-			defaultOrderColumn,
+			pq.Array(filter.ReferenceIDs),
+			pq.Array(filter.Types),
+			pq.Array(filter.Statuses),
+			filter.MinAmount,
+			filter.MaxAmount,
+			filter.TransactedFrom,
+			filter.TransactedTo,
+			nil,
+			nil,
+			nil,
+			nil,
 		).WillReturnRows(expectedRow)
 
 		repo := &transactionRepository{db: db}
@@ -262,6 +409,101 @@ func TestList(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("success with cursor and limit", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		minAmount := int64(100)
+		from := time.Now().Add(-time.Hour)
+		filter := internal.TransactionFilter{
+			WalletIDs:      []string{uuid.New().String()},
+			Types:          []string{model.TransactionType.Deposit},
+			Statuses:       []string{model.TransactionStatus.Success},
+			MinAmount:      &minAmount,
+			TransactedFrom: &from,
+			CreatedBefore:  &from,
+			After: &internal.TransactionCursor{
+				CreatedAt: time.Now(),
+				ID:        uuid.New().String(),
+			},
+			Limit: 10,
+		}
+		mock.ExpectQuery(qList).WithArgs(
+			pq.Array(filter.IDs),
+			pq.Array(filter.WalletIDs),
+			pq.Array(filter.ReferenceIDs),
+			pq.Array(filter.Types),
+			pq.Array(filter.Statuses),
+			filter.MinAmount,
+			filter.MaxAmount,
+			filter.TransactedFrom,
+			filter.TransactedTo,
+			filter.After.CreatedAt,
+			filter.After.ID,
+			filter.CreatedBefore,
+			filter.Limit,
+		).WillReturnRows(sqlmock.NewRows([]string{
+			"id",
+			"wallet_id",
+			"type",
+			"status",
+			"reference_id",
+			"amount",
+			"fee",
+			"transacted_at",
+			"parent_id",
+			"refunded_amount",
+			"reason",
+			"operator",
+			"created_at",
+			"updated_at",
+		}))
+
+		repo := &transactionRepository{db: db}
+		results, err := repo.List(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Transaction{}, results)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success ascending", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		filter := internal.TransactionFilter{
+			WalletIDs: []string{uuid.New().String()},
+			After: &internal.TransactionCursor{
+				CreatedAt: time.Now(),
+				ID:        uuid.New().String(),
+			},
+			Ascending: true,
+			Limit:     10,
+		}
+		mock.ExpectQuery(qListAscending).WithArgs(
+			pq.Array(filter.IDs),
+			pq.Array(filter.WalletIDs),
+			pq.Array(filter.ReferenceIDs),
+			pq.Array(filter.Types),
+			pq.Array(filter.Statuses),
+			filter.MinAmount,
+			filter.MaxAmount,
+			filter.TransactedFrom,
+			filter.TransactedTo,
+			filter.After.CreatedAt,
+			filter.After.ID,
+			filter.CreatedBefore,
+			filter.Limit,
+		).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		repo := &transactionRepository{db: db}
+		results, err := repo.List(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Not Found", func(t *testing.T) {
 		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
 		assert.NoError(t, err)
@@ -274,7 +516,17 @@ func TestList(t *testing.T) {
 		mock.ExpectQuery(qList).WithArgs(
 			pq.Array(filter.IDs),
 			pq.Array(filter.WalletIDs),
-			defaultOrderColumn,
+			pq.Array(filter.ReferenceIDs),
+			pq.Array(filter.Types),
+			pq.Array(filter.Statuses),
+			filter.MinAmount,
+			filter.MaxAmount,
+			filter.TransactedFrom,
+			filter.TransactedTo,
+			nil,
+			nil,
+			nil,
+			nil,
 		).WillReturnError(sql.ErrNoRows)
 
 		repo := &transactionRepository{db: db}
@@ -284,3 +536,101 @@ func TestList(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
+
+func TestAddRefundedTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		transaction := model.Transaction{
+			ID:        uuid.New(),
+			UpdatedAt: time.Now(),
+		}
+		mock.
+			ExpectPrepare(qAddRefunded).
+			ExpectExec().
+			WithArgs(
+				int64(500),
+				transaction.UpdatedAt,
+				transaction.ID,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &transactionRepository{db: db}
+		affected, err := repo.AddRefundedTx(context.Background(), tx, transaction, 500)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Execute", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		errExpected := errors.New("err")
+		mock.
+			ExpectPrepare(qAddRefunded).
+			ExpectExec().
+			WillReturnError(errExpected)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &transactionRepository{db: db}
+		affected, err := repo.AddRefundedTx(context.Background(), tx, model.Transaction{}, 500)
+		assert.ErrorIs(t, err, errExpected)
+		assert.Equal(t, int64(0), affected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSummarize(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		from := time.Now().Add(-time.Hour)
+		filter := internal.TransactionFilter{
+			WalletIDs:      []string{uuid.New().String()},
+			Types:          []string{model.TransactionType.Deposit},
+			Statuses:       []string{model.TransactionStatus.Success},
+			TransactedFrom: &from,
+		}
+		mock.ExpectQuery(qSummarize).WithArgs(
+			pq.Array(filter.WalletIDs),
+			pq.Array(filter.Types),
+			pq.Array(filter.Statuses),
+			filter.TransactedFrom,
+			filter.TransactedTo,
+		).WillReturnRows(sqlmock.NewRows([]string{"count", "sum"}).AddRow(3, "15000"))
+
+		repo := &transactionRepository{db: db}
+		summary, err := repo.Summarize(context.Background(), filter)
+		assert.NoError(t, err)
+		assert.Equal(t, internal.TransactionSummary{Count: 3, Amount: 15000}, summary)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Query", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errExpected := errors.New("err")
+		mock.ExpectQuery(qSummarize).WillReturnError(errExpected)
+
+		repo := &transactionRepository{db: db}
+		summary, err := repo.Summarize(context.Background(), internal.TransactionFilter{})
+		assert.ErrorIs(t, err, errExpected)
+		assert.Equal(t, internal.TransactionSummary{}, summary)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -3,17 +3,77 @@ package internal
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
 
 	"github.com/hokdre/mini-ewallet/internal/model"
 )
 
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 type TransactionFilter struct {
-	WalletIDs []string
-	IDs       []string
+	WalletIDs      []string
+	IDs            []string
+	ReferenceIDs   []string
+	Types          []string
+	Statuses       []string
+	MinAmount      *int64
+	MaxAmount      *int64
+	TransactedFrom *time.Time
+	TransactedTo   *time.Time
+	CreatedBefore  *time.Time
+
+	// After continues the listing right after the given cursor,
+	// transactions are ordered by created_at and id descending, or
+	// ascending when Ascending is set.
+	After     *TransactionCursor
+	Ascending bool
+	Limit     int
+}
+
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+func (c TransactionCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransactionCursor(cursor string) (TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return TransactionCursor{}, ErrInvalidCursor
+	}
+
+	return TransactionCursor{CreatedAt: createdAt, ID: parts[1]}, nil
+}
+
+// TransactionSummary aggregates the transactions matched by a filter.
+type TransactionSummary struct {
+	Count  int
+	Amount int64
 }
 
 type TransactionRepository interface {
 	List(ctx context.Context, filter TransactionFilter) ([]model.Transaction, error)
+	Summarize(ctx context.Context, filter TransactionFilter) (TransactionSummary, error)
+	SummarizeTx(ctx context.Context, tx *sql.Tx, filter TransactionFilter) (TransactionSummary, error)
 	Create(ctx context.Context, newTransaction model.Transaction) error
+	CreateTx(ctx context.Context, tx *sql.Tx, newTransaction model.Transaction) error
 	UpdateTx(ctx context.Context, tx *sql.Tx, transaction model.Transaction) (err error)
+	AddRefundedTx(ctx context.Context, tx *sql.Tx, transaction model.Transaction, amount int64) (int64, error)
 }
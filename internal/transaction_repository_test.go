@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionCursor(t *testing.T) {
+	t.Run("Encode and decode", func(t *testing.T) {
+		cursor := TransactionCursor{
+			CreatedAt: time.Date(2024, 5, 1, 10, 30, 0, 123456000, time.UTC),
+			ID:        uuid.New().String(),
+		}
+
+		decoded, err := DecodeTransactionCursor(cursor.Encode())
+		assert.NoError(t, err)
+		assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+		assert.Equal(t, cursor.ID, decoded.ID)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		for _, v := range []string{"%%%", "bm9waXBl", "MjAyNC0wNS0wMXw"} {
+			_, err := DecodeTransactionCursor(v)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		}
+	})
+}
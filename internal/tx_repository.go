@@ -3,32 +3,167 @@ package internal
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/pkg/tracing"
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	defaultTxMaxAttempts = 3
+	defaultTxBackoff     = 20 * time.Millisecond
 )
 
+var tracer = otel.Tracer("github.com/hokdre/mini-ewallet/internal")
+
+// retryableCodes are the postgres errors of a transaction that lost against
+// a concurrent one, running it again from the start may well succeed.
+var retryableCodes = map[pq.ErrorCode]bool{
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
 type TxRepository interface {
-	Process(ctx context.Context, f func(context.Context, *sql.Tx) error) error
+	Process(ctx context.Context, f func(context.Context, *sql.Tx) error, opts ...TxOption) error
+}
+
+type TxOptions struct {
+	// Isolation is the isolation level, the default is the one of the
+	// database, read committed for postgres.
+	Isolation sql.IsolationLevel
+	// ReadOnly rejects writes, for transactions that only need a consistent
+	// view of several reads.
+	ReadOnly bool
+	// Timeout bounds every attempt, zero means no timeout besides the one
+	// of the context.
+	Timeout time.Duration
+	// MaxAttempts is how often f runs when the transaction keeps failing on
+	// serialization failures or deadlocks.
+	MaxAttempts int
+}
+
+type TxOption func(*TxOptions)
+
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(opts *TxOptions) {
+		opts.Isolation = level
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(opts *TxOptions) {
+		opts.ReadOnly = true
+	}
+}
+
+func WithTimeout(timeout time.Duration) TxOption {
+	return func(opts *TxOptions) {
+		opts.Timeout = timeout
+	}
+}
+
+func WithMaxAttempts(attempts int) TxOption {
+	return func(opts *TxOptions) {
+		opts.MaxAttempts = attempts
+	}
+}
+
+// NewTxOptions applies opts over the defaults.
+func NewTxOptions(opts ...TxOption) TxOptions {
+	options := TxOptions{MaxAttempts: defaultTxMaxAttempts}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.MaxAttempts < 1 {
+		options.MaxAttempts = 1
+	}
+
+	return options
 }
 
 type txRepository struct {
 	db *sql.DB
+	// backoff before the first retry, doubled for every next one.
+	backoff time.Duration
 }
 
 func NewTxRepository(db *sql.DB) *txRepository {
-	return &txRepository{db: db}
+	return &txRepository{db: db, backoff: defaultTxBackoff}
+}
+
+// Process runs f in a transaction, the statements f runs with the context it
+// is given are traced under the transaction span. A commit failure is
+// returned like any error of f, and a panic of f rolls back before it goes
+// on. Serialization failures and deadlocks run f again in a new transaction,
+// so f must not keep state between runs other than what it assigns anew.
+func (t *txRepository) Process(ctx context.Context, f func(context.Context, *sql.Tx) error, opts ...TxOption) (err error) {
+	ctx, span := tracer.Start(ctx, "TxRepository.Process")
+	defer tracing.End(span, &err)
+
+	options := NewTxOptions(opts...)
+	backoff := t.backoff
+	for attempt := 1; ; attempt++ {
+		span.SetAttributes(attribute.Int("db.transaction.attempts", attempt))
+
+		err = t.attempt(ctx, f, options)
+		if err == nil || attempt >= options.MaxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(jitter(backoff)):
+		}
+		backoff *= 2
+	}
 }
 
-func (t *txRepository) Process(ctx context.Context, f func(context.Context, *sql.Tx) error) error {
-	tx, err := t.db.Begin()
+func (t *txRepository) attempt(ctx context.Context, f func(context.Context, *sql.Tx) error, options TxOptions) (err error) {
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+
+	tx, err := t.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: options.Isolation,
+		ReadOnly:  options.ReadOnly,
+	})
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
 
 	err = f(ctx, tx)
 	if err != nil {
 		_ = tx.Rollback()
-	} else {
-		_ = tx.Commit()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && retryableCodes[pqErr.Code]
+}
+
+// jitter waits between half and all of backoff, so transactions that lost
+// against each other do not collide again on their retries.
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return 0
 	}
 
-	return err
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
 }
@@ -5,13 +5,16 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestTxRepository(t *testing.T) {
 	t.Run("Process", TestProcess)
+	t.Run("TxOptions", TestTxOptions)
 }
 
 func TestProcess(t *testing.T) {
@@ -63,4 +66,143 @@ func TestProcess(t *testing.T) {
 		assert.Error(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("Failed commit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		var errExpected = errors.New("err")
+		mock.ExpectCommit().WillReturnError(errExpected)
+
+		r := txRepository{db: db}
+		err = r.Process(context.Background(), func(context.Context, *sql.Tx) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, errExpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Rollback on panic", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		r := txRepository{db: db}
+		assert.PanicsWithValue(t, "boom", func() {
+			_ = r.Process(context.Background(), func(context.Context, *sql.Tx) error {
+				panic("boom")
+			})
+		})
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Retry serialization failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errSerialization := &pq.Error{Code: "40001"}
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(&pq.Error{Code: "40P01"})
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		runs := 0
+		r := txRepository{db: db}
+		err = r.Process(context.Background(), func(context.Context, *sql.Tx) error {
+			runs++
+			if runs == 1 {
+				return errSerialization
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, runs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed retries exhausted", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errSerialization := &pq.Error{Code: "40001"}
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		runs := 0
+		r := txRepository{db: db}
+		err = r.Process(context.Background(), func(context.Context, *sql.Tx) error {
+			runs++
+			return errSerialization
+		}, WithMaxAttempts(2))
+		assert.ErrorIs(t, err, errSerialization)
+		assert.Equal(t, 2, runs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No retry of other errors", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errUnique := &pq.Error{Code: "23505"}
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		runs := 0
+		r := txRepository{db: db}
+		err = r.Process(context.Background(), func(context.Context, *sql.Tx) error {
+			runs++
+			return errUnique
+		})
+		assert.ErrorIs(t, err, errUnique)
+		assert.Equal(t, 1, runs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		r := txRepository{db: db}
+		err = r.Process(context.Background(), func(ctx context.Context, _ *sql.Tx) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithTimeout(10*time.Millisecond))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTxOptions(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		assert.Equal(t, TxOptions{MaxAttempts: defaultTxMaxAttempts}, NewTxOptions())
+	})
+
+	t.Run("Applied", func(t *testing.T) {
+		options := NewTxOptions(
+			WithIsolation(sql.LevelSerializable),
+			WithReadOnly(),
+			WithTimeout(time.Second),
+			WithMaxAttempts(0),
+		)
+		assert.Equal(t, TxOptions{
+			Isolation:   sql.LevelSerializable,
+			ReadOnly:    true,
+			Timeout:     time.Second,
+			MaxAttempts: 1,
+		}, options)
+	})
 }
@@ -0,0 +1,77 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+)
+
+// publish stores an event in the outbox within tx, it is only delivered
+// when tx commits.
+func (w *walletService) publish(
+	ctx context.Context,
+	tx *sql.Tx,
+	eventType string,
+	walletID uuid.UUID,
+	data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now()
+	return w.cfg.OutboxRepository.CreateTx(ctx, tx, model.Event{
+		ID:            uuid.New(),
+		Type:          eventType,
+		WalletID:      walletID,
+		Payload:       payload,
+		Status:        model.EventStatus.Pending,
+		NextAttemptAt: timestamp,
+		CreatedAt:     timestamp,
+		UpdatedAt:     timestamp,
+	})
+}
+
+func (w *walletService) publishTransaction(ctx context.Context, tx *sql.Tx, transaction model.Transaction) error {
+	eventType := model.EventType.TransactionSucceeded
+	if transaction.Status == model.TransactionStatus.Failed {
+		eventType = model.EventType.TransactionFailed
+	}
+
+	// the reason and operator of an adjustment are internal notes, they stay
+	// out of what webhook endpoints receive
+	transaction.Reason = ""
+	transaction.Operator = ""
+	return w.publish(ctx, tx, eventType, transaction.WalletID, transaction)
+}
+
+// auditWallet records the status change of wallet within tx along with the
+// operator of ctx, if any.
+func (w *walletService) auditWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
+	action := model.AuditAction.WalletEnabled
+	if wallet.Status == model.WalletStatus.Disabled {
+		action = model.AuditAction.WalletDisabled
+	}
+
+	return w.cfg.AuditRepository.CreateTx(ctx, tx, model.AuditEntry{
+		ID:        uuid.New(),
+		WalletID:  wallet.ID,
+		Action:    action,
+		Operator:  util.OperatorFromContext(ctx),
+		CreatedAt: time.Now(),
+	})
+}
+
+func (w *walletService) publishWallet(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
+	eventType := model.EventType.WalletEnabled
+	if wallet.Status == model.WalletStatus.Disabled {
+		eventType = model.EventType.WalletDisabled
+	}
+
+	return w.publish(ctx, tx, eventType, wallet.ID, wallet)
+}
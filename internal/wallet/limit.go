@@ -0,0 +1,154 @@
+package wallet
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/tracing"
+)
+
+// Limit caps one transaction type of a wallet, a zero value means no cap.
+type Limit struct {
+	MaxAmount     int64
+	DailyAmount   int64
+	MonthlyAmount int64
+	DailyCount    int
+	MonthlyCount  int
+}
+
+type Limits struct {
+	Deposit Limit
+	// Withdrawal caps every debit that leaves the wallet, withdrawals,
+	// outgoing transfers and captures count together.
+	Withdrawal Limit
+
+	// MaxBalance caps the wallet balance every credit can reach, deposits,
+	// incoming transfers, refunds and credit adjustments alike.
+	MaxBalance int64
+}
+
+// withdrawalTypes take money out of the wallet and share the withdrawal limit.
+var withdrawalTypes = []string{
+	model.TransactionType.Withdrawal,
+	model.TransactionType.TransferOut,
+	model.TransactionType.Capture,
+}
+
+// limitOf returns the limit of the transaction type and the types that
+// count toward it.
+func (l Limits) limitOf(transactionType string) (Limit, []string) {
+	switch transactionType {
+	case model.TransactionType.Deposit:
+		return l.Deposit, []string{model.TransactionType.Deposit}
+	case model.TransactionType.Withdrawal, model.TransactionType.TransferOut, model.TransactionType.Capture:
+		return l.Withdrawal, withdrawalTypes
+	}
+
+	return Limit{}, nil
+}
+
+type summarizeFunc func(ctx context.Context, filter internal.TransactionFilter) (internal.TransactionSummary, error)
+
+// exceedsMaxBalance tells whether crediting amount takes the wallet past the
+// max balance.
+func (l Limits) exceedsMaxBalance(wallet model.Wallet, amount int64) bool {
+	return l.MaxBalance > 0 && wallet.Balance+amount > l.MaxBalance
+}
+
+// checkLimits tells whether the transaction fits the wallet limits. Windows
+// are calendar days and months of the transaction creation time and only
+// successful transactions under the same limit count toward them. It rejects a
+// transaction early, before its pending record is stored, checkLimitsTx
+// decides within the transaction that moves the balance.
+func (w *walletService) checkLimits(ctx context.Context, wallet model.Wallet, transaction model.Transaction) (err error) {
+	ctx, span := tracer.Start(ctx, "walletService.checkLimits")
+	defer tracing.End(span, &err)
+
+	summarize := func(ctx context.Context, filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+		return w.cfg.TransactionRepository.Summarize(ctx, filter)
+	}
+	return w.limitsOf(ctx, summarize, wallet, transaction)
+}
+
+// checkLimitsTx is checkLimits against the wallet locked in tx, concurrent
+// transactions of the wallet wait for tx to end and then count it, so they
+// can not exceed the limits together.
+func (w *walletService) checkLimitsTx(ctx context.Context, tx *sql.Tx, walletID uuid.UUID, transaction model.Transaction) (err error) {
+	ctx, span := tracer.Start(ctx, "walletService.checkLimitsTx")
+	defer tracing.End(span, &err)
+
+	wallet, err := w.cfg.WalletRepository.LockTx(ctx, tx, walletID)
+	if err != nil {
+		return err
+	}
+
+	return w.limitsTx(ctx, tx, wallet, transaction)
+}
+
+// limitsTx is checkLimitsTx for a wallet the caller already locked in tx.
+func (w *walletService) limitsTx(ctx context.Context, tx *sql.Tx, wallet model.Wallet, transaction model.Transaction) error {
+	summarize := func(ctx context.Context, filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+		return w.cfg.TransactionRepository.SummarizeTx(ctx, tx, filter)
+	}
+	return w.limitsOf(ctx, summarize, wallet, transaction)
+}
+
+func (w *walletService) limitsOf(
+	ctx context.Context,
+	summarize summarizeFunc,
+	wallet model.Wallet,
+	transaction model.Transaction) error {
+	if transaction.Type == model.TransactionType.Deposit &&
+		w.cfg.Limits.exceedsMaxBalance(wallet, transaction.Amount) {
+		return model.ErrBalanceLimitExceeded
+	}
+
+	limit, types := w.cfg.Limits.limitOf(transaction.Type)
+	if limit.MaxAmount > 0 && transaction.Amount > limit.MaxAmount {
+		return model.ErrAmountLimitExceeded
+	}
+
+	createdAt := transaction.CreatedAt
+	startOfDay := time.Date(createdAt.Year(), createdAt.Month(), createdAt.Day(), 0, 0, 0, 0, createdAt.Location())
+	startOfMonth := time.Date(createdAt.Year(), createdAt.Month(), 1, 0, 0, 0, 0, createdAt.Location())
+
+	windows := []struct {
+		from      time.Time
+		maxAmount int64
+		maxCount  int
+		errAmount error
+		errCount  error
+	}{
+		{startOfDay, limit.DailyAmount, limit.DailyCount, model.ErrDailyAmountLimitExceeded, model.ErrDailyCountLimitExceeded},
+		{startOfMonth, limit.MonthlyAmount, limit.MonthlyCount, model.ErrMonthlyAmountLimitExceeded, model.ErrMonthlyCountLimitExceeded},
+	}
+	for _, window := range windows {
+		if window.maxAmount <= 0 && window.maxCount <= 0 {
+			continue
+		}
+
+		from := window.from
+		summary, err := summarize(ctx, internal.TransactionFilter{
+			WalletIDs:      []string{wallet.ID.String()},
+			Types:          types,
+			Statuses:       []string{model.TransactionStatus.Success},
+			TransactedFrom: &from,
+		})
+		if err != nil {
+			return err
+		}
+
+		if window.maxAmount > 0 && summary.Amount+transaction.Amount > window.maxAmount {
+			return window.errAmount
+		}
+		if window.maxCount > 0 && summary.Count+1 > window.maxCount {
+			return window.errCount
+		}
+	}
+
+	return nil
+}
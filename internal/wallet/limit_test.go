@@ -0,0 +1,189 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	mock "github.com/hokdre/mini-ewallet/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckLimits(t *testing.T) {
+	wallet := model.Wallet{
+		ID:      uuid.New(),
+		Balance: 10000,
+		Status:  model.WalletStatus.Enabled,
+	}
+	createdAt := time.Date(2024, time.March, 15, 10, 30, 0, 0, time.UTC)
+	deposit := model.Transaction{
+		WalletID:  wallet.ID,
+		Type:      model.TransactionType.Deposit,
+		Amount:    5000,
+		CreatedAt: createdAt,
+	}
+	withdrawal := deposit
+	withdrawal.Type = model.TransactionType.Withdrawal
+
+	t.Run("no limits configured", func(t *testing.T) {
+		w := &walletService{cfg: Config{}}
+		err := w.checkLimits(context.Background(), wallet, deposit)
+		assert.NoError(t, err)
+	})
+
+	t.Run("balance limit", func(t *testing.T) {
+		w := &walletService{cfg: Config{Limits: Limits{MaxBalance: 14999}}}
+		err := w.checkLimits(context.Background(), wallet, deposit)
+		assert.ErrorIs(t, err, model.ErrBalanceLimitExceeded)
+		assert.ErrorIs(t, err, model.ErrBussiness)
+
+		// withdrawals never grow the balance
+		err = w.checkLimits(context.Background(), wallet, withdrawal)
+		assert.NoError(t, err)
+	})
+
+	t.Run("single amount limit", func(t *testing.T) {
+		w := &walletService{cfg: Config{Limits: Limits{Withdrawal: Limit{MaxAmount: 4999}}}}
+		err := w.checkLimits(context.Background(), wallet, withdrawal)
+		assert.ErrorIs(t, err, model.ErrAmountLimitExceeded)
+
+		err = w.checkLimits(context.Background(), wallet, deposit)
+		assert.NoError(t, err)
+	})
+
+	t.Run("daily amount limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		startOfDay := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().Summarize(gomock.Any(), internal.TransactionFilter{
+			WalletIDs:      []string{wallet.ID.String()},
+			Types:          []string{model.TransactionType.Deposit},
+			Statuses:       []string{model.TransactionStatus.Success},
+			TransactedFrom: &startOfDay,
+		}).Return(internal.TransactionSummary{Count: 2, Amount: 6000}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				TransactionRepository: transactionRepo,
+				Limits:                Limits{Deposit: Limit{DailyAmount: 10000}},
+			},
+		}
+		err := w.checkLimits(context.Background(), wallet, deposit)
+		assert.ErrorIs(t, err, model.ErrDailyAmountLimitExceeded)
+	})
+
+	t.Run("monthly count limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		startOfMonth := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().Summarize(gomock.Any(), internal.TransactionFilter{
+			WalletIDs:      []string{wallet.ID.String()},
+			Types:          withdrawalTypes,
+			Statuses:       []string{model.TransactionStatus.Success},
+			TransactedFrom: &startOfMonth,
+		}).Return(internal.TransactionSummary{Count: 5, Amount: 6000}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				TransactionRepository: transactionRepo,
+				Limits:                Limits{Withdrawal: Limit{MonthlyCount: 5}},
+			},
+		}
+		err := w.checkLimits(context.Background(), wallet, withdrawal)
+		assert.ErrorIs(t, err, model.ErrMonthlyCountLimitExceeded)
+
+		var codeErr *model.CodeError
+		assert.True(t, errors.As(err, &codeErr))
+		assert.Equal(t, "monthly_count_limit_exceeded", codeErr.Code)
+	})
+
+	t.Run("transfers and captures share the withdrawal limit", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		startOfDay := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().Summarize(gomock.Any(), internal.TransactionFilter{
+			WalletIDs:      []string{wallet.ID.String()},
+			Types:          withdrawalTypes,
+			Statuses:       []string{model.TransactionStatus.Success},
+			TransactedFrom: &startOfDay,
+		}).Return(internal.TransactionSummary{Count: 1, Amount: 6000}, nil).Times(2)
+
+		w := &walletService{
+			cfg: Config{
+				TransactionRepository: transactionRepo,
+				Limits:                Limits{Withdrawal: Limit{MaxAmount: 4999, DailyAmount: 10000}},
+			},
+		}
+		for _, transactionType := range []string{model.TransactionType.TransferOut, model.TransactionType.Capture} {
+			debit := withdrawal
+			debit.Type = transactionType
+			err := w.checkLimits(context.Background(), wallet, debit)
+			assert.ErrorIs(t, err, model.ErrAmountLimitExceeded)
+
+			debit.Amount = 4500
+			err = w.checkLimits(context.Background(), wallet, debit)
+			assert.ErrorIs(t, err, model.ErrDailyAmountLimitExceeded)
+		}
+	})
+
+	t.Run("within limits", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().Summarize(gomock.Any(), gomock.Any()).
+			Return(internal.TransactionSummary{Count: 1, Amount: 1000}, nil).Times(2)
+
+		w := &walletService{
+			cfg: Config{
+				TransactionRepository: transactionRepo,
+				Limits: Limits{
+					Deposit: Limit{
+						MaxAmount:     5000,
+						DailyAmount:   6000,
+						DailyCount:    2,
+						MonthlyAmount: 6000,
+						MonthlyCount:  2,
+					},
+					MaxBalance: 15000,
+				},
+			},
+		}
+		err := w.checkLimits(context.Background(), wallet, deposit)
+		assert.NoError(t, err)
+	})
+	t.Run("locked wallet in transaction", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		// a concurrent deposit settled since the wallet was read
+		locked := wallet
+		locked.Balance = 12000
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(locked, nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				TransactionRepository: transactionRepo,
+				Limits:                Limits{Deposit: Limit{DailyCount: 2}, MaxBalance: 16000},
+			},
+		}
+		err := w.checkLimitsTx(context.Background(), nil, wallet.ID, deposit)
+		assert.ErrorIs(t, err, model.ErrBalanceLimitExceeded)
+
+		// the locked wallet counts the deposits settled meanwhile
+		startOfDay := time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), internal.TransactionFilter{
+			WalletIDs:      []string{wallet.ID.String()},
+			Types:          []string{model.TransactionType.Deposit},
+			Statuses:       []string{model.TransactionStatus.Success},
+			TransactedFrom: &startOfDay,
+		}).Return(internal.TransactionSummary{Count: 2, Amount: 2000}, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		err = w.checkLimitsTx(context.Background(), nil, wallet.ID, deposit)
+		assert.ErrorIs(t, err, model.ErrDailyCountLimitExceeded)
+	})
+}
@@ -0,0 +1,55 @@
+package wallet
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/metrics"
+)
+
+// failureReasons are the rejections told apart on /metrics, the first match
+// wins so specific errors come before the ones they wrap.
+var failureReasons = []struct {
+	err    error
+	reason string
+}{
+	{err: model.ErrWalletDisabled, reason: "wallet_disabled"},
+	{err: model.ErrReceiverWalletDisabled, reason: "receiver_wallet_disabled"},
+	{err: model.ErrTransferToOwnWallet, reason: "own_wallet"},
+	{err: model.ErrInsufficientBalance, reason: "insufficient_balance"},
+	{err: model.ErrReferenceIDConflict, reason: "reference_conflict"},
+	{err: model.ErrDuplicateReferenceID, reason: "duplicate_reference"},
+	{err: sql.ErrNoRows, reason: "not_found"},
+	{err: model.ErrBussiness, reason: "business"},
+	{err: model.ErrConflict, reason: "conflict"},
+}
+
+func failureReason(err error) string {
+	var codeErr *model.CodeError
+	if errors.As(err, &codeErr) {
+		return codeErr.Code
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return "invalid"
+	}
+
+	for _, failure := range failureReasons {
+		if errors.Is(err, failure.err) {
+			return failure.reason
+		}
+	}
+
+	return "internal"
+}
+
+// observeFailure is deferred with the address of the named error result, so
+// it sees whatever the method ends up returning.
+func observeFailure(transactionType string, err *error) {
+	if *err != nil {
+		metrics.ObserveFailure(transactionType, failureReason(*err))
+	}
+}
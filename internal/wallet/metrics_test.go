@@ -0,0 +1,34 @@
+package wallet
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureReason(t *testing.T) {
+	invalid := util.NewValidator().Validate(model.Transaction{})
+
+	cases := []struct {
+		err    error
+		reason string
+	}{
+		{err: model.ErrDailyAmountLimitExceeded, reason: "daily_amount_limit_exceeded"},
+		{err: invalid, reason: "invalid"},
+		{err: model.ErrWalletDisabled, reason: "wallet_disabled"},
+		{err: fmt.Errorf("receiver : %w", sql.ErrNoRows), reason: "not_found"},
+		{err: model.ErrReferenceIDConflict, reason: "reference_conflict"},
+		{err: model.ErrDuplicateReferenceID, reason: "duplicate_reference"},
+		{err: model.ErrInvalidStatusTransition, reason: "conflict"},
+		{err: model.ErrHoldExpired, reason: "business"},
+		{err: errors.New("connection reset"), reason: "internal"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.reason, failureReason(c.err), c.err.Error())
+	}
+}
@@ -3,6 +3,7 @@ package wallet
 import (
 	"database/sql"
 
+	"github.com/google/uuid"
 	"github.com/hokdre/mini-ewallet/internal"
 	"github.com/hokdre/mini-ewallet/internal/model"
 	"github.com/lib/pq"
@@ -16,14 +17,14 @@ const (
 
 	qCreate = `
 		INSERT INTO wallets (
-			id, owned_by, balance, status, enabled_at, disabled_at, created_at, updated_at, deleted_at, is_active
+			id, owned_by, balance, status, enabled_at, disabled_at, created_at, updated_at, version, deleted_at, is_active
 		) VALUES(
-			$1, $2, $3, $4, $5, $6, $7, $8, null, true 
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, null, true 
 		)
 	`
 	qGet = `
 	   SELECT 
-	   	id, owned_by, balance, status, enabled_at, disabled_at, created_at, updated_at
+	   	id, owned_by, balance, reserved, status, enabled_at, disabled_at, created_at, updated_at, version
 	   FROM wallets
 	   WHERE (id = ANY($1) or $1 IS NULL)
 	   AND (owned_by = ANY($2) or $2 IS NULL)
@@ -31,24 +32,55 @@ const (
 	   OFFSET $4
 	`
 
+	qLock = `
+	   SELECT
+	   	id, owned_by, balance, reserved, status, enabled_at, disabled_at, created_at, updated_at, version
+	   FROM wallets
+	   WHERE id = $1
+	   FOR UPDATE
+	`
+
 	qUpdate = `
 		UPDATE wallets SET
-			status = $1, enabled_at = $2, disabled_at = $3, updated_at = $4
-		WHERE id = $5
+			status = $1, enabled_at = $2, disabled_at = $3, updated_at = $4,
+			version = version + 1
+		WHERE id = $5 AND version = $6
 	`
 
 	qIncrementWallet = `
 		UPDATE wallets SET
 			balance = balance + $1,
 			updated_at = $2
-		WHERE id = $3
+		WHERE id = $3 AND (balance + $1 <= $4 OR $4 <= 0)
 	`
 
 	qDecrementWallet = `
 		UPDATE wallets SET
 			balance = balance - $1,
 			updated_at = $2
-		WHERE id = $3 AND balance >= $1
+		WHERE id = $3 AND balance - reserved >= $1
+	`
+
+	qReserveWallet = `
+		UPDATE wallets SET
+			reserved = reserved + $1,
+			updated_at = $2
+		WHERE id = $3 AND balance - reserved >= $1
+	`
+
+	qReleaseWallet = `
+		UPDATE wallets SET
+			reserved = reserved - $1,
+			updated_at = $2
+		WHERE id = $3 AND reserved >= $1
+	`
+
+	qCaptureWallet = `
+		UPDATE wallets SET
+			reserved = reserved - $1,
+			balance = balance - $2,
+			updated_at = $3
+		WHERE id = $4 AND reserved >= $1 AND balance >= $2
 	`
 )
 
@@ -77,6 +109,7 @@ func (w *walletRepository) CreateTx(ctx context.Context, tx *sql.Tx, newWallet m
 		newWallet.DisabledAt,
 		newWallet.CreatedAt,
 		newWallet.UpdatedAt,
+		newWallet.Version,
 	)
 	if err != nil {
 		return err
@@ -96,16 +129,28 @@ func (a *walletRepository) GetOne(ctx context.Context, filter internal.WalletFil
 		defaultOffset,
 	)
 
+	return scanWallet(row)
+}
+
+// LockTx reads the wallet with FOR UPDATE, concurrent transactions that lock
+// or change it wait until tx ends.
+func (a *walletRepository) LockTx(ctx context.Context, tx *sql.Tx, walletID uuid.UUID) (model.Wallet, error) {
+	return scanWallet(tx.QueryRowContext(ctx, qLock, walletID))
+}
+
+func scanWallet(row *sql.Row) (model.Wallet, error) {
 	wallet := model.Wallet{}
 	err := row.Scan(
 		&wallet.ID,
 		&wallet.OwnedBy,
 		&wallet.Balance,
+		&wallet.Reserved,
 		&wallet.Status,
 		&wallet.EnabledAt,
 		&wallet.DisabledAt,
 		&wallet.CreatedAt,
 		&wallet.UpdatedAt,
+		&wallet.Version,
 	)
 	if err != nil {
 		return model.Wallet{}, err
@@ -114,6 +159,8 @@ func (a *walletRepository) GetOne(ctx context.Context, filter internal.WalletFil
 	return wallet, nil
 }
 
+// Update writes the status columns when the stored wallet is still at
+// wallet.Version, ErrWalletVersionConflict is returned otherwise.
 func (a *walletRepository) Update(ctx context.Context, wallet model.Wallet) error {
 	stmt, err := a.db.Prepare(qUpdate)
 	if err != nil {
@@ -121,22 +168,51 @@ func (a *walletRepository) Update(ctx context.Context, wallet model.Wallet) erro
 	}
 	defer stmt.Close()
 
-	_, err = stmt.ExecContext(
+	return a.update(ctx, stmt, wallet)
+}
+
+// UpdateTx is Update within tx.
+func (a *walletRepository) UpdateTx(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
+	stmt, err := tx.Prepare(qUpdate)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	return a.update(ctx, stmt, wallet)
+}
+
+func (a *walletRepository) update(ctx context.Context, stmt *sql.Stmt, wallet model.Wallet) error {
+	result, err := stmt.ExecContext(
 		ctx,
 		wallet.Status,
 		wallet.EnabledAt,
 		wallet.DisabledAt,
 		wallet.UpdatedAt,
 		wallet.ID,
+		wallet.Version,
 	)
 	if err != nil {
 		return err
 	}
 
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return model.ErrWalletVersionConflict
+	}
+
 	return nil
 }
 
-func (a *walletRepository) Increment(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+func (a *walletRepository) Increment(
+	ctx context.Context,
+	tx *sql.Tx,
+	wallet model.Wallet,
+	amount int64,
+	maxBalance int64) (int64, error) {
 	stmt, err := tx.Prepare(qIncrementWallet)
 	if err != nil {
 		return 0, err
@@ -148,6 +224,7 @@ func (a *walletRepository) Increment(ctx context.Context, tx *sql.Tx, wallet mod
 		amount,
 		wallet.UpdatedAt,
 		wallet.ID,
+		maxBalance,
 	)
 	if err != nil {
 		return 0, err
@@ -185,3 +262,40 @@ func (a *walletRepository) Decrement(ctx context.Context, tx *sql.Tx, wallet mod
 
 	return affected, nil
 }
+
+func (a *walletRepository) Reserve(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+	return a.exec(ctx, tx, qReserveWallet, amount, wallet.UpdatedAt, wallet.ID)
+}
+
+func (a *walletRepository) Release(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+	return a.exec(ctx, tx, qReleaseWallet, amount, wallet.UpdatedAt, wallet.ID)
+}
+
+func (a *walletRepository) Capture(
+	ctx context.Context,
+	tx *sql.Tx,
+	wallet model.Wallet,
+	reserved int64,
+	amount int64) (int64, error) {
+	return a.exec(ctx, tx, qCaptureWallet, reserved, amount, wallet.UpdatedAt, wallet.ID)
+}
+
+func (a *walletRepository) exec(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (int64, error) {
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
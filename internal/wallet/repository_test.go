@@ -18,9 +18,13 @@ import (
 func TestWalletRepository(t *testing.T) {
 	t.Run("CreateTx", TestCreateTx)
 	t.Run("Get", TestGet)
+	t.Run("LockTx", TestLockTx)
 	t.Run("Update", TestUpdate)
+	t.Run("UpdateTx", TestUpdateTx)
 	t.Run("Increment", TestIncerement)
 	t.Run("Decrement", TestIncerement)
+	t.Run("Reserve", TestReserve)
+	t.Run("Capture", TestCapture)
 }
 
 func TestCreateTx(t *testing.T) {
@@ -40,6 +44,7 @@ func TestCreateTx(t *testing.T) {
 			DisabledAt: nil,
 			CreatedAt:  timestamp,
 			UpdatedAt:  timestamp,
+			Version:    1,
 		}
 
 		mock.
@@ -54,6 +59,7 @@ func TestCreateTx(t *testing.T) {
 				newWallet.DisabledAt,
 				newWallet.CreatedAt,
 				newWallet.UpdatedAt,
+				newWallet.Version,
 			).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -124,26 +130,31 @@ func TestGet(t *testing.T) {
 			UpdatedAt:  timeStamp,
 			EnabledAt:  nil,
 			DisabledAt: nil,
+			Version:    4,
 		}
 
 		expectedRow := sqlmock.NewRows([]string{
 			"id",
 			"owned_by",
 			"balance",
+			"reserved",
 			"status",
 			"enabled_at",
 			"disabled_at",
 			"created_at",
 			"updated_at",
+			"version",
 		}).AddRow(
 			wallet.ID,
 			wallet.OwnedBy,
 			wallet.Balance,
+			wallet.Reserved,
 			wallet.Status,
 			wallet.EnabledAt,
 			wallet.DisabledAt,
 			wallet.CreatedAt,
 			wallet.UpdatedAt,
+			wallet.Version,
 		)
 
 		filter := internal.WalletFilter{
@@ -179,26 +190,31 @@ func TestGet(t *testing.T) {
 			UpdatedAt:  timeStamp,
 			EnabledAt:  nil,
 			DisabledAt: nil,
+			Version:    4,
 		}
 
 		expectedRow := sqlmock.NewRows([]string{
 			"id",
 			"owned_by",
 			"balance",
+			"reserved",
 			"status",
 			"enabled_at",
 			"disabled_at",
 			"created_at",
 			"updated_at",
+			"version",
 		}).AddRow(
 			wallet.ID,
 			wallet.OwnedBy,
 			wallet.Balance,
+			wallet.Reserved,
 			wallet.Status,
 			wallet.EnabledAt,
 			wallet.DisabledAt,
 			wallet.CreatedAt,
 			wallet.UpdatedAt,
+			wallet.Version,
 		)
 
 		filter := internal.WalletFilter{
@@ -243,6 +259,71 @@ func TestGet(t *testing.T) {
 	})
 }
 
+func TestLockTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		timeStamp := time.Now()
+		wallet := model.Wallet{
+			ID:        uuid.New(),
+			OwnedBy:   uuid.New(),
+			Balance:   3000,
+			Reserved:  1000,
+			Status:    model.WalletStatus.Enabled,
+			CreatedAt: timeStamp,
+			UpdatedAt: timeStamp,
+			Version:   2,
+		}
+
+		expectedRow := sqlmock.NewRows([]string{
+			"id", "owned_by", "balance", "reserved", "status",
+			"enabled_at", "disabled_at", "created_at", "updated_at", "version",
+		}).AddRow(
+			wallet.ID,
+			wallet.OwnedBy,
+			wallet.Balance,
+			wallet.Reserved,
+			wallet.Status,
+			wallet.EnabledAt,
+			wallet.DisabledAt,
+			wallet.CreatedAt,
+			wallet.UpdatedAt,
+			wallet.Version,
+		)
+		mock.ExpectQuery(qLock).WithArgs(wallet.ID).WillReturnRows(expectedRow)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		locked, err := repo.LockTx(context.Background(), tx, wallet.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, wallet, locked)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed, not found", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		walletID := uuid.New()
+		mock.ExpectQuery(qLock).WithArgs(walletID).WillReturnError(sql.ErrNoRows)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		_, err = repo.LockTx(context.Background(), tx, walletID)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestUpdate(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
@@ -259,6 +340,7 @@ func TestUpdate(t *testing.T) {
 			DisabledAt: nil,
 			CreatedAt:  timestamp,
 			UpdatedAt:  timestamp,
+			Version:    2,
 		}
 
 		mock.
@@ -270,6 +352,7 @@ func TestUpdate(t *testing.T) {
 				newWallet.DisabledAt,
 				newWallet.UpdatedAt,
 				newWallet.ID,
+				newWallet.Version,
 			).
 			WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -311,6 +394,7 @@ func TestUpdate(t *testing.T) {
 				newWallet.DisabledAt,
 				newWallet.UpdatedAt,
 				newWallet.ID,
+				newWallet.Version,
 			).WillReturnError(errors.New("unexpected error"))
 
 		repo := &walletRepository{db: db}
@@ -319,6 +403,30 @@ func TestUpdate(t *testing.T) {
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("Failed, version conflict", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		newWallet := model.Wallet{ID: uuid.New(), Status: model.WalletStatus.Enabled, Version: 2}
+		mock.
+			ExpectPrepare(qUpdate).
+			ExpectExec().
+			WithArgs(
+				newWallet.Status,
+				newWallet.EnabledAt,
+				newWallet.DisabledAt,
+				newWallet.UpdatedAt,
+				newWallet.ID,
+				newWallet.Version,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		repo := &walletRepository{db: db}
+		err = repo.Update(context.Background(), newWallet)
+		assert.ErrorIs(t, err, model.ErrWalletVersionConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestIncerement(t *testing.T) {
@@ -348,6 +456,7 @@ func TestIncerement(t *testing.T) {
 				amount,
 				newWallet.UpdatedAt,
 				newWallet.ID,
+				0,
 			).
 			WillReturnResult(sqlmock.NewResult(int64(1), 1))
 
@@ -355,7 +464,7 @@ func TestIncerement(t *testing.T) {
 		assert.NoError(t, err)
 
 		repo := &walletRepository{db: db}
-		afftected, errCreate := repo.Increment(context.Background(), tx, newWallet, int64(amount))
+		afftected, errCreate := repo.Increment(context.Background(), tx, newWallet, int64(amount), 0)
 		assert.NoError(t, errCreate)
 		assert.Equal(t, afftected, int64(1))
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -379,7 +488,7 @@ func TestIncerement(t *testing.T) {
 		assert.NoError(t, err)
 
 		repo := &walletRepository{db: db}
-		afftected, errCreate := repo.Increment(context.Background(), tx, newWallet, int64(amount))
+		afftected, errCreate := repo.Increment(context.Background(), tx, newWallet, int64(amount), 0)
 		assert.Error(t, errCreate, errExpected)
 		assert.Equal(t, afftected, int64(0))
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -412,6 +521,7 @@ func TestIncerement(t *testing.T) {
 				amount,
 				newWallet.UpdatedAt,
 				newWallet.ID,
+				0,
 			).
 			WillReturnError(errExpected)
 
@@ -419,12 +529,44 @@ func TestIncerement(t *testing.T) {
 		assert.NoError(t, err)
 
 		repo := &walletRepository{db: db}
-		afftected, errCreate := repo.Increment(context.Background(), tx, newWallet, int64(amount))
+		afftected, errCreate := repo.Increment(context.Background(), tx, newWallet, int64(amount), 0)
 		assert.Error(t, errCreate)
 		assert.Equal(t, afftected, int64(0))
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("Max balance reached", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		newWallet := model.Wallet{
+			ID:        uuid.New(),
+			Balance:   4000,
+			UpdatedAt: time.Now(),
+		}
+
+		mock.
+			ExpectPrepare(qIncrementWallet).
+			ExpectExec().
+			WithArgs(
+				2000,
+				newWallet.UpdatedAt,
+				newWallet.ID,
+				5000,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		afftected, errIncrement := repo.Increment(context.Background(), tx, newWallet, 2000, 5000)
+		assert.NoError(t, errIncrement)
+		assert.Equal(t, int64(0), afftected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestDecrement(t *testing.T) {
@@ -532,3 +674,211 @@ func TestDecrement(t *testing.T) {
 	})
 
 }
+
+func TestReserve(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		wallet := model.Wallet{
+			ID:        uuid.New(),
+			UpdatedAt: time.Now(),
+		}
+		amount := int64(10000)
+
+		mock.
+			ExpectPrepare(qReserveWallet).
+			ExpectExec().
+			WithArgs(
+				amount,
+				wallet.UpdatedAt,
+				wallet.ID,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		affected, errReserve := repo.Reserve(context.Background(), tx, wallet, amount)
+		assert.NoError(t, errReserve)
+		assert.Equal(t, int64(1), affected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Insufficient available balance", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		wallet := model.Wallet{
+			ID:        uuid.New(),
+			UpdatedAt: time.Now(),
+		}
+		amount := int64(10000)
+
+		mock.
+			ExpectPrepare(qReserveWallet).
+			ExpectExec().
+			WithArgs(
+				amount,
+				wallet.UpdatedAt,
+				wallet.ID,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		affected, errReserve := repo.Reserve(context.Background(), tx, wallet, amount)
+		assert.NoError(t, errReserve)
+		assert.Equal(t, int64(0), affected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCapture(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		wallet := model.Wallet{
+			ID:        uuid.New(),
+			UpdatedAt: time.Now(),
+		}
+
+		mock.
+			ExpectPrepare(qCaptureWallet).
+			ExpectExec().
+			WithArgs(
+				int64(10000),
+				int64(7500),
+				wallet.UpdatedAt,
+				wallet.ID,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		affected, errCapture := repo.Capture(context.Background(), tx, wallet, 10000, 7500)
+		assert.NoError(t, errCapture)
+		assert.Equal(t, int64(1), affected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Prepare", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		var errExpected = errors.New("error")
+		mock.
+			ExpectPrepare(qCaptureWallet).
+			WillReturnError(errExpected)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		affected, errCapture := repo.Capture(context.Background(), tx, model.Wallet{}, 10000, 7500)
+		assert.ErrorIs(t, errCapture, errExpected)
+		assert.Equal(t, int64(0), affected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUpdateTx(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		timestamp := time.Now()
+		wallet := model.Wallet{
+			ID:        uuid.New(),
+			OwnedBy:   uuid.New(),
+			Status:    model.WalletStatus.Enabled,
+			EnabledAt: &timestamp,
+			CreatedAt: timestamp,
+			UpdatedAt: timestamp,
+			Version:   1,
+		}
+		mock.
+			ExpectPrepare(qUpdate).
+			ExpectExec().
+			WithArgs(
+				wallet.Status,
+				wallet.EnabledAt,
+				wallet.DisabledAt,
+				wallet.UpdatedAt,
+				wallet.ID,
+				wallet.Version,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		err = repo.UpdateTx(context.Background(), tx, wallet)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed Prepare", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		errExpected := errors.New("Failed create prepate statement")
+		mock.ExpectPrepare(qUpdate).WillReturnError(errExpected)
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		err = repo.UpdateTx(context.Background(), tx, model.Wallet{})
+		assert.ErrorIs(t, err, errExpected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed, version conflict", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+		mock.ExpectBegin()
+
+		wallet := model.Wallet{ID: uuid.New(), Status: model.WalletStatus.Disabled, Version: 5}
+		mock.
+			ExpectPrepare(qUpdate).
+			ExpectExec().
+			WithArgs(
+				wallet.Status,
+				wallet.EnabledAt,
+				wallet.DisabledAt,
+				wallet.UpdatedAt,
+				wallet.ID,
+				wallet.Version,
+			).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		tx, err := db.Begin()
+		assert.NoError(t, err)
+
+		repo := &walletRepository{db: db}
+		err = repo.UpdateTx(context.Background(), tx, wallet)
+		assert.ErrorIs(t, err, model.ErrWalletVersionConflict)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -3,21 +3,63 @@ package wallet
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"log"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hokdre/mini-ewallet/internal"
 	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/pkg/metrics"
+	"github.com/hokdre/mini-ewallet/pkg/tracing"
 	"github.com/hokdre/mini-ewallet/pkg/util"
 )
 
+const (
+	defaultTransactionLimit = 20
+	maxTransactionLimit     = 100
+
+	defaultHoldExpiry        = 7 * 24 * time.Hour
+	defaultHoldSweepInterval = time.Minute
+	expireHoldBatchSize      = 100
+
+	defaultPendingRecoveryAge      = 5 * time.Minute
+	defaultPendingRecoveryInterval = time.Minute
+	recoverPendingBatchSize        = 100
+
+	statementPageSize = 500
+)
+
 type Config struct {
 	AccountRepo           internal.AccountRepository
 	WalletRepository      internal.WalletRepository
 	TransactionRepository internal.TransactionRepository
 	Validator             util.Validator
-	Encryption            util.Encryption
+	TokenService          internal.TokenService
 	TxRepository          internal.TxRepository
+	LedgerRepository      internal.LedgerRepository
+	HoldRepository        internal.HoldRepository
+	OutboxRepository      internal.OutboxRepository
+	AuditRepository       internal.AuditRepository
+
+	// HoldExpiry is how long an authorized hold keeps its funds reserved.
+	HoldExpiry time.Duration
+
+	// PendingRecoveryAge is how long a transaction may stay pending before
+	// RecoverPending resolves it.
+	PendingRecoveryAge time.Duration
+
+	Limits Limits
+
+	// WithdrawalFee and TransferFee are charged on top of the amount, nil
+	// means free.
+	WithdrawalFee internal.FeeCalculator
+	TransferFee   internal.FeeCalculator
+
+	// TxTimeout bounds every database transaction of the service, zero
+	// leaves them to the request context.
+	TxTimeout time.Duration
 }
 
 type walletService struct {
@@ -28,35 +70,38 @@ func NewWalletService(cfg Config) *walletService {
 	return &walletService{cfg: cfg}
 }
 
-func (w *walletService) Init(ctx context.Context, externalID string) (string, error) {
+func (w *walletService) Init(ctx context.Context, externalID string) (_ model.TokenPair, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Init")
+	defer tracing.End(span, &err)
+
 	newAccount := model.Account{
 		ID:                 uuid.New(),
 		ExternalCustomerID: externalID,
 		CreatedAt:          time.Now(),
 		UpdatedAt:          time.Now(),
 	}
-	err := w.cfg.Validator.Validate(newAccount)
+	err = w.validate(ctx, newAccount)
 	if err != nil {
-		return "", err
+		return model.TokenPair{}, err
 	}
 
 	existingAcc, errGetAcc := w.cfg.AccountRepo.Get(ctx, internal.AccountFilter{
 		ExternalIDs: []string{externalID},
 	})
 	if errGetAcc != nil && errGetAcc != sql.ErrNoRows {
-		return "", errGetAcc
+		return model.TokenPair{}, errGetAcc
 	}
 
 	accountID := existingAcc.ID
 	if accountID == uuid.Nil {
 		v, _, err := w.createAccountAndWallet(ctx, externalID)
 		if err != nil {
-			return "", err
+			return model.TokenPair{}, err
 		}
 		accountID = v
 	}
 
-	return w.createToken(accountID)
+	return w.createToken(ctx, accountID)
 }
 
 func (w *walletService) createAccountAndWallet(
@@ -77,6 +122,7 @@ func (w *walletService) createAccountAndWallet(
 		Balance:   0,
 		CreatedAt: timeStamp,
 		UpdatedAt: timeStamp,
+		Version:   1,
 	}
 
 	errCreate := w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
@@ -91,7 +137,7 @@ func (w *walletService) createAccountAndWallet(
 		}
 
 		return nil
-	})
+	}, w.txOptions()...)
 	if errCreate != nil {
 		return uuid.Nil, uuid.Nil, errCreate
 	}
@@ -99,17 +145,23 @@ func (w *walletService) createAccountAndWallet(
 	return newAccount.ID, newWallet.ID, nil
 }
 
-func (w *walletService) createToken(accountID uuid.UUID) (string, error) {
-	return w.cfg.Encryption.Encrypt(accountID.String())
+func (w *walletService) createToken(ctx context.Context, accountID uuid.UUID) (model.TokenPair, error) {
+	return w.cfg.TokenService.Issue(ctx, accountID)
 }
 
-func (w *walletService) Enable(ctx context.Context, accountID uuid.UUID) (model.Wallet, error) {
+func (w *walletService) Enable(ctx context.Context, accountID uuid.UUID, version int64) (_ model.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Enable")
+	defer tracing.End(span, &err)
+
 	wallet, err := w.cfg.WalletRepository.GetOne(ctx, internal.WalletFilter{
 		OwnedBies: []string{accountID.String()},
 	})
 	if err != nil {
 		return model.Wallet{}, err
 	}
+	if version != 0 && version != wallet.Version {
+		return model.Wallet{}, model.ErrWalletVersionConflict
+	}
 	if wallet.Status == model.WalletStatus.Enabled {
 		return model.Wallet{}, model.ErrWalletAlreadyEnabled
 	}
@@ -119,21 +171,43 @@ func (w *walletService) Enable(ctx context.Context, accountID uuid.UUID) (model.
 	wallet.EnabledAt = &timestamp
 	wallet.DisabledAt = nil
 	wallet.UpdatedAt = timestamp
-	err = w.cfg.WalletRepository.Update(ctx, wallet)
+	// the update only lands on the version read above, so of two concurrent
+	// calls one fails with ErrWalletVersionConflict
+	updated := wallet
+	updated.Version++
+	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		errUpdate := w.cfg.WalletRepository.UpdateTx(ctx, tx, wallet)
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		errAudit := w.auditWallet(ctx, tx, updated)
+		if errAudit != nil {
+			return errAudit
+		}
+
+		return w.publishWallet(ctx, tx, updated)
+	}, w.txOptions()...)
 	if err != nil {
 		return model.Wallet{}, err
 	}
 
-	return wallet, nil
+	return updated, nil
 }
 
-func (w *walletService) Disable(ctx context.Context, accountID uuid.UUID) (model.Wallet, error) {
+func (w *walletService) Disable(ctx context.Context, accountID uuid.UUID, version int64) (_ model.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Disable")
+	defer tracing.End(span, &err)
+
 	wallet, err := w.cfg.WalletRepository.GetOne(ctx, internal.WalletFilter{
 		OwnedBies: []string{accountID.String()},
 	})
 	if err != nil {
 		return model.Wallet{}, err
 	}
+	if version != 0 && version != wallet.Version {
+		return model.Wallet{}, model.ErrWalletVersionConflict
+	}
 	if wallet.Status == model.WalletStatus.Disabled {
 		return model.Wallet{}, model.ErrWalletAlreadyDisabled
 	}
@@ -143,15 +217,34 @@ func (w *walletService) Disable(ctx context.Context, accountID uuid.UUID) (model
 	wallet.EnabledAt = nil
 	wallet.DisabledAt = &timestamp
 	wallet.UpdatedAt = timestamp
-	err = w.cfg.WalletRepository.Update(ctx, wallet)
+	// the update only lands on the version read above, so of two concurrent
+	// calls one fails with ErrWalletVersionConflict
+	updated := wallet
+	updated.Version++
+	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		errUpdate := w.cfg.WalletRepository.UpdateTx(ctx, tx, wallet)
+		if errUpdate != nil {
+			return errUpdate
+		}
+
+		errAudit := w.auditWallet(ctx, tx, updated)
+		if errAudit != nil {
+			return errAudit
+		}
+
+		return w.publishWallet(ctx, tx, updated)
+	}, w.txOptions()...)
 	if err != nil {
 		return model.Wallet{}, err
 	}
 
-	return wallet, nil
+	return updated, nil
 }
 
-func (w *walletService) Get(ctx context.Context, accountID uuid.UUID) (model.Wallet, error) {
+func (w *walletService) Get(ctx context.Context, accountID uuid.UUID) (_ model.Wallet, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Get")
+	defer tracing.End(span, &err)
+
 	wallet, err := w.cfg.WalletRepository.GetOne(ctx, internal.WalletFilter{
 		OwnedBies: []string{accountID.String()},
 	})
@@ -159,34 +252,209 @@ func (w *walletService) Get(ctx context.Context, accountID uuid.UUID) (model.Wal
 		return model.Wallet{}, err
 	}
 
+	// the disabled wallet still goes back with the error, its version is
+	// what a client needs to enable it again
 	if wallet.Status == model.WalletStatus.Disabled {
-		return model.Wallet{}, model.ErrWalletDisabled
+		return wallet, model.ErrWalletDisabled
 	}
 
 	return wallet, nil
 }
 
-func (w *walletService) GetTransactions(ctx context.Context, accountID uuid.UUID) ([]model.Transaction, error) {
+func (w *walletService) GetTransactions(
+	ctx context.Context,
+	accountID uuid.UUID,
+	filter internal.TransactionFilter) (_ []model.Transaction, _ *internal.TransactionCursor, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.GetTransactions")
+	defer tracing.End(span, &err)
+
 	wallet, err := w.Get(ctx, accountID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if wallet.Status == model.WalletStatus.Disabled {
-		return nil, model.ErrWalletDisabled
+		return nil, nil, model.ErrWalletDisabled
 	}
 
-	transactions, err := w.cfg.TransactionRepository.List(ctx, internal.TransactionFilter{
+	filter.WalletIDs = []string{wallet.ID.String()}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultTransactionLimit
+	}
+	if filter.Limit > maxTransactionLimit {
+		filter.Limit = maxTransactionLimit
+	}
+
+	// fetch one extra row to know whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	transactions, err := w.cfg.TransactionRepository.List(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(transactions) <= limit {
+		return transactions, nil, nil
+	}
+
+	transactions = transactions[:limit]
+	last := transactions[limit-1]
+	return transactions, &internal.TransactionCursor{
+		CreatedAt: last.CreatedAt,
+		ID:        last.ID.String(),
+	}, nil
+}
+
+// Statement writes the successful transactions transacted between from and
+// to oldest first, each with the balance right after it. Transactions are
+// read one page at a time so a long statement never sits in memory. The
+// opening balance is everything transacted up to to, less the period itself,
+// both sums are read in one read only snapshot so they agree.
+func (w *walletService) Statement(
+	ctx context.Context,
+	accountID uuid.UUID,
+	from time.Time,
+	to time.Time,
+	writer internal.StatementWriter) (err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Statement")
+	defer tracing.End(span, &err)
+
+	if to.Before(from) {
+		return model.ErrInvalidStatementPeriod
+	}
+
+	wallet, err := w.Get(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	filter := internal.TransactionFilter{
 		WalletIDs: []string{wallet.ID.String()},
+		Statuses:  []string{model.TransactionStatus.Success},
+	}
+	filter.TransactedTo = &to
+	untilToFilter := filter
+	filter.TransactedFrom = &from
+
+	var untilTo, period int64
+	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		untilTo, err = w.signedBalanceTx(ctx, tx, untilToFilter)
+		if err != nil {
+			return err
+		}
+
+		period, err = w.signedBalanceTx(ctx, tx, filter)
+		return err
+	}, w.txOptions(internal.WithIsolation(sql.LevelRepeatableRead), internal.WithReadOnly())...)
+	if err != nil {
+		return err
+	}
+
+	statement := model.Statement{
+		WalletID:       wallet.ID,
+		From:           from,
+		To:             to,
+		OpeningBalance: untilTo - period,
+		GeneratedAt:    time.Now(),
+	}
+	err = writer.Begin(statement)
+	if err != nil {
+		return err
+	}
+
+	balance := statement.OpeningBalance
+	filter.Ascending = true
+	filter.Limit = statementPageSize
+	for {
+		transactions, err := w.cfg.TransactionRepository.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range transactions {
+			balance += t.SignedAmount()
+			err = writer.Line(model.StatementLine{Transaction: t, Balance: balance})
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(transactions) < statementPageSize {
+			break
+		}
+		last := transactions[len(transactions)-1]
+		filter.After = &internal.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID.String()}
+	}
+
+	statement.ClosingBalance = balance
+	return writer.End(statement)
+}
+
+// signedBalanceTx adds up the credits matched by filter and takes off the
+// debits, the types of filter are replaced.
+func (w *walletService) signedBalanceTx(ctx context.Context, tx *sql.Tx, filter internal.TransactionFilter) (int64, error) {
+	filter.Types = model.CreditTransactionTypes
+	credits, err := w.cfg.TransactionRepository.SummarizeTx(ctx, tx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	filter.Types = model.DebitTransactionTypes
+	debits, err := w.cfg.TransactionRepository.SummarizeTx(ctx, tx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return credits.Amount - debits.Amount, nil
+}
+
+// createPending stores the transaction as pending. Callers look the reference
+// up with findByReference first, when a concurrent retry still stores the
+// same reference the original transaction is returned instead, so retried
+// requests get the first result back.
+func (w *walletService) createPending(ctx context.Context, transaction model.Transaction) (model.Transaction, bool, error) {
+	err := w.cfg.TransactionRepository.Create(ctx, transaction)
+	if errors.Is(err, model.ErrDuplicateReferenceID) {
+		// a concurrent retry stored the same reference first
+		original, found, err := w.findByReference(ctx, transaction)
+		if err == nil && !found {
+			err = model.ErrDuplicateReferenceID
+		}
+		return original, found, err
+	}
+	if err != nil {
+		return model.Transaction{}, false, err
+	}
+
+	return transaction, false, nil
+}
+
+func (w *walletService) findByReference(ctx context.Context, transaction model.Transaction) (model.Transaction, bool, error) {
+	transactions, err := w.cfg.TransactionRepository.List(ctx, internal.TransactionFilter{
+		WalletIDs:    []string{transaction.WalletID.String()},
+		ReferenceIDs: []string{transaction.ReferenceID},
 	})
 	if err != nil {
-		return nil, err
+		return model.Transaction{}, false, err
+	}
+	if len(transactions) == 0 {
+		return model.Transaction{}, false, nil
+	}
+
+	original := transactions[0]
+	if original.Type != transaction.Type || original.Amount != transaction.Amount {
+		return model.Transaction{}, false, model.ErrReferenceIDConflict
 	}
 
-	return transactions, nil
+	return original, true, nil
 }
 
-func (w *walletService) Deposit(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (model.Transaction, error) {
+func (w *walletService) Deposit(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (_ model.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Deposit")
+	defer tracing.End(span, &err)
+	defer observeFailure(model.TransactionType.Deposit, &err)
+
 	wallet, err := w.Get(ctx, accountID)
 	if err != nil {
 		return model.Transaction{}, err
@@ -200,41 +468,91 @@ func (w *walletService) Deposit(ctx context.Context, accountID uuid.UUID, transa
 	transaction.TransactedAt = nil
 	transaction.Status = model.TransactionStatus.Pending
 	transaction.Type = model.TransactionType.Deposit
-	err = w.cfg.Validator.Validate(transaction)
+	err = w.validate(ctx, transaction)
 	if err != nil {
 		return model.Transaction{}, err
 	}
 
-	err = w.cfg.TransactionRepository.Create(ctx, transaction)
+	original, found, err := w.findByReference(ctx, transaction)
 	if err != nil {
 		return model.Transaction{}, err
 	}
+	if found {
+		return original, nil
+	}
 
+	err = w.checkLimits(ctx, wallet, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	original, replayed, err := w.createPending(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if replayed {
+		return original, nil
+	}
+
+	// the limits hold once checked on the locked wallet, a limit hit there
+	// fails the pending transaction and is returned after the failure is stored
+	var errLimit error
 	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		_, errIncrement := w.cfg.WalletRepository.Increment(ctx, tx, wallet, transaction.Amount)
+		errLimit = w.checkLimitsTx(ctx, tx, wallet.ID, transaction)
+		if errLimit != nil && !errors.Is(errLimit, model.ErrBussiness) {
+			return errLimit
+		}
+
+		if errLimit == nil {
+			affected, errIncrement := w.cfg.WalletRepository.Increment(
+				ctx, tx, wallet, transaction.Amount, w.cfg.Limits.MaxBalance)
+			if errIncrement != nil {
+				return errIncrement
+			}
+			if affected == 0 {
+				errLimit = model.ErrBalanceLimitExceeded
+			}
+		}
+
 		timestamp := time.Now()
 		transaction.Status = model.TransactionStatus.Success
 		transaction.TransactedAt = &timestamp
-		if errIncrement != nil {
+		if errLimit != nil {
 			transaction.Status = model.TransactionStatus.Failed
 			transaction.TransactedAt = nil
 		}
 
+		if transaction.Status == model.TransactionStatus.Success {
+			entry := journalEntry(transaction, model.LedgerAccount.CashIn, transaction.Amount, timestamp)
+			errPost := w.cfg.LedgerRepository.PostTx(ctx, tx, entry)
+			if errPost != nil {
+				return errPost
+			}
+		}
+
 		errTransaction := w.cfg.TransactionRepository.UpdateTx(ctx, tx, transaction)
 		if errTransaction != nil {
 			return errTransaction
 		}
 
-		return nil
-	})
+		return w.publishTransaction(ctx, tx, transaction)
+	}, w.txOptions()...)
 	if err != nil {
 		return model.Transaction{}, err
 	}
 
+	metrics.ObserveTransaction(transaction)
+	if errLimit != nil {
+		return model.Transaction{}, errLimit
+	}
 	return transaction, nil
 }
 
-func (w *walletService) Withdrawal(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (model.Transaction, error) {
+func (w *walletService) Withdrawal(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (_ model.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Withdrawal")
+	defer tracing.End(span, &err)
+	defer observeFailure(model.TransactionType.Withdrawal, &err)
+
 	wallet, err := w.Get(ctx, accountID)
 	if err != nil {
 		return model.Transaction{}, err
@@ -248,36 +566,954 @@ func (w *walletService) Withdrawal(ctx context.Context, accountID uuid.UUID, tra
 	transaction.TransactedAt = nil
 	transaction.Status = model.TransactionStatus.Pending
 	transaction.Type = model.TransactionType.Withdrawal
-	err = w.cfg.Validator.Validate(transaction)
+	transaction.Fee = calculateFee(w.cfg.WithdrawalFee, transaction.Amount)
+	err = w.validate(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	original, found, err := w.findByReference(ctx, transaction)
 	if err != nil {
 		return model.Transaction{}, err
 	}
+	if found {
+		return original, nil
+	}
 
-	err = w.cfg.TransactionRepository.Create(ctx, transaction)
+	err = w.checkLimits(ctx, wallet, transaction)
 	if err != nil {
 		return model.Transaction{}, err
 	}
 
+	original, replayed, err := w.createPending(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if replayed {
+		return original, nil
+	}
+
+	var errLimit error
 	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
-		affected, errDecrement := w.cfg.WalletRepository.Decrement(ctx, tx, wallet, transaction.Amount)
+		errLimit = w.checkLimitsTx(ctx, tx, wallet.ID, transaction)
+		if errLimit != nil && !errors.Is(errLimit, model.ErrBussiness) {
+			return errLimit
+		}
+
+		affected := int64(0)
+		if errLimit == nil {
+			var errDecrement error
+			affected, errDecrement = w.cfg.WalletRepository.Decrement(ctx, tx, wallet, transaction.GrossAmount())
+			if errDecrement != nil {
+				return errDecrement
+			}
+		}
+
 		timestamp := time.Now()
 		transaction.Status = model.TransactionStatus.Success
 		transaction.TransactedAt = &timestamp
-		if errDecrement != nil || affected == 0 {
+		if affected == 0 {
 			transaction.Status = model.TransactionStatus.Failed
 			transaction.TransactedAt = nil
 		}
 
+		if transaction.Status == model.TransactionStatus.Success {
+			entry := journalEntry(transaction, model.LedgerAccount.CashOut, -transaction.Amount, timestamp)
+			errPost := w.cfg.LedgerRepository.PostTx(ctx, tx, entry)
+			if errPost != nil {
+				return errPost
+			}
+
+			errFee := w.chargeFee(ctx, tx, transaction)
+			if errFee != nil {
+				return errFee
+			}
+		}
+
 		errTransaction := w.cfg.TransactionRepository.UpdateTx(ctx, tx, transaction)
 		if errTransaction != nil {
 			return errTransaction
 		}
 
-		return nil
+		return w.publishTransaction(ctx, tx, transaction)
+	}, w.txOptions()...)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	metrics.ObserveTransaction(transaction)
+	if errLimit != nil {
+		return model.Transaction{}, errLimit
+	}
+	return transaction, nil
+}
+
+func (w *walletService) Transfer(
+	ctx context.Context,
+	accountID uuid.UUID,
+	receiverWalletID uuid.UUID,
+	transaction model.Transaction) (_ model.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Transfer")
+	defer tracing.End(span, &err)
+	defer observeFailure(model.TransactionType.TransferOut, &err)
+
+	wallet, err := w.Get(ctx, accountID)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	if wallet.ID == receiverWalletID {
+		return model.Transaction{}, model.ErrTransferToOwnWallet
+	}
+
+	receiverWallet, err := w.cfg.WalletRepository.GetOne(ctx, internal.WalletFilter{
+		IDs: []string{receiverWalletID.String()},
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	if receiverWallet.Status == model.WalletStatus.Disabled {
+		return model.Transaction{}, model.ErrReceiverWalletDisabled
+	}
+
+	timestamp := time.Now()
+	transaction.ID = uuid.New()
+	transaction.WalletID = wallet.ID
+	transaction.CreatedAt = timestamp
+	transaction.UpdatedAt = timestamp
+	transaction.TransactedAt = nil
+	transaction.Status = model.TransactionStatus.Pending
+	transaction.Type = model.TransactionType.TransferOut
+	transaction.Fee = calculateFee(w.cfg.TransferFee, transaction.Amount)
+	err = w.validate(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	original, found, err := w.findByReference(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if found {
+		return original, nil
+	}
+
+	if w.cfg.Limits.exceedsMaxBalance(receiverWallet, transaction.Amount) {
+		return model.Transaction{}, model.ErrBalanceLimitExceeded
+	}
+
+	err = w.checkLimits(ctx, wallet, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	original, replayed, err := w.createPending(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if replayed {
+		return original, nil
+	}
+
+	// the credit is linked to the debit by ParentID, it gets its own reference
+	// so the sender's reference never takes one of the receiver's
+	credit := model.Transaction{
+		ID:        uuid.New(),
+		WalletID:  receiverWallet.ID,
+		Type:      model.TransactionType.TransferIn,
+		Amount:    transaction.Amount,
+		ParentID:  &transaction.ID,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+	}
+	credit.ReferenceID = credit.ID.String()
+
+	// serializable as it moves balance between two wallets, a transfer that
+	// loses against a concurrent one is run again by the TxRepository. A
+	// sender that reached its withdrawal limits or a receiver that reached its
+	// max balance meanwhile fails the transfer.
+	var errLimit error
+	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		sender, receiver, errLock := w.lockPair(ctx, tx, wallet.ID, receiverWallet.ID)
+		if errLock != nil {
+			return errLock
+		}
+
+		errLimit = w.limitsTx(ctx, tx, sender, transaction)
+		if errLimit != nil && !errors.Is(errLimit, model.ErrBussiness) {
+			return errLimit
+		}
+		if errLimit == nil && w.cfg.Limits.exceedsMaxBalance(receiver, transaction.Amount) {
+			errLimit = model.ErrBalanceLimitExceeded
+		}
+
+		affected := int64(0)
+		if errLimit == nil {
+			var errDecrement error
+			affected, errDecrement = w.cfg.WalletRepository.Decrement(ctx, tx, wallet, transaction.GrossAmount())
+			if errDecrement != nil {
+				return errDecrement
+			}
+		}
+		if affected == 0 {
+			transaction.Status = model.TransactionStatus.Failed
+			transaction.TransactedAt = nil
+			errTransaction := w.cfg.TransactionRepository.UpdateTx(ctx, tx, transaction)
+			if errTransaction != nil {
+				return errTransaction
+			}
+
+			return w.publishTransaction(ctx, tx, transaction)
+		}
+
+		affected, errIncrement := w.cfg.WalletRepository.Increment(
+			ctx, tx, receiverWallet, transaction.Amount, w.cfg.Limits.MaxBalance)
+		if errIncrement != nil {
+			return errIncrement
+		}
+		if affected == 0 {
+			return model.ErrBalanceLimitExceeded
+		}
+
+		timestamp := time.Now()
+		transaction.Status = model.TransactionStatus.Success
+		transaction.TransactedAt = &timestamp
+		credit.Status = model.TransactionStatus.Success
+		credit.TransactedAt = &timestamp
+
+		errCredit := w.cfg.TransactionRepository.CreateTx(ctx, tx, credit)
+		if errCredit != nil {
+			return errCredit
+		}
+
+		errPublish := w.publishTransaction(ctx, tx, credit)
+		if errPublish != nil {
+			return errPublish
+		}
+
+		// both sides settle through the clearing account, one entry per transaction
+		entries := []model.JournalEntry{
+			journalEntry(transaction, model.LedgerAccount.TransferClearing, -transaction.Amount, timestamp),
+			journalEntry(credit, model.LedgerAccount.TransferClearing, credit.Amount, timestamp),
+		}
+		for _, entry := range entries {
+			errPost := w.cfg.LedgerRepository.PostTx(ctx, tx, entry)
+			if errPost != nil {
+				return errPost
+			}
+		}
+
+		errFee := w.chargeFee(ctx, tx, transaction)
+		if errFee != nil {
+			return errFee
+		}
+
+		errTransaction := w.cfg.TransactionRepository.UpdateTx(ctx, tx, transaction)
+		if errTransaction != nil {
+			return errTransaction
+		}
+
+		return w.publishTransaction(ctx, tx, transaction)
+	}, w.txOptions(internal.WithIsolation(sql.LevelSerializable))...)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	metrics.ObserveTransaction(transaction)
+	if errLimit != nil {
+		return model.Transaction{}, errLimit
+	}
+	return transaction, nil
+}
+
+// Refund gives back a successful deposit or withdrawal, a zero amount refunds
+// whatever is left. A withdrawal is refunded into the wallet and a deposit is
+// reversed out of it, the new transaction is linked to the original by ParentID.
+func (w *walletService) Refund(
+	ctx context.Context,
+	accountID uuid.UUID,
+	transactionID uuid.UUID,
+	amount int64) (_ model.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Refund")
+	defer tracing.End(span, &err)
+
+	wallet, err := w.Get(ctx, accountID)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	transactions, err := w.cfg.TransactionRepository.List(ctx, internal.TransactionFilter{
+		IDs:       []string{transactionID.String()},
+		WalletIDs: []string{wallet.ID.String()},
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if len(transactions) == 0 {
+		return model.Transaction{}, sql.ErrNoRows
+	}
+
+	original := transactions[0]
+	refundable := original.RefundableAmount()
+	if refundable <= 0 {
+		return model.Transaction{}, model.ErrNotRefundable
+	}
+	if amount == 0 {
+		amount = refundable
+	}
+	if amount > refundable {
+		return model.Transaction{}, model.ErrRefundExceedsRemaining
+	}
+
+	timestamp := time.Now()
+	refund := model.Transaction{
+		ID:           uuid.New(),
+		WalletID:     wallet.ID,
+		Type:         model.TransactionType.Refund,
+		Status:       model.TransactionStatus.Success,
+		TransactedAt: &timestamp,
+		Amount:       amount,
+		ParentID:     &original.ID,
+		CreatedAt:    timestamp,
+		UpdatedAt:    timestamp,
+	}
+	// a partial refund can happen many times, so each one gets its own reference
+	refund.ReferenceID = refund.ID.String()
+	counterAccount := model.LedgerAccount.CashOut
+	walletAmount := amount
+	if original.Type == model.TransactionType.Deposit {
+		refund.Type = model.TransactionType.Reversal
+		counterAccount = model.LedgerAccount.CashIn
+		walletAmount = -amount
+	}
+	err = w.validate(ctx, refund)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	original.UpdatedAt = timestamp
+	wallet.UpdatedAt = timestamp
+	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		affected, errRefunded := w.cfg.TransactionRepository.AddRefundedTx(ctx, tx, original, amount)
+		if errRefunded != nil {
+			return errRefunded
+		}
+		if affected == 0 {
+			// refunded concurrently
+			return model.ErrRefundExceedsRemaining
+		}
+
+		if walletAmount > 0 {
+			affected, errIncrement := w.cfg.WalletRepository.Increment(ctx, tx, wallet, amount, w.cfg.Limits.MaxBalance)
+			if errIncrement != nil {
+				return errIncrement
+			}
+			if affected == 0 {
+				return model.ErrBalanceLimitExceeded
+			}
+		} else {
+			affected, errDecrement := w.cfg.WalletRepository.Decrement(ctx, tx, wallet, amount)
+			if errDecrement != nil {
+				return errDecrement
+			}
+			if affected == 0 {
+				return model.ErrInsufficientBalance
+			}
+		}
+
+		errTransaction := w.cfg.TransactionRepository.CreateTx(ctx, tx, refund)
+		if errTransaction != nil {
+			return errTransaction
+		}
+
+		entry := journalEntry(refund, counterAccount, walletAmount, timestamp)
+		errPost := w.cfg.LedgerRepository.PostTx(ctx, tx, entry)
+		if errPost != nil {
+			return errPost
+		}
+
+		return w.publishTransaction(ctx, tx, refund)
+	}, w.txOptions()...)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	return refund, nil
+}
+
+// Adjust posts a manual correction made by an operator, AdjustmentCredit
+// adds the amount and AdjustmentDebit takes it off. The reason and the
+// operator of ctx are stored with the transaction, fees and limits other
+// than the max balance do not apply and disabled wallets can be adjusted
+// too. A retried reference returns the first adjustment.
+func (w *walletService) Adjust(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (_ model.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Adjust")
+	defer tracing.End(span, &err)
+
+	walletAmount := transaction.Amount
+	switch transaction.Type {
+	case model.TransactionType.AdjustmentCredit:
+	case model.TransactionType.AdjustmentDebit:
+		walletAmount = -transaction.Amount
+	default:
+		return model.Transaction{}, model.ErrNotAnAdjustment
+	}
+	if strings.TrimSpace(transaction.Reason) == "" {
+		return model.Transaction{}, model.ErrAdjustmentNoReason
+	}
+	transaction.Operator = strings.TrimSpace(util.OperatorFromContext(ctx))
+	if transaction.Operator == "" {
+		return model.Transaction{}, model.ErrAdjustmentNoOperator
+	}
+
+	wallet, err := w.cfg.WalletRepository.GetOne(ctx, internal.WalletFilter{
+		OwnedBies: []string{accountID.String()},
+	})
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	timestamp := time.Now()
+	transaction.ID = uuid.New()
+	transaction.WalletID = wallet.ID
+	transaction.Status = model.TransactionStatus.Success
+	transaction.TransactedAt = &timestamp
+	transaction.Fee = 0
+	transaction.ParentID = nil
+	transaction.RefundedAmount = 0
+	transaction.CreatedAt = timestamp
+	transaction.UpdatedAt = timestamp
+	if transaction.ReferenceID == "" {
+		transaction.ReferenceID = transaction.ID.String()
+	}
+	err = w.validate(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	original, found, err := w.findByReference(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+	if found {
+		return original, nil
+	}
+
+	wallet.UpdatedAt = timestamp
+	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if walletAmount > 0 {
+			affected, errIncrement := w.cfg.WalletRepository.Increment(
+				ctx, tx, wallet, transaction.Amount, w.cfg.Limits.MaxBalance)
+			if errIncrement != nil {
+				return errIncrement
+			}
+			if affected == 0 {
+				return model.ErrBalanceLimitExceeded
+			}
+		} else {
+			affected, errDecrement := w.cfg.WalletRepository.Decrement(ctx, tx, wallet, transaction.Amount)
+			if errDecrement != nil {
+				return errDecrement
+			}
+			if affected == 0 {
+				return model.ErrInsufficientBalance
+			}
+		}
+
+		errTransaction := w.cfg.TransactionRepository.CreateTx(ctx, tx, transaction)
+		if errTransaction != nil {
+			return errTransaction
+		}
+
+		entry := journalEntry(transaction, model.LedgerAccount.Adjustments, walletAmount, timestamp)
+		errPost := w.cfg.LedgerRepository.PostTx(ctx, tx, entry)
+		if errPost != nil {
+			return errPost
+		}
+
+		return w.publishTransaction(ctx, tx, transaction)
+	}, w.txOptions()...)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+func (w *walletService) Authorize(ctx context.Context, accountID uuid.UUID, hold model.Hold) (_ model.Hold, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Authorize")
+	defer tracing.End(span, &err)
+
+	wallet, err := w.Get(ctx, accountID)
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	holdExpiry := w.cfg.HoldExpiry
+	if holdExpiry <= 0 {
+		holdExpiry = defaultHoldExpiry
+	}
+
+	timestamp := time.Now()
+	hold.ID = uuid.New()
+	hold.WalletID = wallet.ID
+	hold.CapturedAmount = 0
+	hold.Status = model.HoldStatus.Authorized
+	hold.ExpiresAt = timestamp.Add(holdExpiry)
+	hold.CreatedAt = timestamp
+	hold.UpdatedAt = timestamp
+	err = w.validate(ctx, hold)
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	existing, err := w.cfg.HoldRepository.GetOne(ctx, internal.HoldFilter{
+		WalletIDs:    []string{wallet.ID.String()},
+		ReferenceIDs: []string{hold.ReferenceID},
+	})
+	if err == nil {
+		if existing.Amount != hold.Amount {
+			return model.Hold{}, model.ErrReferenceIDConflict
+		}
+		return existing, nil
+	}
+	if err != sql.ErrNoRows {
+		return model.Hold{}, err
+	}
+
+	wallet.UpdatedAt = timestamp
+	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		affected, errReserve := w.cfg.WalletRepository.Reserve(ctx, tx, wallet, hold.Amount)
+		if errReserve != nil {
+			return errReserve
+		}
+		if affected == 0 {
+			return model.ErrInsufficientBalance
+		}
+
+		return w.cfg.HoldRepository.CreateTx(ctx, tx, hold)
+	}, w.txOptions()...)
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// Capture settles an authorized hold, a zero amount captures the whole hold.
+// Whatever is not captured goes back to the available balance.
+func (w *walletService) Capture(
+	ctx context.Context,
+	accountID uuid.UUID,
+	holdID uuid.UUID,
+	amount int64) (_ model.Transaction, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Capture")
+	defer tracing.End(span, &err)
+
+	wallet, hold, err := w.getHold(ctx, accountID, holdID)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	if amount == 0 {
+		amount = hold.Amount
+	}
+	if amount > hold.Amount {
+		return model.Transaction{}, model.ErrCaptureExceedsHold
+	}
+
+	timestamp := time.Now()
+	transaction := model.Transaction{
+		ID:           uuid.New(),
+		WalletID:     wallet.ID,
+		Type:         model.TransactionType.Capture,
+		Status:       model.TransactionStatus.Success,
+		TransactedAt: &timestamp,
+		Amount:       amount,
+		ReferenceID:  captureReference(hold),
+		CreatedAt:    timestamp,
+		UpdatedAt:    timestamp,
+	}
+	err = w.validate(ctx, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	err = w.checkLimits(ctx, wallet, transaction)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	hold.Status = model.HoldStatus.Captured
+	hold.CapturedAmount = amount
+	hold.UpdatedAt = timestamp
+	wallet.UpdatedAt = timestamp
+	err = w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		affected, errHold := w.cfg.HoldRepository.UpdateTx(ctx, tx, hold)
+		if errHold != nil {
+			return errHold
+		}
+		if affected == 0 {
+			return model.ErrHoldNotAuthorized
+		}
+
+		// a capture over the withdrawal limits leaves the hold authorized
+		errLimit := w.checkLimitsTx(ctx, tx, wallet.ID, transaction)
+		if errLimit != nil {
+			return errLimit
+		}
+
+		affected, errCapture := w.cfg.WalletRepository.Capture(ctx, tx, wallet, hold.Amount, amount)
+		if errCapture != nil {
+			return errCapture
+		}
+		if affected == 0 {
+			return model.ErrReservedMismatch
+		}
+
+		errTransaction := w.cfg.TransactionRepository.CreateTx(ctx, tx, transaction)
+		if errTransaction != nil {
+			return errTransaction
+		}
+
+		entry := journalEntry(transaction, model.LedgerAccount.CashOut, -amount, timestamp)
+		errPost := w.cfg.LedgerRepository.PostTx(ctx, tx, entry)
+		if errPost != nil {
+			return errPost
+		}
+
+		return w.publishTransaction(ctx, tx, transaction)
+	}, w.txOptions()...)
+	if err != nil {
+		return model.Transaction{}, err
+	}
+
+	return transaction, nil
+}
+
+// captureReference links the capture to its hold, the reference of the hold
+// itself stays free for the client to use on another transaction.
+func captureReference(hold model.Hold) string {
+	return "hold:" + hold.ID.String()
+}
+
+func (w *walletService) Void(ctx context.Context, accountID uuid.UUID, holdID uuid.UUID) (_ model.Hold, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.Void")
+	defer tracing.End(span, &err)
+
+	wallet, hold, err := w.getHold(ctx, accountID, holdID)
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	return w.releaseHold(ctx, wallet, hold, model.HoldStatus.Voided)
+}
+
+// ExpireHolds releases the reserved funds of holds that expired before now
+// and returns how many were released.
+func (w *walletService) ExpireHolds(ctx context.Context, now time.Time) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.ExpireHolds")
+	defer tracing.End(span, &err)
+
+	holds, err := w.cfg.HoldRepository.List(ctx, internal.HoldFilter{
+		Statuses:      []string{model.HoldStatus.Authorized},
+		ExpiresBefore: &now,
+		Limit:         expireHoldBatchSize,
 	})
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, hold := range holds {
+		_, err := w.releaseHold(ctx, model.Wallet{ID: hold.WalletID}, hold, model.HoldStatus.Expired)
+		if errors.Is(err, model.ErrHoldNotAuthorized) {
+			// settled while we were sweeping
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// RunHoldExpiry sweeps expired holds every interval until ctx is done.
+func (w *walletService) RunHoldExpiry(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultHoldSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := w.ExpireHolds(ctx, now); err != nil {
+				log.Printf("failed expire holds : %s \n", err)
+			}
+		}
+	}
+}
+
+// RecoverPending resolves transactions left pending for longer than
+// PendingRecoveryAge before now, which happens when the process stops between
+// storing the pending row and settling it. The ledger decides the outcome,
+// a journal entry means the money moved and the transaction succeeded, no
+// entry means nothing moved and it failed. It returns how many were resolved.
+func (w *walletService) RecoverPending(ctx context.Context, now time.Time) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "walletService.RecoverPending")
+	defer tracing.End(span, &err)
+
+	age := w.cfg.PendingRecoveryAge
+	if age <= 0 {
+		age = defaultPendingRecoveryAge
+	}
+
+	createdBefore := now.Add(-age)
+	transactions, err := w.cfg.TransactionRepository.List(ctx, internal.TransactionFilter{
+		Statuses:      []string{model.TransactionStatus.Pending},
+		CreatedBefore: &createdBefore,
+		Limit:         recoverPendingBatchSize,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	for _, transaction := range transactions {
+		resolved, err := w.resolvePending(ctx, transaction, now)
+		if errors.Is(err, model.ErrInvalidStatusTransition) {
+			// settled while we were sweeping
+			continue
+		}
+		if err != nil {
+			return recovered, err
+		}
+
+		log.Printf("recovered pending transaction %s as %s \n", resolved.ID, resolved.Status)
+		recovered++
+	}
+
+	return recovered, nil
+}
+
+// RunPendingRecovery resolves stuck pending transactions every interval
+// until ctx is done.
+func (w *walletService) RunPendingRecovery(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultPendingRecoveryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := w.RecoverPending(ctx, now); err != nil {
+				log.Printf("failed recover pending transactions : %s \n", err)
+			}
+		}
+	}
+}
+
+func (w *walletService) resolvePending(ctx context.Context, transaction model.Transaction, now time.Time) (model.Transaction, error) {
+	transaction.UpdatedAt = now
+	err := w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		entry, errEntry := w.cfg.LedgerRepository.GetEntryTx(ctx, tx, transaction.ID)
+		switch {
+		case errEntry == nil:
+			// the balance moved, only the status update was lost
+			transactedAt := entry.CreatedAt
+			transaction.Status = model.TransactionStatus.Success
+			transaction.TransactedAt = &transactedAt
+		case errors.Is(errEntry, sql.ErrNoRows):
+			transaction.Status = model.TransactionStatus.Failed
+			transaction.TransactedAt = nil
+		default:
+			return errEntry
+		}
+
+		errTransaction := w.cfg.TransactionRepository.UpdateTx(ctx, tx, transaction)
+		if errTransaction != nil {
+			return errTransaction
+		}
+
+		return w.publishTransaction(ctx, tx, transaction)
+	}, w.txOptions()...)
 	if err != nil {
 		return model.Transaction{}, err
 	}
 
 	return transaction, nil
 }
+
+// getHold loads an authorized hold of the account's wallet, a hold found
+// past its expiry is released on the spot.
+func (w *walletService) getHold(ctx context.Context, accountID uuid.UUID, holdID uuid.UUID) (model.Wallet, model.Hold, error) {
+	wallet, err := w.Get(ctx, accountID)
+	if err != nil {
+		return model.Wallet{}, model.Hold{}, err
+	}
+
+	hold, err := w.cfg.HoldRepository.GetOne(ctx, internal.HoldFilter{
+		IDs:       []string{holdID.String()},
+		WalletIDs: []string{wallet.ID.String()},
+	})
+	if err != nil {
+		return model.Wallet{}, model.Hold{}, err
+	}
+
+	if hold.IsExpired(time.Now()) {
+		_, err = w.releaseHold(ctx, wallet, hold, model.HoldStatus.Expired)
+		if err != nil {
+			return model.Wallet{}, model.Hold{}, err
+		}
+		return model.Wallet{}, model.Hold{}, model.ErrHoldExpired
+	}
+
+	if hold.Status != model.HoldStatus.Authorized {
+		return model.Wallet{}, model.Hold{}, model.ErrHoldNotAuthorized
+	}
+
+	return wallet, hold, nil
+}
+
+func (w *walletService) releaseHold(
+	ctx context.Context,
+	wallet model.Wallet,
+	hold model.Hold,
+	status string) (model.Hold, error) {
+	timestamp := time.Now()
+	hold.Status = status
+	hold.UpdatedAt = timestamp
+	wallet.UpdatedAt = timestamp
+	err := w.cfg.TxRepository.Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		affected, errHold := w.cfg.HoldRepository.UpdateTx(ctx, tx, hold)
+		if errHold != nil {
+			return errHold
+		}
+		if affected == 0 {
+			return model.ErrHoldNotAuthorized
+		}
+
+		affected, errRelease := w.cfg.WalletRepository.Release(ctx, tx, wallet, hold.Amount)
+		if errRelease != nil {
+			return errRelease
+		}
+		if affected == 0 {
+			return model.ErrReservedMismatch
+		}
+
+		return nil
+	}, w.txOptions()...)
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+func calculateFee(calculator internal.FeeCalculator, amount int64) int64 {
+	if calculator == nil {
+		return 0
+	}
+
+	return calculator.Calculate(amount)
+}
+
+// lockPair locks both wallets in the order of their ids, so transfers between
+// the same wallets in opposite directions can not deadlock.
+func (w *walletService) lockPair(ctx context.Context, tx *sql.Tx, a uuid.UUID, b uuid.UUID) (model.Wallet, model.Wallet, error) {
+	if b.String() < a.String() {
+		second, first, err := w.lockPair(ctx, tx, b, a)
+		return first, second, err
+	}
+
+	first, err := w.cfg.WalletRepository.LockTx(ctx, tx, a)
+	if err != nil {
+		return model.Wallet{}, model.Wallet{}, err
+	}
+	second, err := w.cfg.WalletRepository.LockTx(ctx, tx, b)
+	if err != nil {
+		return model.Wallet{}, model.Wallet{}, err
+	}
+
+	return first, second, nil
+}
+
+// txOptions adds the configured timeout to opts.
+func (w *walletService) txOptions(opts ...internal.TxOption) []internal.TxOption {
+	return append(opts, internal.WithTimeout(w.cfg.TxTimeout))
+}
+
+// chargeFee records the fee of a settled transaction as its own fee
+// transaction linked to it, the wallet was already debited for the gross
+// amount.
+func (w *walletService) chargeFee(ctx context.Context, tx *sql.Tx, parent model.Transaction) error {
+	if parent.Fee <= 0 {
+		return nil
+	}
+
+	timestamp := *parent.TransactedAt
+	fee := model.Transaction{
+		ID:           uuid.New(),
+		WalletID:     parent.WalletID,
+		Type:         model.TransactionType.Fee,
+		Status:       model.TransactionStatus.Success,
+		TransactedAt: &timestamp,
+		Amount:       parent.Fee,
+		ParentID:     &parent.ID,
+		CreatedAt:    timestamp,
+		UpdatedAt:    timestamp,
+	}
+	fee.ReferenceID = fee.ID.String()
+
+	err := w.cfg.TransactionRepository.CreateTx(ctx, tx, fee)
+	if err != nil {
+		return err
+	}
+
+	entry := journalEntry(fee, model.LedgerAccount.Fees, -fee.Amount, timestamp)
+	err = w.cfg.LedgerRepository.PostTx(ctx, tx, entry)
+	if err != nil {
+		return err
+	}
+
+	return w.publishTransaction(ctx, tx, fee)
+}
+
+// journalEntry books walletAmount on the wallet of the transaction and the
+// opposite amount on the counter account, so the entry is always balanced.
+func journalEntry(
+	transaction model.Transaction,
+	counterAccount string,
+	walletAmount int64,
+	timestamp time.Time) model.JournalEntry {
+	walletID := transaction.WalletID
+	return model.JournalEntry{
+		ID:            uuid.New(),
+		TransactionID: transaction.ID,
+		CreatedAt:     timestamp,
+		Postings: []model.Posting{
+			{
+				ID:       uuid.New(),
+				Account:  model.LedgerAccount.Wallet,
+				WalletID: &walletID,
+				Amount:   walletAmount,
+			},
+			{
+				ID:      uuid.New(),
+				Account: counterAccount,
+				Amount:  -walletAmount,
+			},
+		},
+	}
+}
@@ -3,14 +3,17 @@ package wallet
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/hokdre/mini-ewallet/internal"
 	"github.com/hokdre/mini-ewallet/internal/model"
 	mock "github.com/hokdre/mini-ewallet/pkg/mocks"
+	"github.com/hokdre/mini-ewallet/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,7 +23,15 @@ func TestWalletService(t *testing.T) {
 	t.Run("Disable", TestDisable)
 	t.Run("Get", TestWalletService_Get)
 	t.Run("GetTransaction", TestGetTransaction)
+	t.Run("Statement", TestStatement)
 	t.Run("Deposit", TestDeposit)
+	t.Run("Transfer", TestTransfer)
+	t.Run("Idempotency", TestIdempotency)
+	t.Run("Hold", TestHold)
+	t.Run("Refund", TestRefund)
+	t.Run("Adjust", TestAdjust)
+	t.Run("RecoverPending", TestRecoverPending)
+	t.Run("Events", TestEvents)
 }
 
 func TestInit(t *testing.T) {
@@ -36,7 +47,7 @@ func TestInit(t *testing.T) {
 		}
 		token, err := w.Init(context.Background(), "")
 		assert.Error(t, err)
-		assert.Equal(t, "", token)
+		assert.Equal(t, model.TokenPair{}, token)
 	})
 
 	t.Run("failed check account", func(t *testing.T) {
@@ -60,7 +71,7 @@ func TestInit(t *testing.T) {
 		}
 		token, err := w.Init(context.Background(), externalId)
 		assert.Error(t, err, errExpected)
-		assert.Equal(t, "", token)
+		assert.Equal(t, model.TokenPair{}, token)
 	})
 
 	t.Run("failed create account", func(t *testing.T) {
@@ -72,7 +83,7 @@ func TestInit(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -88,14 +99,15 @@ func TestInit(t *testing.T) {
 
 		w := &walletService{
 			cfg: Config{
-				Validator:    validator,
-				AccountRepo:  accountRepo,
-				TxRepository: txRepo,
+				Validator:        validator,
+				AccountRepo:      accountRepo,
+				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
 			},
 		}
 		token, err := w.Init(context.Background(), externalId)
 		assert.Error(t, err, errExpected)
-		assert.Equal(t, "", token)
+		assert.Equal(t, model.TokenPair{}, token)
 	})
 
 	t.Run("failed create wallet", func(t *testing.T) {
@@ -107,7 +119,7 @@ func TestInit(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -132,12 +144,13 @@ func TestInit(t *testing.T) {
 				Validator:        validator,
 				AccountRepo:      accountRepo,
 				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
 				WalletRepository: walletRepo,
 			},
 		}
 		token, err := w.Init(context.Background(), externalId)
 		assert.Error(t, err, errExpected)
-		assert.Equal(t, "", token)
+		assert.Equal(t, model.TokenPair{}, token)
 	})
 
 	t.Run("failed create wallet", func(t *testing.T) {
@@ -149,7 +162,7 @@ func TestInit(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -174,12 +187,13 @@ func TestInit(t *testing.T) {
 				Validator:        validator,
 				AccountRepo:      accountRepo,
 				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
 				WalletRepository: walletRepo,
 			},
 		}
 		token, err := w.Init(context.Background(), externalId)
 		assert.Error(t, err, errExpected)
-		assert.Equal(t, "", token)
+		assert.Equal(t, model.TokenPair{}, token)
 	})
 
 	t.Run("failed create token", func(t *testing.T) {
@@ -191,7 +205,7 @@ func TestInit(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -212,33 +226,34 @@ func TestInit(t *testing.T) {
 			gomock.Any(),
 		).Return(nil).Times(1)
 
-		encryption := mock.NewMockEncryption(ctrl)
-		encryption.EXPECT().Encrypt(gomock.Any()).Return("", errExpected).Times(1)
+		tokenService := mock.NewMockTokenService(ctrl)
+		tokenService.EXPECT().Issue(gomock.Any(), gomock.Any()).Return(model.TokenPair{}, errExpected).Times(1)
 
 		w := &walletService{
 			cfg: Config{
 				Validator:        validator,
 				AccountRepo:      accountRepo,
 				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
 				WalletRepository: walletRepo,
-				Encryption:       encryption,
+				TokenService:     tokenService,
 			},
 		}
 		token, err := w.Init(context.Background(), externalId)
 		assert.Error(t, err, errExpected)
-		assert.Equal(t, "", token)
+		assert.Equal(t, model.TokenPair{}, token)
 	})
 
 	t.Run("Success New Register", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		externalId := uuid.New().String()
-		token := "token"
+		tokens := model.TokenPair{AccessToken: "token", RefreshToken: "refresh"}
 
 		validator := mock.NewMockValidator(ctrl)
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -259,27 +274,28 @@ func TestInit(t *testing.T) {
 			gomock.Any(),
 		).Return(nil).Times(1)
 
-		encryption := mock.NewMockEncryption(ctrl)
-		encryption.EXPECT().Encrypt(gomock.Any()).Return(token, nil).Times(1)
+		tokenService := mock.NewMockTokenService(ctrl)
+		tokenService.EXPECT().Issue(gomock.Any(), gomock.Any()).Return(tokens, nil).Times(1)
 
 		w := &walletService{
 			cfg: Config{
 				Validator:        validator,
 				AccountRepo:      accountRepo,
 				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
 				WalletRepository: walletRepo,
-				Encryption:       encryption,
+				TokenService:     tokenService,
 			},
 		}
 		res, err := w.Init(context.Background(), externalId)
 		assert.NoError(t, err)
-		assert.Equal(t, token, res)
+		assert.Equal(t, tokens, res)
 	})
 
 	t.Run("Success old register", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		externalId := uuid.New().String()
-		token := "token"
+		tokens := model.TokenPair{AccessToken: "token", RefreshToken: "refresh"}
 
 		validator := mock.NewMockValidator(ctrl)
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
@@ -295,21 +311,22 @@ func TestInit(t *testing.T) {
 
 		walletRepo := mock.NewMockWalletRepository(ctrl)
 
-		encryption := mock.NewMockEncryption(ctrl)
-		encryption.EXPECT().Encrypt(gomock.Any()).Return(token, nil).Times(1)
+		tokenService := mock.NewMockTokenService(ctrl)
+		tokenService.EXPECT().Issue(gomock.Any(), gomock.Any()).Return(tokens, nil).Times(1)
 
 		w := &walletService{
 			cfg: Config{
 				Validator:        validator,
 				AccountRepo:      accountRepo,
 				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
 				WalletRepository: walletRepo,
-				Encryption:       encryption,
+				TokenService:     tokenService,
 			},
 		}
 		res, err := w.Init(context.Background(), externalId)
 		assert.NoError(t, err)
-		assert.Equal(t, token, res)
+		assert.Equal(t, tokens, res)
 	})
 }
 
@@ -329,11 +346,34 @@ func TestEnable(t *testing.T) {
 				WalletRepository: walletRepo,
 			},
 		}
-		res, err := w.Enable(context.Background(), accountID)
+		res, err := w.Enable(context.Background(), accountID, 0)
 		assert.Error(t, err)
 		assert.Equal(t, model.Wallet{}, res)
 	})
 
+	t.Run("Failed version mismatch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			Status:  model.WalletStatus.Disabled,
+			Version: 3,
+		}
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+			},
+		}
+		res, err := w.Enable(context.Background(), accountID, 2)
+		assert.ErrorIs(t, err, model.ErrWalletVersionConflict)
+		assert.Equal(t, model.Wallet{}, res)
+	})
+
 	t.Run("Failed status already enable", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountID := uuid.New()
@@ -351,7 +391,7 @@ func TestEnable(t *testing.T) {
 				WalletRepository: walletRepo,
 			},
 		}
-		res, err := w.Enable(context.Background(), accountID)
+		res, err := w.Enable(context.Background(), accountID, 0)
 		assert.Error(t, err, model.ErrWalletAlreadyEnabled)
 		assert.Equal(t, model.Wallet{}, res)
 	})
@@ -369,13 +409,20 @@ func TestEnable(t *testing.T) {
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
 
-		walletRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errExpected).Times(1)
+		walletRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(errExpected).Times(1)
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
 		w := &walletService{
 			cfg: Config{
 				WalletRepository: walletRepo,
+				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
 			},
 		}
-		res, err := w.Enable(context.Background(), accountID)
+		res, err := w.Enable(context.Background(), accountID, 0)
 		assert.Error(t, err, errExpected)
 		assert.Equal(t, model.Wallet{}, res)
 	})
@@ -385,22 +432,53 @@ func TestEnable(t *testing.T) {
 		accountID := uuid.New()
 
 		wallet := model.Wallet{
-			Status: model.WalletStatus.Disabled,
+			Status:  model.WalletStatus.Disabled,
+			Version: 3,
 		}
 		walletRepo := mock.NewMockWalletRepository(ctrl)
 		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
 
-		walletRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		walletRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, updated model.Wallet) error {
+				// the update is conditional on the version read
+				assert.Equal(t, int64(3), updated.Version)
+				return nil
+			}).Times(1)
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, event model.Event) error {
+				assert.Equal(t, model.EventType.WalletEnabled, event.Type)
+				assert.Equal(t, model.EventStatus.Pending, event.Status)
+				return nil
+			}).Times(1)
+
+		auditRepo := mock.NewMockAuditRepository(ctrl)
+		auditRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error {
+				assert.Equal(t, model.AuditAction.WalletEnabled, entry.Action)
+				assert.Equal(t, "ops", entry.Operator)
+				return nil
+			}).Times(1)
+
 		w := &walletService{
 			cfg: Config{
 				WalletRepository: walletRepo,
+				TxRepository:     txRepo,
+				OutboxRepository: outboxRepo,
+				AuditRepository:  auditRepo,
 			},
 		}
-		res, err := w.Enable(context.Background(), accountID)
+		res, err := w.Enable(util.ContextWithOperator(context.Background(), "ops"), accountID, 3)
 		assert.NoError(t, err)
 		assert.Equal(t, res.Status, model.WalletStatus.Enabled)
+		assert.Equal(t, int64(4), res.Version)
 	})
 }
 
@@ -420,11 +498,65 @@ func TestDisable(t *testing.T) {
 				WalletRepository: walletRepo,
 			},
 		}
-		res, err := w.Disable(context.Background(), accountID)
+		res, err := w.Disable(context.Background(), accountID, 0)
 		assert.Error(t, err)
 		assert.Equal(t, model.Wallet{}, res)
 	})
 
+	t.Run("Failed version mismatch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			Status:  model.WalletStatus.Enabled,
+			Version: 3,
+		}
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+			},
+		}
+		res, err := w.Disable(context.Background(), accountID, 2)
+		assert.ErrorIs(t, err, model.ErrWalletVersionConflict)
+		assert.Equal(t, model.Wallet{}, res)
+	})
+
+	t.Run("Failed concurrent update", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			Status:  model.WalletStatus.Enabled,
+			Version: 3,
+		}
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		walletRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(model.ErrWalletVersionConflict).Times(1)
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Disable(context.Background(), accountID, 0)
+		assert.ErrorIs(t, err, model.ErrWalletVersionConflict)
+		assert.Equal(t, model.Wallet{}, res)
+	})
+
 	t.Run("Failed status already disabled", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		accountID := uuid.New()
@@ -442,7 +574,7 @@ func TestDisable(t *testing.T) {
 				WalletRepository: walletRepo,
 			},
 		}
-		res, err := w.Disable(context.Background(), accountID)
+		res, err := w.Disable(context.Background(), accountID, 0)
 		assert.Error(t, err, model.ErrWalletAlreadyDisabled)
 		assert.Equal(t, model.Wallet{}, res)
 	})
@@ -460,13 +592,20 @@ func TestDisable(t *testing.T) {
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
 
-		walletRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errExpected).Times(1)
+		walletRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(errExpected).Times(1)
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
 		w := &walletService{
 			cfg: Config{
 				WalletRepository: walletRepo,
+				TxRepository:     txRepo,
+				OutboxRepository: anyOutboxRepository(ctrl),
 			},
 		}
-		res, err := w.Disable(context.Background(), accountID)
+		res, err := w.Disable(context.Background(), accountID, 0)
 		assert.Error(t, err, errExpected)
 		assert.Equal(t, model.Wallet{}, res)
 	})
@@ -483,13 +622,38 @@ func TestDisable(t *testing.T) {
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
 
-		walletRepo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		walletRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, event model.Event) error {
+				assert.Equal(t, model.EventType.WalletDisabled, event.Type)
+				assert.Equal(t, model.EventStatus.Pending, event.Status)
+				return nil
+			}).Times(1)
+
+		auditRepo := mock.NewMockAuditRepository(ctrl)
+		auditRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error {
+				// no operator acted, the account holder did
+				assert.Equal(t, model.AuditAction.WalletDisabled, entry.Action)
+				assert.Equal(t, "", entry.Operator)
+				return nil
+			}).Times(1)
+
 		w := &walletService{
 			cfg: Config{
 				WalletRepository: walletRepo,
+				TxRepository:     txRepo,
+				OutboxRepository: outboxRepo,
+				AuditRepository:  auditRepo,
 			},
 		}
-		res, err := w.Disable(context.Background(), accountID)
+		res, err := w.Disable(context.Background(), accountID, 0)
 		assert.NoError(t, err)
 		assert.Equal(t, res.Status, model.WalletStatus.Disabled)
 	})
@@ -524,7 +688,8 @@ func TestWalletService_Get(t *testing.T) {
 		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
 			OwnedBies: []string{accountID.String()},
 		}).Return(model.Wallet{
-			Status: model.WalletStatus.Disabled,
+			Status:  model.WalletStatus.Disabled,
+			Version: 3,
 		}, nil).Times(1)
 
 		w := &walletService{
@@ -533,8 +698,8 @@ func TestWalletService_Get(t *testing.T) {
 			},
 		}
 		res, err := w.Get(context.Background(), accountID)
-		assert.Error(t, err, model.ErrWalletDisabled)
-		assert.Equal(t, model.Wallet{}, res)
+		assert.ErrorIs(t, err, model.ErrWalletDisabled)
+		assert.Equal(t, model.Wallet{Status: model.WalletStatus.Disabled, Version: 3}, res)
 	})
 
 	t.Run("Success", func(t *testing.T) {
@@ -578,7 +743,7 @@ func TestGetTransaction(t *testing.T) {
 				WalletRepository: walletRepo,
 			},
 		}
-		res, err := w.GetTransactions(context.Background(), accountID)
+		res, _, err := w.GetTransactions(context.Background(), accountID, internal.TransactionFilter{})
 		assert.Error(t, err, errExpected)
 		assert.Nil(t, res)
 	})
@@ -602,7 +767,7 @@ func TestGetTransaction(t *testing.T) {
 				WalletRepository: walletRepo,
 			},
 		}
-		res, err := w.GetTransactions(context.Background(), accountID)
+		res, _, err := w.GetTransactions(context.Background(), accountID, internal.TransactionFilter{})
 		assert.Error(t, err, model.ErrWalletDisabled)
 		assert.Nil(t, res)
 	})
@@ -626,6 +791,7 @@ func TestGetTransaction(t *testing.T) {
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
 		transactionRepo.EXPECT().List(gomock.Any(), internal.TransactionFilter{
 			WalletIDs: []string{wallet.ID.String()},
+			Limit:     defaultTransactionLimit + 1,
 		}).Return(nil, errExpect).Times(1)
 		w := &walletService{
 			cfg: Config{
@@ -633,7 +799,7 @@ func TestGetTransaction(t *testing.T) {
 				TransactionRepository: transactionRepo,
 			},
 		}
-		res, err := w.GetTransactions(context.Background(), accountID)
+		res, _, err := w.GetTransactions(context.Background(), accountID, internal.TransactionFilter{})
 		assert.Error(t, err, errExpect)
 		assert.Nil(t, res)
 	})
@@ -657,6 +823,7 @@ func TestGetTransaction(t *testing.T) {
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
 		transactionRepo.EXPECT().List(gomock.Any(), internal.TransactionFilter{
 			WalletIDs: []string{wallet.ID.String()},
+			Limit:     defaultTransactionLimit + 1,
 		}).Return(transactions, nil).Times(1)
 		w := &walletService{
 			cfg: Config{
@@ -664,11 +831,265 @@ func TestGetTransaction(t *testing.T) {
 				TransactionRepository: transactionRepo,
 			},
 		}
-		res, err := w.GetTransactions(context.Background(), accountID)
+		res, _, err := w.GetTransactions(context.Background(), accountID, internal.TransactionFilter{})
 		assert.NoError(t, err)
 		assert.Equal(t, transactions, res)
 	})
 
+	t.Run("Success with next page", func(t *testing.T) {
+		accountID := uuid.New()
+		timestamp := time.Now()
+		transactions := []model.Transaction{
+			{ID: uuid.New(), CreatedAt: timestamp},
+			{ID: uuid.New(), CreatedAt: timestamp.Add(-time.Minute)},
+			{ID: uuid.New(), CreatedAt: timestamp.Add(-2 * time.Minute)},
+		}
+		after := &internal.TransactionCursor{
+			CreatedAt: timestamp.Add(time.Minute),
+			ID:        uuid.New().String(),
+		}
+
+		wallet := model.Wallet{
+			ID:      uuid.New(),
+			OwnedBy: accountID,
+			Status:  model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), internal.TransactionFilter{
+			WalletIDs: []string{wallet.ID.String()},
+			Types:     []string{model.TransactionType.Deposit},
+			After:     after,
+			Limit:     3,
+		}).Return(transactions, nil).Times(1)
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		res, next, err := w.GetTransactions(context.Background(), accountID, internal.TransactionFilter{
+			WalletIDs: []string{uuid.New().String()},
+			Types:     []string{model.TransactionType.Deposit},
+			After:     after,
+			Limit:     2,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, transactions[:2], res)
+		assert.Equal(t, &internal.TransactionCursor{
+			CreatedAt: transactions[1].CreatedAt,
+			ID:        transactions[1].ID.String(),
+		}, next)
+	})
+
+}
+
+func TestStatement(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 31, 23, 59, 59, 0, time.UTC)
+
+	t.Run("failed invalid period", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		writer := mock.NewMockStatementWriter(ctrl)
+
+		w := &walletService{}
+		err := w.Statement(context.Background(), uuid.New(), to, from, writer)
+		assert.ErrorIs(t, err, model.ErrInvalidStatementPeriod)
+	})
+
+	t.Run("failed wallet disabled", func(t *testing.T) {
+		accountID := uuid.New()
+
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(model.Wallet{Status: model.WalletStatus.Disabled}, nil).Times(1)
+		writer := mock.NewMockStatementWriter(ctrl)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+			},
+		}
+		err := w.Statement(context.Background(), accountID, from, to, writer)
+		assert.ErrorIs(t, err, model.ErrWalletDisabled)
+	})
+
+	t.Run("failed summarize", func(t *testing.T) {
+		accountID := uuid.New()
+		errExpected := errors.New("err")
+		wallet := model.Wallet{
+			ID:      uuid.New(),
+			OwnedBy: accountID,
+			Status:  model.WalletStatus.Enabled,
+		}
+
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(internal.TransactionSummary{}, errExpected).Times(1)
+		writer := mock.NewMockStatementWriter(ctrl)
+
+		w := &walletService{
+			cfg: Config{
+				TxRepository:          txRepo,
+				WalletRepository:      walletRepo,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		err := w.Statement(context.Background(), accountID, from, to, writer)
+		assert.ErrorIs(t, err, errExpected)
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:      uuid.New(),
+			OwnedBy: accountID,
+			Status:  model.WalletStatus.Enabled,
+		}
+		filter := internal.TransactionFilter{
+			WalletIDs:    []string{wallet.ID.String()},
+			Statuses:     []string{model.TransactionStatus.Success},
+			TransactedTo: &to,
+		}
+		untilTo := func(types []string) internal.TransactionFilter {
+			f := filter
+			f.Types = types
+			return f
+		}
+		period := func(types []string) internal.TransactionFilter {
+			f := untilTo(types)
+			f.TransactedFrom = &from
+			return f
+		}
+
+		// a full page of deposits and a withdrawal on the next one
+		timestamp := from.Add(time.Hour)
+		firstPage := make([]model.Transaction, statementPageSize)
+		for i := range firstPage {
+			firstPage[i] = model.Transaction{
+				ID:        uuid.New(),
+				Type:      model.TransactionType.Deposit,
+				Amount:    1,
+				CreatedAt: timestamp.Add(time.Duration(i) * time.Second),
+			}
+		}
+		withdrawal := model.Transaction{
+			ID:        uuid.New(),
+			Type:      model.TransactionType.Withdrawal,
+			Amount:    100,
+			CreatedAt: timestamp.Add(time.Hour),
+		}
+		list := period(nil)
+		list.Ascending = true
+		list.Limit = statementPageSize
+		next := list
+		last := firstPage[len(firstPage)-1]
+		next.After = &internal.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID.String()}
+
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, opts ...internal.TxOption) error {
+			assert.True(t, internal.NewTxOptions(opts...).ReadOnly)
+			return fn(ctx, nil)
+		}).Times(1)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), untilTo(model.CreditTransactionTypes)).Return(internal.TransactionSummary{Amount: 1500}, nil).Times(1)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), untilTo(model.DebitTransactionTypes)).Return(internal.TransactionSummary{Amount: 300}, nil).Times(1)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), period(model.CreditTransactionTypes)).Return(internal.TransactionSummary{Amount: 1000}, nil).Times(1)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), period(model.DebitTransactionTypes)).Return(internal.TransactionSummary{Amount: 100}, nil).Times(1)
+		gomock.InOrder(
+			transactionRepo.EXPECT().List(gomock.Any(), list).Return(firstPage, nil).Times(1),
+			transactionRepo.EXPECT().List(gomock.Any(), next).Return([]model.Transaction{withdrawal}, nil).Times(1),
+		)
+
+		var begun model.Statement
+		var lines []model.StatementLine
+		writer := mock.NewMockStatementWriter(ctrl)
+		writer.EXPECT().Begin(gomock.Any()).DoAndReturn(func(statement model.Statement) error {
+			begun = statement
+			return nil
+		}).Times(1)
+		writer.EXPECT().Line(gomock.Any()).DoAndReturn(func(line model.StatementLine) error {
+			lines = append(lines, line)
+			return nil
+		}).Times(statementPageSize + 1)
+		writer.EXPECT().End(gomock.Any()).DoAndReturn(func(statement model.Statement) error {
+			assert.Equal(t, int64(300), statement.OpeningBalance)
+			assert.Equal(t, int64(300+statementPageSize-100), statement.ClosingBalance)
+			return nil
+		}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				TxRepository:          txRepo,
+				WalletRepository:      walletRepo,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		err := w.Statement(context.Background(), accountID, from, to, writer)
+		assert.NoError(t, err)
+		assert.Equal(t, wallet.ID, begun.WalletID)
+		assert.Equal(t, int64(300), begun.OpeningBalance)
+		assert.Equal(t, int64(301), lines[0].Balance)
+		assert.Equal(t, withdrawal, lines[statementPageSize].Transaction)
+		assert.Equal(t, int64(300+statementPageSize-100), lines[statementPageSize].Balance)
+	})
+
+	t.Run("failed writer", func(t *testing.T) {
+		accountID := uuid.New()
+		errExpected := errors.New("err")
+		wallet := model.Wallet{
+			ID:      uuid.New(),
+			OwnedBy: accountID,
+			Status:  model.WalletStatus.Enabled,
+		}
+
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(internal.TransactionSummary{}, nil).Times(4)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{
+			{ID: uuid.New(), Type: model.TransactionType.Deposit, Amount: 10},
+			{ID: uuid.New(), Type: model.TransactionType.Deposit, Amount: 10},
+		}, nil).Times(1)
+		writer := mock.NewMockStatementWriter(ctrl)
+		writer.EXPECT().Begin(gomock.Any()).Return(nil).Times(1)
+		writer.EXPECT().Line(gomock.Any()).Return(errExpected).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				TxRepository:          txRepo,
+				WalletRepository:      walletRepo,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		err := w.Statement(context.Background(), accountID, from, to, writer)
+		assert.ErrorIs(t, err, errExpected)
+	})
 }
 
 func TestDeposit(t *testing.T) {
@@ -738,6 +1159,7 @@ func TestDeposit(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errExpected).Times(1)
 
 		w := &walletService{
@@ -762,6 +1184,7 @@ func TestDeposit(t *testing.T) {
 		}
 		ctrl := gomock.NewController(t)
 		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
 		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
@@ -770,13 +1193,14 @@ func TestDeposit(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
-		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(int64(0), errExpected).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(0), nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -789,6 +1213,7 @@ func TestDeposit(t *testing.T) {
 				Validator:             validator,
 				TransactionRepository: transactionRepo,
 				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
 			},
 		}
 		res, err := w.Deposit(context.Background(), accountID, model.Transaction{})
@@ -796,9 +1221,8 @@ func TestDeposit(t *testing.T) {
 		assert.Equal(t, model.Transaction{}, res)
 	})
 
-	t.Run("failed increment, success update status transaction", func(t *testing.T) {
+	t.Run("max balance reached on increment, failed status stored", func(t *testing.T) {
 		accountID := uuid.New()
-		var errExpected = errors.New("err")
 
 		wallet := model.Wallet{
 			ID:     uuid.New(),
@@ -806,6 +1230,7 @@ func TestDeposit(t *testing.T) {
 		}
 		ctrl := gomock.NewController(t)
 		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
 		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
@@ -814,18 +1239,22 @@ func TestDeposit(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
-		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(int64(0), errExpected).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(0), nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
 		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(nil).Times(1)
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, transaction model.Transaction) error {
+				assert.Equal(t, model.TransactionStatus.Failed, transaction.Status)
+				return nil
+			}).Times(1)
 
 		w := &walletService{
 			cfg: Config{
@@ -833,15 +1262,17 @@ func TestDeposit(t *testing.T) {
 				Validator:             validator,
 				TransactionRepository: transactionRepo,
 				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
 			},
 		}
 		res, err := w.Deposit(context.Background(), accountID, model.Transaction{})
-		assert.Nil(t, err)
-		assert.Equal(t, model.TransactionStatus.Failed, res.Status)
+		assert.ErrorIs(t, err, model.ErrBalanceLimitExceeded)
+		assert.Equal(t, model.Transaction{}, res)
 	})
 
-	t.Run("Success Deposit", func(t *testing.T) {
+	t.Run("error increment", func(t *testing.T) {
 		accountID := uuid.New()
+		var errExpected = errors.New("err")
 
 		wallet := model.Wallet{
 			ID:     uuid.New(),
@@ -849,27 +1280,24 @@ func TestDeposit(t *testing.T) {
 		}
 		ctrl := gomock.NewController(t)
 		walletRepo := mock.NewMockWalletRepository(ctrl)
-		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
-			OwnedBies: []string{accountID.String()},
-		}).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
 
 		validator := mock.NewMockValidator(ctrl)
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
-		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(int64(0), nil).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(0), errExpected).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
-		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(nil).Times(1)
-
 		w := &walletService{
 			cfg: Config{
 				WalletRepository:      walletRepo,
@@ -879,13 +1307,116 @@ func TestDeposit(t *testing.T) {
 			},
 		}
 		res, err := w.Deposit(context.Background(), accountID, model.Transaction{})
-		assert.Nil(t, err)
-		assert.Equal(t, model.TransactionStatus.Success, res.Status)
+		assert.ErrorIs(t, err, errExpected)
+		assert.Equal(t, model.Transaction{}, res)
 	})
-}
 
-func TestWithdrawal(t *testing.T) {
-	t.Run("failed get wallet", func(t *testing.T) {
+	t.Run("failed post journal entry", func(t *testing.T) {
+		accountID := uuid.New()
+		var errExpected = errors.New("err")
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(1), nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(errExpected).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+				LedgerRepository:      ledgerRepo,
+			},
+		}
+		res, err := w.Deposit(context.Background(), accountID, model.Transaction{})
+		assert.ErrorIs(t, err, errExpected)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("Success Deposit", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(1), nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error {
+				assert.True(t, entry.IsBalanced())
+				return nil
+			}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+				LedgerRepository:      ledgerRepo,
+			},
+		}
+		res, err := w.Deposit(context.Background(), accountID, model.Transaction{})
+		assert.Nil(t, err)
+		assert.Equal(t, model.TransactionStatus.Success, res.Status)
+	})
+}
+
+func TestWithdrawal(t *testing.T) {
+	t.Run("failed get wallet", func(t *testing.T) {
 		accountID := uuid.New()
 		var errExpected = errors.New("err")
 
@@ -951,6 +1482,7 @@ func TestWithdrawal(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errExpected).Times(1)
 
 		w := &walletService{
@@ -975,6 +1507,7 @@ func TestWithdrawal(t *testing.T) {
 		}
 		ctrl := gomock.NewController(t)
 		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
 		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
@@ -983,13 +1516,14 @@ func TestWithdrawal(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(int64(0), errExpected).Times(1)
+			Return(int64(0), nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -1002,6 +1536,7 @@ func TestWithdrawal(t *testing.T) {
 				Validator:             validator,
 				TransactionRepository: transactionRepo,
 				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
 			},
 		}
 		res, err := w.Withdrawal(context.Background(), accountID, model.Transaction{})
@@ -1011,7 +1546,6 @@ func TestWithdrawal(t *testing.T) {
 
 	t.Run("failed decrment, success update status transaction", func(t *testing.T) {
 		accountID := uuid.New()
-		var errExpected = errors.New("err")
 
 		wallet := model.Wallet{
 			ID:     uuid.New(),
@@ -1019,6 +1553,7 @@ func TestWithdrawal(t *testing.T) {
 		}
 		ctrl := gomock.NewController(t)
 		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
 		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
@@ -1027,13 +1562,14 @@ func TestWithdrawal(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-			Return(int64(0), errExpected).Times(1)
+			Return(int64(0), nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -1046,6 +1582,7 @@ func TestWithdrawal(t *testing.T) {
 				Validator:             validator,
 				TransactionRepository: transactionRepo,
 				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
 			},
 		}
 		res, err := w.Withdrawal(context.Background(), accountID, model.Transaction{})
@@ -1062,6 +1599,7 @@ func TestWithdrawal(t *testing.T) {
 		}
 		ctrl := gomock.NewController(t)
 		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
 		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
@@ -1070,13 +1608,14 @@ func TestWithdrawal(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(int64(0), nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
@@ -1089,6 +1628,7 @@ func TestWithdrawal(t *testing.T) {
 				Validator:             validator,
 				TransactionRepository: transactionRepo,
 				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
 			},
 		}
 		res, err := w.Withdrawal(context.Background(), accountID, model.Transaction{})
@@ -1105,6 +1645,7 @@ func TestWithdrawal(t *testing.T) {
 		}
 		ctrl := gomock.NewController(t)
 		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
 		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
 			OwnedBies: []string{accountID.String()},
 		}).Return(wallet, nil).Times(1)
@@ -1113,29 +1654,1661 @@ func TestWithdrawal(t *testing.T) {
 		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
 
 		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
 		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(int64(1), nil).Times(1)
 
 		txRepo := mock.NewMockTxRepository(ctrl)
-		txRepo.EXPECT().Process(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) error {
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
 			return fn(ctx, nil)
 		}).Times(1)
 
 		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil).Times(1)
 
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error {
+				assert.True(t, entry.IsBalanced())
+				return nil
+			}).Times(1)
+
 		w := &walletService{
 			cfg: Config{
 				WalletRepository:      walletRepo,
 				Validator:             validator,
 				TransactionRepository: transactionRepo,
 				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+				LedgerRepository:      ledgerRepo,
 			},
 		}
 		res, err := w.Withdrawal(context.Background(), accountID, model.Transaction{})
 		assert.Nil(t, err)
 		assert.Equal(t, model.TransactionStatus.Success, res.Status)
 	})
+
+	t.Run("Success Withdrawal with fee", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), int64(10250)).
+			Return(int64(1), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		feeCalculator := mock.NewMockFeeCalculator(ctrl)
+		feeCalculator.EXPECT().Calculate(int64(10000)).Return(int64(250)).Times(1)
+
+		var parentID uuid.UUID
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, transaction model.Transaction) error {
+				parentID = transaction.ID
+				assert.Equal(t, int64(250), transaction.Fee)
+				return nil
+			}).Times(1)
+		transactionRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, transaction model.Transaction) error {
+				assert.Equal(t, model.TransactionType.Fee, transaction.Type)
+				assert.Equal(t, model.TransactionStatus.Success, transaction.Status)
+				assert.Equal(t, int64(250), transaction.Amount)
+				assert.Equal(t, &parentID, transaction.ParentID)
+				return nil
+			}).Times(1)
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		accounts := []string{}
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error {
+				assert.True(t, entry.IsBalanced())
+				accounts = append(accounts, entry.Postings[1].Account)
+				return nil
+			}).Times(2)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+				LedgerRepository:      ledgerRepo,
+				WithdrawalFee:         feeCalculator,
+			},
+		}
+		res, err := w.Withdrawal(context.Background(), accountID, model.Transaction{Amount: 10000})
+		assert.Nil(t, err)
+		assert.Equal(t, model.TransactionStatus.Success, res.Status)
+		assert.Equal(t, int64(250), res.Fee)
+		assert.Equal(t, int64(10250), res.GrossAmount())
+		assert.Equal(t, []string{model.LedgerAccount.CashOut, model.LedgerAccount.Fees}, accounts)
+	})
+
+	t.Run("failed decrement gross amount with fee", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), int64(10250)).
+			Return(int64(0), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		feeCalculator := mock.NewMockFeeCalculator(ctrl)
+		feeCalculator.EXPECT().Calculate(int64(10000)).Return(int64(250)).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+				WithdrawalFee:         feeCalculator,
+			},
+		}
+		res, err := w.Withdrawal(context.Background(), accountID, model.Transaction{Amount: 10000})
+		assert.Nil(t, err)
+		assert.Equal(t, model.TransactionStatus.Failed, res.Status)
+	})
+}
+
+func TestTransfer(t *testing.T) {
+	t.Run("failed transfer to own wallet", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+			},
+		}
+		res, err := w.Transfer(context.Background(), accountID, wallet.ID, model.Transaction{})
+		assert.ErrorIs(t, err, model.ErrTransferToOwnWallet)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("failed receiver wallet disabled", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		receiverWallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Disabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			IDs: []string{receiverWallet.ID.String()},
+		}).Return(receiverWallet, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+			},
+		}
+		res, err := w.Transfer(context.Background(), accountID, receiverWallet.ID, model.Transaction{})
+		assert.ErrorIs(t, err, model.ErrReceiverWalletDisabled)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("failed decrement, success update status transaction", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		receiverWallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), receiverWallet.ID).Return(receiverWallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			IDs: []string{receiverWallet.ID.String()},
+		}).Return(receiverWallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), wallet, gomock.Any()).
+			Return(int64(0), nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Transfer(context.Background(), accountID, receiverWallet.ID, model.Transaction{})
+		assert.Nil(t, err)
+		assert.Equal(t, model.TransactionStatus.Failed, res.Status)
+	})
+
+	t.Run("failed increment receiver, rollback", func(t *testing.T) {
+		accountID := uuid.New()
+		var errExpected = errors.New("err")
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		receiverWallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), receiverWallet.ID).Return(receiverWallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			IDs: []string{receiverWallet.ID.String()},
+		}).Return(receiverWallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), wallet, gomock.Any()).
+			Return(int64(1), nil).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), receiverWallet, gomock.Any(), gomock.Any()).
+			Return(int64(0), errExpected).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Transfer(context.Background(), accountID, receiverWallet.ID, model.Transaction{})
+		assert.ErrorIs(t, err, errExpected)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("failed transfer over withdrawal limit", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		receiverWallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), receiverWallet.ID).Return(receiverWallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			IDs: []string{receiverWallet.ID.String()},
+		}).Return(receiverWallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		// a concurrent transfer settled between the early check and the lock
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Summarize(gomock.Any(), gomock.Any()).
+			Return(internal.TransactionSummary{Count: 1, Amount: 1000}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+				assert.Equal(t, withdrawalTypes, filter.Types)
+				return internal.TransactionSummary{Count: 2, Amount: 2000}, nil
+			}).Times(1)
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, transaction model.Transaction) error {
+				assert.Equal(t, model.TransactionStatus.Failed, transaction.Status)
+				return nil
+			}).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+				Limits:                Limits{Withdrawal: Limit{DailyCount: 2}},
+			},
+		}
+		res, err := w.Transfer(context.Background(), accountID, receiverWallet.ID, model.Transaction{
+			Amount:      1000,
+			ReferenceID: "ref",
+		})
+		assert.ErrorIs(t, err, model.ErrDailyCountLimitExceeded)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("Success Transfer", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		receiverWallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), receiverWallet.ID).Return(receiverWallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			IDs: []string{receiverWallet.ID.String()},
+		}).Return(receiverWallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), wallet, int64(1000)).
+			Return(int64(1), nil).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), receiverWallet, int64(1000), gomock.Any()).
+			Return(int64(1), nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		var credit model.Transaction
+		transactionRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, t model.Transaction) error {
+				credit = t
+				return nil
+			}).Times(1)
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error {
+				assert.True(t, entry.IsBalanced())
+				return nil
+			}).Times(2)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      anyOutboxRepository(ctrl),
+				LedgerRepository:      ledgerRepo,
+			},
+		}
+		res, err := w.Transfer(context.Background(), accountID, receiverWallet.ID, model.Transaction{
+			Amount:      1000,
+			ReferenceID: "ref",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, model.TransactionStatus.Success, res.Status)
+		assert.Equal(t, model.TransactionType.TransferOut, res.Type)
+		assert.Equal(t, receiverWallet.ID, credit.WalletID)
+		assert.Equal(t, model.TransactionType.TransferIn, credit.Type)
+		assert.Equal(t, model.TransactionStatus.Success, credit.Status)
+		assert.Equal(t, credit.ID.String(), credit.ReferenceID)
+		assert.Equal(t, &res.ID, credit.ParentID)
+	})
+}
+
+func TestIdempotency(t *testing.T) {
+	t.Run("replay returns original transaction", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		original := model.Transaction{
+			ID:          uuid.New(),
+			WalletID:    wallet.ID,
+			Type:        model.TransactionType.Deposit,
+			Status:      model.TransactionStatus.Failed,
+			Amount:      1000,
+			ReferenceID: "ref",
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), internal.TransactionFilter{
+			WalletIDs:    []string{wallet.ID.String()},
+			ReferenceIDs: []string{original.ReferenceID},
+		}).Return([]model.Transaction{original}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		res, err := w.Deposit(context.Background(), accountID, model.Transaction{
+			Amount:      1000,
+			ReferenceID: "ref",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, original, res)
+	})
+
+	t.Run("reference used with different payload", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		original := model.Transaction{
+			ID:          uuid.New(),
+			WalletID:    wallet.ID,
+			Type:        model.TransactionType.Deposit,
+			Status:      model.TransactionStatus.Success,
+			Amount:      1000,
+			ReferenceID: "ref",
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{original}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		res, err := w.Withdrawal(context.Background(), accountID, model.Transaction{
+			Amount:      1000,
+			ReferenceID: "ref",
+		})
+		assert.ErrorIs(t, err, model.ErrReferenceIDConflict)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("concurrent retry stored the reference first", func(t *testing.T) {
+		accountID := uuid.New()
+
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		original := model.Transaction{
+			ID:          uuid.New(),
+			WalletID:    wallet.ID,
+			Type:        model.TransactionType.Deposit,
+			Status:      model.TransactionStatus.Pending,
+			Amount:      1000,
+			ReferenceID: "ref",
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		gomock.InOrder(
+			transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1),
+			transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(model.ErrDuplicateReferenceID).Times(1),
+			transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{original}, nil).Times(1),
+		)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		res, err := w.Deposit(context.Background(), accountID, model.Transaction{
+			Amount:      1000,
+			ReferenceID: "ref",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, original, res)
+	})
+}
+
+func TestHold(t *testing.T) {
+	processTx := func(ctrl *gomock.Controller, times int) *mock.MockTxRepository {
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(times)
+		return txRepo
+	}
+
+	t.Run("authorize insufficient balance", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any(), int64(5000)).
+			Return(int64(0), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(model.Hold{}, sql.ErrNoRows).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				Validator:        validator,
+				HoldRepository:   holdRepo,
+				TxRepository:     processTx(ctrl, 1),
+				OutboxRepository: anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Authorize(context.Background(), accountID, model.Hold{Amount: 5000, ReferenceID: "ref"})
+		assert.ErrorIs(t, err, model.ErrInsufficientBalance)
+		assert.Equal(t, model.Hold{}, res)
+	})
+
+	t.Run("authorize success", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Reserve(gomock.Any(), gomock.Any(), gomock.Any(), int64(5000)).
+			Return(int64(1), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), internal.HoldFilter{
+			WalletIDs:    []string{wallet.ID.String()},
+			ReferenceIDs: []string{"ref"},
+		}).Return(model.Hold{}, sql.ErrNoRows).Times(1)
+		holdRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				Validator:        validator,
+				HoldRepository:   holdRepo,
+				TxRepository:     processTx(ctrl, 1),
+				OutboxRepository: anyOutboxRepository(ctrl),
+				HoldExpiry:       time.Hour,
+			},
+		}
+		res, err := w.Authorize(context.Background(), accountID, model.Hold{Amount: 5000, ReferenceID: "ref"})
+		assert.NoError(t, err)
+		assert.Equal(t, model.HoldStatus.Authorized, res.Status)
+		assert.Equal(t, wallet.ID, res.WalletID)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), res.ExpiresAt, time.Minute)
+	})
+
+	t.Run("capture partial amount", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		hold := model.Hold{
+			ID:          uuid.New(),
+			WalletID:    wallet.ID,
+			Amount:      5000,
+			Status:      model.HoldStatus.Authorized,
+			ReferenceID: "ref",
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), int64(5000), int64(3000)).
+			Return(int64(1), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), internal.HoldFilter{
+			IDs:       []string{hold.ID.String()},
+			WalletIDs: []string{wallet.ID.String()},
+		}).Return(hold, nil).Times(1)
+		holdRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, h model.Hold) (int64, error) {
+				assert.Equal(t, model.HoldStatus.Captured, h.Status)
+				assert.Equal(t, int64(3000), h.CapturedAmount)
+				return 1, nil
+			}).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				HoldRepository:        holdRepo,
+				TransactionRepository: transactionRepo,
+				LedgerRepository:      ledgerRepo,
+				TxRepository:          processTx(ctrl, 1),
+				OutboxRepository:      anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Capture(context.Background(), accountID, hold.ID, 3000)
+		assert.NoError(t, err)
+		assert.Equal(t, model.TransactionType.Capture, res.Type)
+		assert.Equal(t, model.TransactionStatus.Success, res.Status)
+		assert.Equal(t, int64(3000), res.Amount)
+		assert.Equal(t, "hold:"+hold.ID.String(), res.ReferenceID)
+	})
+
+	t.Run("capture reserved mismatch", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		hold := model.Hold{
+			ID:          uuid.New(),
+			WalletID:    wallet.ID,
+			Amount:      5000,
+			Status:      model.HoldStatus.Authorized,
+			ReferenceID: "ref",
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Capture(gomock.Any(), gomock.Any(), gomock.Any(), int64(5000), int64(5000)).
+			Return(int64(0), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(hold, nil).Times(1)
+		holdRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				Validator:        validator,
+				HoldRepository:   holdRepo,
+				TxRepository:     processTx(ctrl, 1),
+			},
+		}
+		_, err := w.Capture(context.Background(), accountID, hold.ID, 0)
+		assert.ErrorIs(t, err, model.ErrReservedMismatch)
+		assert.ErrorIs(t, err, model.ErrBussiness)
+	})
+
+	t.Run("capture over withdrawal limit", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		hold := model.Hold{
+			ID:          uuid.New(),
+			WalletID:    wallet.ID,
+			Amount:      5000,
+			Status:      model.HoldStatus.Authorized,
+			ReferenceID: "ref",
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(hold, nil).Times(1)
+		holdRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+
+		// a withdrawal settled between the early check and the lock
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().Summarize(gomock.Any(), gomock.Any()).
+			Return(internal.TransactionSummary{Count: 1, Amount: 4000}, nil).Times(1)
+		transactionRepo.EXPECT().SummarizeTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+				assert.Equal(t, withdrawalTypes, filter.Types)
+				return internal.TransactionSummary{Count: 2, Amount: 6000}, nil
+			}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				HoldRepository:        holdRepo,
+				TransactionRepository: transactionRepo,
+				TxRepository:          processTx(ctrl, 1),
+				Limits:                Limits{Withdrawal: Limit{DailyAmount: 10000}},
+			},
+		}
+		res, err := w.Capture(context.Background(), accountID, hold.ID, 0)
+		assert.ErrorIs(t, err, model.ErrDailyAmountLimitExceeded)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("capture more than hold", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		hold := model.Hold{
+			ID:        uuid.New(),
+			WalletID:  wallet.ID,
+			Amount:    5000,
+			Status:    model.HoldStatus.Authorized,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(hold, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				HoldRepository:   holdRepo,
+			},
+		}
+		res, err := w.Capture(context.Background(), accountID, hold.ID, 6000)
+		assert.ErrorIs(t, err, model.ErrCaptureExceedsHold)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("capture expired hold releases it", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		hold := model.Hold{
+			ID:        uuid.New(),
+			WalletID:  wallet.ID,
+			Amount:    5000,
+			Status:    model.HoldStatus.Authorized,
+			ExpiresAt: time.Now().Add(-time.Minute),
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Release(gomock.Any(), gomock.Any(), gomock.Any(), int64(5000)).
+			Return(int64(1), nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(hold, nil).Times(1)
+		holdRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, h model.Hold) (int64, error) {
+				assert.Equal(t, model.HoldStatus.Expired, h.Status)
+				return 1, nil
+			}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				HoldRepository:   holdRepo,
+				TxRepository:     processTx(ctrl, 1),
+				OutboxRepository: anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Capture(context.Background(), accountID, hold.ID, 0)
+		assert.ErrorIs(t, err, model.ErrHoldExpired)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("void settled hold", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		hold := model.Hold{
+			ID:        uuid.New(),
+			WalletID:  wallet.ID,
+			Amount:    5000,
+			Status:    model.HoldStatus.Captured,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(hold, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				HoldRepository:   holdRepo,
+			},
+		}
+		res, err := w.Void(context.Background(), accountID, hold.ID)
+		assert.ErrorIs(t, err, model.ErrHoldNotAuthorized)
+		assert.Equal(t, model.Hold{}, res)
+	})
+
+	t.Run("void success", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		hold := model.Hold{
+			ID:        uuid.New(),
+			WalletID:  wallet.ID,
+			Amount:    5000,
+			Status:    model.HoldStatus.Authorized,
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Release(gomock.Any(), gomock.Any(), gomock.Any(), int64(5000)).
+			Return(int64(1), nil).Times(1)
+
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(hold, nil).Times(1)
+		holdRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				HoldRepository:   holdRepo,
+				TxRepository:     processTx(ctrl, 1),
+				OutboxRepository: anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Void(context.Background(), accountID, hold.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, model.HoldStatus.Voided, res.Status)
+	})
+
+	t.Run("expire holds skips settled holds", func(t *testing.T) {
+		now := time.Now()
+		holds := []model.Hold{
+			{ID: uuid.New(), WalletID: uuid.New(), Amount: 1000, Status: model.HoldStatus.Authorized},
+			{ID: uuid.New(), WalletID: uuid.New(), Amount: 2000, Status: model.HoldStatus.Authorized},
+		}
+		ctrl := gomock.NewController(t)
+		holdRepo := mock.NewMockHoldRepository(ctrl)
+		holdRepo.EXPECT().List(gomock.Any(), internal.HoldFilter{
+			Statuses:      []string{model.HoldStatus.Authorized},
+			ExpiresBefore: &now,
+			Limit:         expireHoldBatchSize,
+		}).Return(holds, nil).Times(1)
+		gomock.InOrder(
+			holdRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(0), nil).Times(1),
+			holdRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(int64(1), nil).Times(1),
+		)
+
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().Release(gomock.Any(), gomock.Any(), gomock.Any(), int64(2000)).
+			Return(int64(1), nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				HoldRepository:   holdRepo,
+				TxRepository:     processTx(ctrl, 2),
+				OutboxRepository: anyOutboxRepository(ctrl),
+			},
+		}
+		expired, err := w.ExpireHolds(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, expired)
+	})
+}
+
+func TestRefund(t *testing.T) {
+	processTx := func(ctrl *gomock.Controller) *mock.MockTxRepository {
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+		return txRepo
+	}
+
+	t.Run("refund part of a withdrawal", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		original := model.Transaction{
+			ID:             uuid.New(),
+			WalletID:       wallet.ID,
+			Type:           model.TransactionType.Withdrawal,
+			Status:         model.TransactionStatus.Success,
+			Amount:         5000,
+			RefundedAmount: 1000,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), int64(3000), gomock.Any()).
+			Return(int64(1), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), internal.TransactionFilter{
+			IDs:       []string{original.ID.String()},
+			WalletIDs: []string{wallet.ID.String()},
+		}).Return([]model.Transaction{original}, nil).Times(1)
+		transactionRepo.EXPECT().AddRefundedTx(gomock.Any(), gomock.Any(), gomock.Any(), int64(3000)).
+			Return(int64(1), nil).Times(1)
+		transactionRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error {
+				assert.True(t, entry.IsBalanced())
+				assert.Equal(t, int64(3000), entry.Postings[0].Amount)
+				assert.Equal(t, model.LedgerAccount.CashOut, entry.Postings[1].Account)
+				return nil
+			}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				LedgerRepository:      ledgerRepo,
+				TxRepository:          processTx(ctrl),
+				OutboxRepository:      anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Refund(context.Background(), accountID, original.ID, 3000)
+		assert.NoError(t, err)
+		assert.Equal(t, model.TransactionType.Refund, res.Type)
+		assert.Equal(t, model.TransactionStatus.Success, res.Status)
+		assert.Equal(t, int64(3000), res.Amount)
+		assert.Equal(t, &original.ID, res.ParentID)
+	})
+
+	t.Run("reverse deposit with insufficient balance", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		original := model.Transaction{
+			ID:       uuid.New(),
+			WalletID: wallet.ID,
+			Type:     model.TransactionType.Deposit,
+			Status:   model.TransactionStatus.Success,
+			Amount:   5000,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), int64(5000)).
+			Return(int64(0), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{original}, nil).Times(1)
+		transactionRepo.EXPECT().AddRefundedTx(gomock.Any(), gomock.Any(), gomock.Any(), int64(5000)).
+			Return(int64(1), nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          processTx(ctrl),
+				OutboxRepository:      anyOutboxRepository(ctrl),
+			},
+		}
+		res, err := w.Refund(context.Background(), accountID, original.ID, 0)
+		assert.ErrorIs(t, err, model.ErrInsufficientBalance)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("transaction not refundable", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		original := model.Transaction{
+			ID:       uuid.New(),
+			WalletID: wallet.ID,
+			Type:     model.TransactionType.Withdrawal,
+			Status:   model.TransactionStatus.Failed,
+			Amount:   5000,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{original}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		res, err := w.Refund(context.Background(), accountID, original.ID, 0)
+		assert.ErrorIs(t, err, model.ErrNotRefundable)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("refund more than remaining", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		original := model.Transaction{
+			ID:             uuid.New(),
+			WalletID:       wallet.ID,
+			Type:           model.TransactionType.Withdrawal,
+			Status:         model.TransactionStatus.Success,
+			Amount:         5000,
+			RefundedAmount: 4000,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{original}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		res, err := w.Refund(context.Background(), accountID, original.ID, 2000)
+		assert.ErrorIs(t, err, model.ErrRefundExceedsRemaining)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("transaction not found", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		res, err := w.Refund(context.Background(), accountID, uuid.New(), 0)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+}
+
+// anyOutboxRepository accepts any event, for tests not asserting on events.
+func anyOutboxRepository(ctrl *gomock.Controller) *mock.MockOutboxRepository {
+	outboxRepo := mock.NewMockOutboxRepository(ctrl)
+	outboxRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	return outboxRepo
+}
+
+func TestAdjust(t *testing.T) {
+	operatorCtx := util.ContextWithOperator(context.Background(), "ops")
+	processTx := func(ctrl *gomock.Controller) *mock.MockTxRepository {
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+		return txRepo
+	}
+
+	t.Run("credit a disabled wallet", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Disabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), int64(2500), gomock.Any()).
+			Return(int64(1), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, transaction model.Transaction) error {
+				assert.Equal(t, "ticket 42 : missed deposit", transaction.Reason)
+				assert.Equal(t, "ops", transaction.Operator)
+				return nil
+			}).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error {
+				assert.True(t, entry.IsBalanced())
+				assert.Equal(t, int64(2500), entry.Postings[0].Amount)
+				assert.Equal(t, model.LedgerAccount.Adjustments, entry.Postings[1].Account)
+				return nil
+			}).Times(1)
+
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, event model.Event) error {
+				assert.NotContains(t, string(event.Payload), "reason")
+				assert.NotContains(t, string(event.Payload), "ticket 42")
+				return nil
+			}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				LedgerRepository:      ledgerRepo,
+				TxRepository:          processTx(ctrl),
+				OutboxRepository:      outboxRepo,
+			},
+		}
+		res, err := w.Adjust(operatorCtx, accountID, model.Transaction{
+			Type:   model.TransactionType.AdjustmentCredit,
+			Amount: 2500,
+			Reason: "ticket 42 : missed deposit",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, model.TransactionStatus.Success, res.Status)
+		assert.Equal(t, res.ID.String(), res.ReferenceID)
+		assert.Equal(t, wallet.ID, res.WalletID)
+	})
+
+	t.Run("debit with insufficient balance", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), int64(2500)).
+			Return(int64(0), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          processTx(ctrl),
+			},
+		}
+		res, err := w.Adjust(operatorCtx, accountID, model.Transaction{
+			Type:        model.TransactionType.AdjustmentDebit,
+			Amount:      2500,
+			ReferenceID: "ticket-43",
+			Reason:      "ticket 43 : duplicated deposit",
+		})
+		assert.ErrorIs(t, err, model.ErrInsufficientBalance)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("retried reference", func(t *testing.T) {
+		wallet := model.Wallet{ID: uuid.New()}
+		original := model.Transaction{
+			ID:          uuid.New(),
+			WalletID:    wallet.ID,
+			Type:        model.TransactionType.AdjustmentCredit,
+			Amount:      2500,
+			ReferenceID: "ticket-42",
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{original}, nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+			},
+		}
+		res, err := w.Adjust(operatorCtx, uuid.New(), model.Transaction{
+			Type:        model.TransactionType.AdjustmentCredit,
+			Amount:      2500,
+			ReferenceID: "ticket-42",
+			Reason:      "ticket 42 : missed deposit",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, original, res)
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		w := &walletService{}
+		res, err := w.Adjust(context.Background(), uuid.New(), model.Transaction{
+			Type:   model.TransactionType.AdjustmentCredit,
+			Amount: 2500,
+			Reason: "  ",
+		})
+		assert.ErrorIs(t, err, model.ErrAdjustmentNoReason)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("missing operator", func(t *testing.T) {
+		w := &walletService{}
+		res, err := w.Adjust(context.Background(), uuid.New(), model.Transaction{
+			Type:   model.TransactionType.AdjustmentCredit,
+			Amount: 2500,
+			Reason: "ticket 42 : missed deposit",
+		})
+		assert.ErrorIs(t, err, model.ErrAdjustmentNoOperator)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+
+	t.Run("not an adjustment", func(t *testing.T) {
+		w := &walletService{}
+		res, err := w.Adjust(context.Background(), uuid.New(), model.Transaction{
+			Type:   model.TransactionType.Deposit,
+			Amount: 2500,
+			Reason: "ticket 44",
+		})
+		assert.ErrorIs(t, err, model.ErrNotAnAdjustment)
+		assert.Equal(t, model.Transaction{}, res)
+	})
+}
+
+func TestEvents(t *testing.T) {
+	t.Run("failed deposit publishes transaction failed", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(0), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, event model.Event) error {
+				assert.Equal(t, model.EventType.TransactionFailed, event.Type)
+				assert.Equal(t, wallet.ID, event.WalletID)
+
+				payload := model.Transaction{}
+				assert.NoError(t, json.Unmarshal(event.Payload, &payload))
+				assert.Equal(t, model.TransactionStatus.Failed, payload.Status)
+				return nil
+			}).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      outboxRepo,
+			},
+		}
+		_, err := w.Deposit(context.Background(), accountID, model.Transaction{Amount: 1000})
+		assert.ErrorIs(t, err, model.ErrBalanceLimitExceeded)
+	})
+
+	t.Run("transfer publishes both sides", func(t *testing.T) {
+		accountID := uuid.New()
+		wallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		receiverWallet := model.Wallet{
+			ID:     uuid.New(),
+			Status: model.WalletStatus.Enabled,
+		}
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), wallet.ID).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().LockTx(gomock.Any(), gomock.Any(), receiverWallet.ID).Return(receiverWallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			OwnedBies: []string{accountID.String()},
+		}).Return(wallet, nil).Times(1)
+		walletRepo.EXPECT().GetOne(gomock.Any(), internal.WalletFilter{
+			IDs: []string{receiverWallet.ID.String()},
+		}).Return(receiverWallet, nil).Times(1)
+		walletRepo.EXPECT().Decrement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(1), nil).Times(1)
+		walletRepo.EXPECT().Increment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(int64(1), nil).Times(1)
+
+		validator := mock.NewMockValidator(ctrl)
+		validator.EXPECT().Validate(gomock.Any()).Return(nil).Times(1)
+
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{}, nil).Times(1)
+		transactionRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		transactionRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().PostTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		published := []uuid.UUID{}
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, event model.Event) error {
+				assert.Equal(t, model.EventType.TransactionSucceeded, event.Type)
+				published = append(published, event.WalletID)
+				return nil
+			}).Times(2)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository:      walletRepo,
+				Validator:             validator,
+				TransactionRepository: transactionRepo,
+				LedgerRepository:      ledgerRepo,
+				TxRepository:          txRepo,
+				OutboxRepository:      outboxRepo,
+			},
+		}
+		res, err := w.Transfer(context.Background(), accountID, receiverWallet.ID, model.Transaction{Amount: 1000})
+		assert.NoError(t, err)
+		assert.Equal(t, model.TransactionStatus.Success, res.Status)
+		assert.ElementsMatch(t, []uuid.UUID{wallet.ID, receiverWallet.ID}, published)
+	})
+
+	t.Run("failed publish rolls back", func(t *testing.T) {
+		accountID := uuid.New()
+		errExpected := errors.New("err")
+		ctrl := gomock.NewController(t)
+		walletRepo := mock.NewMockWalletRepository(ctrl)
+		walletRepo.EXPECT().GetOne(gomock.Any(), gomock.Any()).
+			Return(model.Wallet{Status: model.WalletStatus.Disabled}, nil).Times(1)
+		walletRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(1)
+
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(errExpected).Times(1)
+
+		auditRepo := mock.NewMockAuditRepository(ctrl)
+		auditRepo.EXPECT().CreateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				WalletRepository: walletRepo,
+				TxRepository:     txRepo,
+				OutboxRepository: outboxRepo,
+				AuditRepository:  auditRepo,
+			},
+		}
+		res, err := w.Enable(context.Background(), accountID, 0)
+		assert.ErrorIs(t, err, errExpected)
+		assert.Equal(t, model.Wallet{}, res)
+	})
+}
+
+func TestRecoverPending(t *testing.T) {
+	processTx := func(ctrl *gomock.Controller, times int) *mock.MockTxRepository {
+		txRepo := mock.NewMockTxRepository(ctrl)
+		txRepo.EXPECT().Process(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, _ ...internal.TxOption) error {
+			return fn(ctx, nil)
+		}).Times(times)
+		return txRepo
+	}
+	newPending := func(createdAt time.Time) model.Transaction {
+		return model.Transaction{
+			ID:          uuid.New(),
+			WalletID:    uuid.New(),
+			Type:        model.TransactionType.Deposit,
+			Status:      model.TransactionStatus.Pending,
+			Amount:      1000,
+			ReferenceID: uuid.New().String(),
+			CreatedAt:   createdAt,
+			UpdatedAt:   createdAt,
+		}
+	}
+
+	t.Run("resolves from the ledger", func(t *testing.T) {
+		now := time.Now()
+		createdBefore := now.Add(-defaultPendingRecoveryAge)
+		settled := newPending(now.Add(-time.Hour))
+		lost := newPending(now.Add(-time.Hour))
+		entry := model.JournalEntry{ID: uuid.New(), TransactionID: settled.ID, CreatedAt: now.Add(-59 * time.Minute)}
+
+		ctrl := gomock.NewController(t)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), internal.TransactionFilter{
+			Statuses:      []string{model.TransactionStatus.Pending},
+			CreatedBefore: &createdBefore,
+			Limit:         recoverPendingBatchSize,
+		}).Return([]model.Transaction{settled, lost}, nil).Times(1)
+
+		resolved := map[uuid.UUID]model.Transaction{}
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, tx *sql.Tx, transaction model.Transaction) error {
+				resolved[transaction.ID] = transaction
+				return nil
+			}).Times(2)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().GetEntryTx(gomock.Any(), gomock.Any(), settled.ID).Return(entry, nil).Times(1)
+		ledgerRepo.EXPECT().GetEntryTx(gomock.Any(), gomock.Any(), lost.ID).Return(model.JournalEntry{}, sql.ErrNoRows).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				TransactionRepository: transactionRepo,
+				LedgerRepository:      ledgerRepo,
+				TxRepository:          processTx(ctrl, 2),
+				OutboxRepository:      anyOutboxRepository(ctrl),
+			},
+		}
+		recovered, err := w.RecoverPending(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, recovered)
+
+		assert.Equal(t, model.TransactionStatus.Success, resolved[settled.ID].Status)
+		assert.Equal(t, &entry.CreatedAt, resolved[settled.ID].TransactedAt)
+		assert.Equal(t, model.TransactionStatus.Failed, resolved[lost.ID].Status)
+		assert.Nil(t, resolved[lost.ID].TransactedAt)
+		assert.Equal(t, now, resolved[lost.ID].UpdatedAt)
+	})
+
+	t.Run("skips transactions settled concurrently", func(t *testing.T) {
+		now := time.Now()
+		pending := newPending(now.Add(-time.Hour))
+
+		ctrl := gomock.NewController(t)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]model.Transaction{pending}, nil).Times(1)
+		transactionRepo.EXPECT().UpdateTx(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(model.ErrInvalidStatusTransition).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().GetEntryTx(gomock.Any(), gomock.Any(), pending.ID).Return(model.JournalEntry{}, sql.ErrNoRows).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				TransactionRepository: transactionRepo,
+				LedgerRepository:      ledgerRepo,
+				TxRepository:          processTx(ctrl, 1),
+				OutboxRepository:      mock.NewMockOutboxRepository(ctrl),
+			},
+		}
+		recovered, err := w.RecoverPending(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, recovered)
+	})
+
+	t.Run("failed read ledger", func(t *testing.T) {
+		now := time.Now()
+		errExpected := errors.New("failed")
+
+		ctrl := gomock.NewController(t)
+		transactionRepo := mock.NewMockTransactionRepository(ctrl)
+		transactionRepo.EXPECT().List(gomock.Any(), gomock.Any()).
+			Return([]model.Transaction{newPending(now.Add(-time.Hour))}, nil).Times(1)
+
+		ledgerRepo := mock.NewMockLedgerRepository(ctrl)
+		ledgerRepo.EXPECT().GetEntryTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(model.JournalEntry{}, errExpected).Times(1)
+
+		w := &walletService{
+			cfg: Config{
+				TransactionRepository: transactionRepo,
+				LedgerRepository:      ledgerRepo,
+				TxRepository:          processTx(ctrl, 1),
+			},
+		}
+		recovered, err := w.RecoverPending(context.Background(), now)
+		assert.ErrorIs(t, err, errExpected)
+		assert.Equal(t, 0, recovered)
+	})
 }
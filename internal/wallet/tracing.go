@@ -0,0 +1,18 @@
+package wallet
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/hokdre/mini-ewallet/internal/wallet")
+
+// validate runs the validator in a span of its own, so its share of a slow
+// request shows next to the queries.
+func (w *walletService) validate(ctx context.Context, v interface{}) error {
+	_, span := tracer.Start(ctx, "Validator.Validate")
+	defer span.End()
+
+	return w.cfg.Validator.Validate(v)
+}
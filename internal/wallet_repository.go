@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/google/uuid"
 	"github.com/hokdre/mini-ewallet/internal/model"
 )
 
@@ -12,10 +13,23 @@ type WalletFilter struct {
 	IDs       []string
 }
 
+// WalletRepository keeps the wallet balance as a cache of its ledger postings,
+// Increment and Decrement must run in the same transaction as LedgerRepository.PostTx.
 type WalletRepository interface {
 	GetOne(ctx context.Context, filter WalletFilter) (model.Wallet, error)
 	Update(ctx context.Context, wallet model.Wallet) error
+	UpdateTx(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error
 	CreateTx(ctx context.Context, tx *sql.Tx, newWallet model.Wallet) error
-	Increment(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error)
+	// LockTx reads the wallet and locks its row until tx ends.
+	LockTx(ctx context.Context, tx *sql.Tx, walletID uuid.UUID) (model.Wallet, error)
+	// Increment leaves the wallet untouched when the balance would exceed
+	// maxBalance, zero means no cap.
+	Increment(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64, maxBalance int64) (int64, error)
 	Decrement(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error)
+
+	// Reserve and Release move funds in and out of the reserved part of the
+	// balance, Capture releases reserved and debits amount in one step.
+	Reserve(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error)
+	Release(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error)
+	Capture(ctx context.Context, tx *sql.Tx, wallet model.Wallet, reserved int64, amount int64) (int64, error)
 }
@@ -2,17 +2,29 @@ package internal
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hokdre/mini-ewallet/internal/model"
 )
 
 type WalletService interface {
-	Init(ctx context.Context, externalID string) (string, error)
-	Enable(ctx context.Context, accountID uuid.UUID) (model.Wallet, error)
-	Disable(ctx context.Context, accountID uuid.UUID) (model.Wallet, error)
+	Init(ctx context.Context, externalID string) (model.TokenPair, error)
+	// Enable and Disable fail with ErrWalletVersionConflict unless the wallet
+	// is at version, zero takes any version.
+	Enable(ctx context.Context, accountID uuid.UUID, version int64) (model.Wallet, error)
+	Disable(ctx context.Context, accountID uuid.UUID, version int64) (model.Wallet, error)
+	// Get fails with ErrWalletDisabled on a disabled wallet, the wallet is
+	// returned along with it.
 	Get(ctx context.Context, accountID uuid.UUID) (model.Wallet, error)
-	GetTransactions(ctx context.Context, accountID uuid.UUID) ([]model.Transaction, error)
+	GetTransactions(ctx context.Context, accountID uuid.UUID, filter TransactionFilter) ([]model.Transaction, *TransactionCursor, error)
+	Statement(ctx context.Context, accountID uuid.UUID, from time.Time, to time.Time, writer StatementWriter) error
 	Deposit(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (model.Transaction, error)
 	Withdrawal(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (model.Transaction, error)
+	Transfer(ctx context.Context, accountID uuid.UUID, receiverWalletID uuid.UUID, transaction model.Transaction) (model.Transaction, error)
+	Authorize(ctx context.Context, accountID uuid.UUID, hold model.Hold) (model.Hold, error)
+	Capture(ctx context.Context, accountID uuid.UUID, holdID uuid.UUID, amount int64) (model.Transaction, error)
+	Void(ctx context.Context, accountID uuid.UUID, holdID uuid.UUID) (model.Hold, error)
+	Refund(ctx context.Context, accountID uuid.UUID, transactionID uuid.UUID, amount int64) (model.Transaction, error)
+	Adjust(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (model.Transaction, error)
 }
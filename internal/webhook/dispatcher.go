@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+const (
+	HeaderEventID   = "X-Webhook-Id"
+	HeaderEventType = "X-Webhook-Event"
+	// HeaderSignature holds "t=<unix seconds>,v1=<hex hmac>", the hmac is
+	// SHA256 keyed by the secret over "<unix seconds>.<body>".
+	HeaderSignature = "X-Webhook-Signature"
+
+	defaultMaxAttempts   = 10
+	defaultBaseBackoff   = 10 * time.Second
+	defaultMaxBackoff    = time.Hour
+	defaultBatchSize     = 100
+	defaultTimeout       = 10 * time.Second
+	defaultSweepInterval = 5 * time.Second
+)
+
+// ErrMissingSecret is returned by Config.Validate for endpoints without a
+// secret, their deliveries could not be told apart from forged ones.
+var ErrMissingSecret = errors.New("webhook secret is required when endpoints are configured")
+
+type Config struct {
+	OutboxRepository internal.OutboxRepository
+	HTTPClient       *http.Client
+
+	// Endpoints receive every event, an event is delivered once all of
+	// them answered with a 2xx status.
+	Endpoints []string
+	Secret    string
+
+	// MaxAttempts moves an event to the dead state once reached, retries wait
+	// BaseBackoff doubled per attempt and capped at MaxBackoff.
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	BatchSize   int
+}
+
+// Validate reports a config that would deliver unsigned events.
+func (c Config) Validate() error {
+	if len(c.Endpoints) > 0 && c.Secret == "" {
+		return ErrMissingSecret
+	}
+
+	return nil
+}
+
+type dispatcher struct {
+	cfg Config
+}
+
+func NewDispatcher(cfg Config) *dispatcher {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = defaultBaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+
+	return &dispatcher{cfg: cfg}
+}
+
+// Dispatch delivers the events due at now and returns how many were
+// delivered. Delivery is at least once, subscribers dedupe on the event id.
+// Without endpoints nothing is claimed, the events stay pending until one is
+// configured.
+func (d *dispatcher) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	if len(d.cfg.Endpoints) == 0 {
+		return 0, nil
+	}
+
+	// the lease keeps other dispatchers away while the batch is sent
+	lease := d.cfg.HTTPClient.Timeout * time.Duration(len(d.cfg.Endpoints)*d.cfg.BatchSize+1)
+	if lease <= 0 {
+		lease = d.cfg.MaxBackoff
+	}
+
+	events, err := d.cfg.OutboxRepository.Claim(ctx, now, lease, d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, event := range events {
+		errDeliver := d.deliver(ctx, event)
+		event = d.settle(event, errDeliver, time.Now())
+		err := d.cfg.OutboxRepository.Update(ctx, event)
+		if err != nil {
+			return delivered, err
+		}
+
+		if errDeliver == nil {
+			delivered++
+		}
+	}
+
+	return delivered, nil
+}
+
+// Run dispatches due events every interval until ctx is done.
+func (d *dispatcher) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := d.Dispatch(ctx, now); err != nil {
+				log.Printf("failed dispatch webhooks : %s \n", err)
+			}
+		}
+	}
+}
+
+func (d *dispatcher) settle(event model.Event, errDeliver error, now time.Time) model.Event {
+	event.Attempts++
+	event.UpdatedAt = now
+	if errDeliver == nil {
+		event.Status = model.EventStatus.Delivered
+		event.DeliveredAt = &now
+		event.LastError = nil
+		return event
+	}
+
+	message := errDeliver.Error()
+	event.LastError = &message
+	if event.Attempts >= d.cfg.MaxAttempts {
+		event.Status = model.EventStatus.Dead
+		return event
+	}
+
+	event.NextAttemptAt = now.Add(d.backoff(event.Attempts))
+	return event
+}
+
+func (d *dispatcher) backoff(attempts int) time.Duration {
+	backoff := d.cfg.BaseBackoff
+	for i := 1; i < attempts && backoff < d.cfg.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.cfg.MaxBackoff {
+		backoff = d.cfg.MaxBackoff
+	}
+
+	return backoff
+}
+
+func (d *dispatcher) deliver(ctx context.Context, event model.Event) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"id":         event.ID,
+		"type":       event.Type,
+		"wallet_id":  event.WalletID,
+		"created_at": event.CreatedAt,
+		"data":       event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range d.cfg.Endpoints {
+		err := d.post(ctx, endpoint, event, body)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *dispatcher) post(ctx context.Context, endpoint string, event model.Event, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, event.ID.String())
+	req.Header.Set(HeaderEventType, event.Type)
+	req.Header.Set(HeaderSignature, "t="+timestamp+",v1="+Sign(d.cfg.Secret, timestamp, body))
+
+	res, err := d.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("%s answered %d", endpoint, res.StatusCode)
+	}
+
+	return nil
+}
+
+// Sign returns the hex hmac subscribers compare against the v1 signature.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
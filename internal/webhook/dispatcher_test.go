@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/memory"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	mock "github.com/hokdre/mini-ewallet/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher(t *testing.T) {
+	t.Run("Dispatch", TestDispatch)
+	t.Run("Backoff", TestBackoff)
+	t.Run("Validate", TestConfigValidate)
+}
+
+func TestDispatch(t *testing.T) {
+	newEvent := func() model.Event {
+		return model.Event{
+			ID:       uuid.New(),
+			Type:     model.EventType.TransactionSucceeded,
+			WalletID: uuid.New(),
+			Payload:  json.RawMessage(`{"amount":1000}`),
+			Status:   model.EventStatus.Pending,
+		}
+	}
+
+	t.Run("delivered with signature", func(t *testing.T) {
+		event := newEvent()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, event.ID.String(), r.Header.Get(HeaderEventID))
+			assert.Equal(t, event.Type, r.Header.Get(HeaderEventType))
+
+			parts := strings.Split(r.Header.Get(HeaderSignature), ",")
+			assert.Len(t, parts, 2)
+			timestamp := strings.TrimPrefix(parts[0], "t=")
+			assert.Equal(t, "v1="+Sign("secret", timestamp, body), parts[1])
+
+			payload := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(body, &payload))
+			assert.Equal(t, map[string]interface{}{"amount": float64(1000)}, payload["data"])
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		ctrl := gomock.NewController(t)
+		now := time.Now()
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().Claim(gomock.Any(), now, gomock.Any(), defaultBatchSize).
+			Return([]model.Event{event}, nil).Times(1)
+		outboxRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, event model.Event) error {
+				assert.Equal(t, model.EventStatus.Delivered, event.Status)
+				assert.Equal(t, 1, event.Attempts)
+				assert.NotNil(t, event.DeliveredAt)
+				assert.Nil(t, event.LastError)
+				return nil
+			}).Times(1)
+
+		d := NewDispatcher(Config{
+			OutboxRepository: outboxRepo,
+			Endpoints:        []string{server.URL},
+			Secret:           "secret",
+		})
+		delivered, err := d.Dispatch(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+	})
+
+	t.Run("failed delivery is retried later", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		event := newEvent()
+		event.Attempts = 2
+
+		ctrl := gomock.NewController(t)
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]model.Event{event}, nil).Times(1)
+		outboxRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, event model.Event) error {
+				assert.Equal(t, model.EventStatus.Pending, event.Status)
+				assert.Equal(t, 3, event.Attempts)
+				assert.NotNil(t, event.LastError)
+				assert.WithinDuration(t, time.Now().Add(4*time.Second), event.NextAttemptAt, time.Second)
+				return nil
+			}).Times(1)
+
+		d := NewDispatcher(Config{
+			OutboxRepository: outboxRepo,
+			Endpoints:        []string{server.URL},
+			BaseBackoff:      time.Second,
+		})
+		delivered, err := d.Dispatch(context.Background(), time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, delivered)
+	})
+
+	t.Run("dead after max attempts", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		event := newEvent()
+		event.Attempts = 4
+
+		ctrl := gomock.NewController(t)
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]model.Event{event}, nil).Times(1)
+		outboxRepo.EXPECT().Update(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, event model.Event) error {
+				assert.Equal(t, model.EventStatus.Dead, event.Status)
+				assert.Equal(t, 5, event.Attempts)
+				return nil
+			}).Times(1)
+
+		d := NewDispatcher(Config{
+			OutboxRepository: outboxRepo,
+			Endpoints:        []string{server.URL},
+			MaxAttempts:      5,
+		})
+		delivered, err := d.Dispatch(context.Background(), time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, delivered)
+	})
+
+	t.Run("failed claim", func(t *testing.T) {
+		errExpected := errors.New("err")
+		ctrl := gomock.NewController(t)
+		outboxRepo := mock.NewMockOutboxRepository(ctrl)
+		outboxRepo.EXPECT().Claim(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, errExpected).Times(1)
+
+		d := NewDispatcher(Config{
+			OutboxRepository: outboxRepo,
+			Endpoints:        []string{"http://localhost/hook"},
+			Secret:           "secret",
+		})
+		delivered, err := d.Dispatch(context.Background(), time.Now())
+		assert.ErrorIs(t, err, errExpected)
+		assert.Equal(t, 0, delivered)
+	})
+
+	t.Run("no endpoints leaves events pending", func(t *testing.T) {
+		ctx := context.Background()
+		outboxRepo := memory.NewOutboxRepository(memory.NewStore())
+		event := newEvent()
+		assert.NoError(t, outboxRepo.CreateTx(ctx, nil, event))
+
+		d := NewDispatcher(Config{OutboxRepository: outboxRepo})
+		delivered, err := d.Dispatch(ctx, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, 0, delivered)
+
+		pending, err := outboxRepo.Claim(ctx, time.Now(), time.Minute, defaultBatchSize)
+		assert.NoError(t, err)
+		assert.Len(t, pending, 1)
+		assert.Equal(t, event.ID, pending[0].ID)
+		assert.Equal(t, model.EventStatus.Pending, pending[0].Status)
+		assert.Equal(t, 0, pending[0].Attempts)
+		assert.Nil(t, pending[0].DeliveredAt)
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Endpoints: []string{"http://localhost/hook"}, Secret: "secret"}.Validate())
+	assert.ErrorIs(t, Config{Endpoints: []string{"http://localhost/hook"}}.Validate(), ErrMissingSecret)
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(Config{
+		BaseBackoff: time.Second,
+		MaxBackoff:  10 * time.Second,
+	})
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 8*time.Second, d.backoff(4))
+	assert.Equal(t, 10*time.Second, d.backoff(5))
+	assert.Equal(t, 10*time.Second, d.backoff(60))
+}
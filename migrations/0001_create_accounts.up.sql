@@ -0,0 +1,9 @@
+CREATE TABLE accounts (
+    id VARCHAR(36) NOT NULL,
+    external_id VARCHAR(36) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    is_active BOOLEAN DEFAULT 'true',
+    PRIMARY KEY(id)
+);
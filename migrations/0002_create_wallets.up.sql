@@ -0,0 +1,15 @@
+CREATE TABLE wallets (
+    id VARCHAR(36) NOT NULL,
+    owned_by VARCHAR(36) UNIQUE NOT NULL,
+    balance NUMERIC NOT NULL,
+    reserved NUMERIC NOT NULL DEFAULT 0,
+    status VARCHAR(255) NOT NULL,
+    enabled_at TIMESTAMP NULL,
+    disabled_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    is_active BOOLEAN DEFAULT 'true',
+    PRIMARY KEY(id),
+    FOREIGN KEY (owned_by) REFERENCES accounts(id)
+);
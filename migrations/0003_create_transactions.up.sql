@@ -0,0 +1,22 @@
+CREATE TABLE transactions (
+    id VARCHAR(36) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL,
+    type VARCHAR(255) NOT NULL,
+    status VARCHAR(255) NOT NULL,
+    reference_id VARCHAR(255) NOT NULL,
+    amount NUMERIC NOT NULL,
+    fee NUMERIC NOT NULL DEFAULT 0,
+    transacted_at TIMESTAMP NULL,
+    parent_id VARCHAR(36) NULL,
+    refunded_amount NUMERIC NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    deleted_at TIMESTAMP NULL,
+    is_active BOOLEAN DEFAULT 'true',
+    PRIMARY KEY(id),
+    UNIQUE (wallet_id, reference_id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id),
+    FOREIGN KEY (parent_id) REFERENCES transactions(id)
+);
+
+CREATE INDEX transactions_wallet_id_created_at ON transactions(wallet_id, created_at DESC, id DESC);
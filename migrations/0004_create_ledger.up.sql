@@ -0,0 +1,21 @@
+CREATE TABLE journal_entries (
+    id VARCHAR(36) NOT NULL,
+    transaction_id VARCHAR(36) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+CREATE TABLE postings (
+    id VARCHAR(36) NOT NULL,
+    journal_entry_id VARCHAR(36) NOT NULL,
+    account VARCHAR(255) NOT NULL,
+    wallet_id VARCHAR(36) NULL,
+    amount NUMERIC NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(id),
+    FOREIGN KEY (journal_entry_id) REFERENCES journal_entries(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE INDEX postings_account_wallet_id ON postings(account, wallet_id);
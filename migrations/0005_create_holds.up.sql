@@ -0,0 +1,16 @@
+CREATE TABLE holds (
+    id VARCHAR(36) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL,
+    amount NUMERIC NOT NULL,
+    captured_amount NUMERIC NOT NULL DEFAULT 0,
+    status VARCHAR(255) NOT NULL,
+    reference_id VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(id),
+    UNIQUE (wallet_id, reference_id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE INDEX holds_status_expires_at ON holds(status, expires_at);
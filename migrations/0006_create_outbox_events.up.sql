@@ -0,0 +1,17 @@
+CREATE TABLE outbox_events (
+    id VARCHAR(36) NOT NULL,
+    type VARCHAR(255) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(255) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL,
+    last_error TEXT NULL,
+    delivered_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE INDEX outbox_events_status_next_attempt_at ON outbox_events(status, next_attempt_at);
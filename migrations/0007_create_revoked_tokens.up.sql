@@ -0,0 +1,10 @@
+CREATE TABLE revoked_tokens (
+    id VARCHAR(36) NOT NULL,
+    account_id VARCHAR(36) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(id),
+    FOREIGN KEY (account_id) REFERENCES accounts(id)
+);
+
+CREATE INDEX revoked_tokens_expires_at ON revoked_tokens(expires_at);
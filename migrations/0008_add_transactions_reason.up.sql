@@ -0,0 +1 @@
+ALTER TABLE transactions ADD COLUMN reason TEXT NOT NULL DEFAULT '';
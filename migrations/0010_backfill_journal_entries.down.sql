@@ -0,0 +1,2 @@
+DELETE FROM postings WHERE journal_entry_id IN (SELECT id FROM journal_entries WHERE id = transaction_id);
+DELETE FROM journal_entries WHERE id = transaction_id;
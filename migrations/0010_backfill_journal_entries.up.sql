@@ -0,0 +1,39 @@
+-- Transactions settled before the ledger existed get the journal entry they
+-- would be booked with today, so the postings explain every balance they
+-- moved. The entry and its wallet posting take the id of the transaction,
+-- the counter posting takes it with a leading 'o' that no generated id has.
+INSERT INTO journal_entries (id, transaction_id, created_at)
+SELECT t.id, t.id, COALESCE(t.transacted_at, t.created_at)
+FROM transactions t
+WHERE t.status = 'success'
+AND NOT EXISTS (SELECT 1 FROM journal_entries j WHERE j.transaction_id = t.id);
+
+INSERT INTO postings (id, journal_entry_id, account, wallet_id, amount, created_at)
+SELECT
+    j.id,
+    j.id,
+    'wallet',
+    t.wallet_id,
+    CASE WHEN t.type IN ('deposit', 'transfer_in', 'refund', 'adjustment_credit') THEN t.amount ELSE -t.amount END,
+    j.created_at
+FROM journal_entries j
+JOIN transactions t ON t.id = j.transaction_id
+WHERE j.id = j.transaction_id;
+
+INSERT INTO postings (id, journal_entry_id, account, wallet_id, amount, created_at)
+SELECT
+    'o' || substr(j.id, 2),
+    j.id,
+    CASE
+        WHEN t.type IN ('deposit', 'reversal') THEN 'cash-in'
+        WHEN t.type IN ('transfer_in', 'transfer_out') THEN 'transfer-clearing'
+        WHEN t.type = 'fee' THEN 'fees'
+        WHEN t.type IN ('adjustment_credit', 'adjustment_debit') THEN 'adjustments'
+        ELSE 'cash-out'
+    END,
+    NULL,
+    CASE WHEN t.type IN ('deposit', 'transfer_in', 'refund', 'adjustment_credit') THEN -t.amount ELSE t.amount END,
+    j.created_at
+FROM journal_entries j
+JOIN transactions t ON t.id = j.transaction_id
+WHERE j.id = j.transaction_id;
@@ -0,0 +1,2 @@
+DROP TABLE audit_logs;
+ALTER TABLE transactions DROP COLUMN operator;
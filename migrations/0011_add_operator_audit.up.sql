@@ -0,0 +1,13 @@
+ALTER TABLE transactions ADD COLUMN operator TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE audit_logs (
+    id VARCHAR(36) NOT NULL,
+    wallet_id VARCHAR(36) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    operator TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE INDEX audit_logs_wallet_id_created_at ON audit_logs(wallet_id, created_at);
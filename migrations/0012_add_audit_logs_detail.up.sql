@@ -0,0 +1 @@
+ALTER TABLE audit_logs ADD COLUMN detail TEXT NOT NULL DEFAULT '';
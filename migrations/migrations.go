@@ -0,0 +1,8 @@
+// Package migrations embeds the numbered schema migrations, run them with
+// cmd/migrate.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
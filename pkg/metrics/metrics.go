@@ -0,0 +1,116 @@
+// Package metrics holds the prometheus collectors of the wallet, they are
+// served on /metrics by the rest server.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wallet"
+
+// Registry is used instead of the prometheus default registry so nothing
+// registered by a dependency ends up on /metrics unnoticed.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route and status code.",
+		},
+		[]string{"method", "route", "status"},
+	)
+	httpDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method and route.",
+			Buckets:   prometheus.DefBuckets,
+		},
+		[]string{"method", "route"},
+	)
+
+	transactions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transactions_total",
+			Help:      "Settled transactions by type and status.",
+		},
+		[]string{"type", "status"},
+	)
+	transactionAmount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_amount_total",
+			Help:      "Amount moved by successful transactions, fees excluded.",
+		},
+		[]string{"type"},
+	)
+	transactionFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transaction_failures_total",
+			Help:      "Transactions that were rejected or did not succeed, by type and reason.",
+		},
+		[]string{"type", "reason"},
+	)
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		transactions,
+		transactionAmount,
+		transactionFailures,
+	)
+}
+
+// Handler serves every collector of Registry in the prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDB exports the connection pool stats of db, name tells the pools
+// apart when there are several.
+func RegisterDB(db *sql.DB, name string) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveRequest records a served request, route is the registered path
+// rather than the requested one so ids do not blow up the label values.
+func ObserveRequest(method string, route string, status int, duration time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// ObserveTransaction records a settled transaction, a failed one counts as
+// declined.
+func ObserveTransaction(transaction model.Transaction) {
+	transactions.WithLabelValues(transaction.Type, transaction.Status).Inc()
+	switch transaction.Status {
+	case model.TransactionStatus.Success:
+		transactionAmount.WithLabelValues(transaction.Type).Add(float64(transaction.Amount))
+	case model.TransactionStatus.Failed:
+		ObserveFailure(transaction.Type, "declined")
+	}
+}
+
+// ObserveFailure records a transaction of transactionType that was rejected
+// for reason.
+func ObserveFailure(transactionType string, reason string) {
+	transactionFailures.WithLabelValues(transactionType, reason).Inc()
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("ObserveRequest", TestObserveRequest)
+	t.Run("ObserveTransaction", TestObserveTransaction)
+	t.Run("Handler", TestHandler)
+}
+
+func TestObserveRequest(t *testing.T) {
+	before := testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodPost, "/api/v1/wallet/deposits", "201"))
+	ObserveRequest(http.MethodPost, "/api/v1/wallet/deposits", http.StatusCreated, 30*time.Millisecond)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues(http.MethodPost, "/api/v1/wallet/deposits", "201")))
+	assert.Equal(t, 1, testutil.CollectAndCount(httpDuration, "wallet_http_request_duration_seconds"))
+}
+
+func TestObserveTransaction(t *testing.T) {
+	withdrawal := model.Transaction{
+		Type:   model.TransactionType.Withdrawal,
+		Status: model.TransactionStatus.Success,
+		Amount: 1500,
+		Fee:    100,
+	}
+	amount := testutil.ToFloat64(transactionAmount.WithLabelValues(withdrawal.Type))
+	declined := testutil.ToFloat64(transactionFailures.WithLabelValues(withdrawal.Type, "declined"))
+	failed := testutil.ToFloat64(transactions.WithLabelValues(withdrawal.Type, model.TransactionStatus.Failed))
+
+	ObserveTransaction(withdrawal)
+	assert.Equal(t, amount+1500, testutil.ToFloat64(transactionAmount.WithLabelValues(withdrawal.Type)))
+	assert.Equal(t, declined, testutil.ToFloat64(transactionFailures.WithLabelValues(withdrawal.Type, "declined")))
+
+	withdrawal.Status = model.TransactionStatus.Failed
+	ObserveTransaction(withdrawal)
+	assert.Equal(t, amount+1500, testutil.ToFloat64(transactionAmount.WithLabelValues(withdrawal.Type)))
+	assert.Equal(t, declined+1, testutil.ToFloat64(transactionFailures.WithLabelValues(withdrawal.Type, "declined")))
+	assert.Equal(t, failed+1, testutil.ToFloat64(transactions.WithLabelValues(withdrawal.Type, model.TransactionStatus.Failed)))
+}
+
+func TestHandler(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	// a pool is registered once per name, the suite runs this test twice
+	assert.NoError(t, RegisterDB(db, t.Name()))
+	ObserveFailure(model.TransactionType.Deposit, "wallet_disabled")
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `wallet_transaction_failures_total{reason="wallet_disabled",type="deposit"}`)
+	assert.Contains(t, rec.Body.String(), `go_sql_open_connections{db_name="`+t.Name()+`"}`)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+}
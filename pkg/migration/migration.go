@@ -0,0 +1,324 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// lockID keys the postgres advisory lock held while migrating.
+	lockID = 7164319
+
+	qCreateTable = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL,
+			PRIMARY KEY(version)
+		)
+	`
+
+	qTableExists = `
+		SELECT to_regclass('schema_migrations') IS NOT NULL
+	`
+
+	qLock = `
+		SELECT pg_advisory_lock($1)
+	`
+
+	qUnlock = `
+		SELECT pg_advisory_unlock($1)
+	`
+
+	qApplied = `
+		SELECT version, applied_at FROM schema_migrations ORDER BY version ASC
+	`
+
+	qInsert = `
+		INSERT INTO schema_migrations (
+			version, name, applied_at
+		) VALUES(
+			$1, $2, $3
+		)
+	`
+
+	qDelete = `
+		DELETE FROM schema_migrations WHERE version = $1
+	`
+)
+
+var (
+	ErrNoDownMigration = errors.New("migration has no down script")
+
+	fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	// AppliedAt is nil while the migration is pending.
+	AppliedAt *time.Time
+}
+
+// Load reads "<version>_<name>.up.sql" and the optional
+// "<version>_<name>.down.sql" files of fsys, ordered by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		script, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("version %d used by %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+type migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+func New(db *sql.DB, migrations []Migration) *migrator {
+	return &migrator{db: db, migrations: migrations}
+}
+
+// Up applies every pending migration in version order, each in its own
+// transaction, and returns the applied ones.
+func (m *migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied := []Migration{}
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+
+			err := m.run(ctx, conn, migration.Up, qInsert, migration.Version, migration.Name, time.Now())
+			if err != nil {
+				return fmt.Errorf("failed migrate up %d_%s : %w", migration.Version, migration.Name, err)
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the latest steps applied migrations and returns them.
+func (m *migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	reverted := []Migration{}
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		done, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("%w : %d_%s", ErrNoDownMigration, migration.Version, migration.Name)
+			}
+
+			err := m.run(ctx, conn, migration.Down, qDelete, migration.Version)
+			if err != nil {
+				return fmt.Errorf("failed migrate down %d_%s : %w", migration.Version, migration.Name, err)
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration with the time it was applied, it
+// neither takes the lock nor creates the tracking table.
+func (m *migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var exists bool
+	err = conn.QueryRowContext(ctx, qTableExists).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+
+	done := map[int64]time.Time{}
+	if exists {
+		done, err = m.applied(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	statuses := []Status{}
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if appliedAt, ok := done[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Pending returns the migrations not applied yet.
+func (m *migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []Migration{}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending = append(pending, status.Migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// withLock runs fn on a single connection holding the advisory lock, so a
+// second migrate waits instead of applying the same scripts twice.
+func (m *migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, qLock, lockID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), qUnlock, lockID); err != nil {
+			// drop the session so the pool never hands out a connection
+			// still holding the lock
+			_ = conn.Raw(func(driverConn interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, qCreateTable)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *migrator) applied(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, qApplied)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// run executes script and the bookkeeping query in one transaction.
+func (m *migrator) run(ctx context.Context, conn *sql.Conn, script string, query string, args ...interface{}) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, script)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
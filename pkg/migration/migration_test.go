@@ -0,0 +1,183 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/hokdre/mini-ewallet/migrations"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigration(t *testing.T) {
+	t.Run("Load", TestLoad)
+	t.Run("Up", TestUp)
+	t.Run("Down", TestDown)
+	t.Run("Status", TestStatus)
+}
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "create_accounts", Up: "CREATE TABLE accounts ();", Down: "DROP TABLE accounts;"},
+	{Version: 2, Name: "create_wallets", Up: "CREATE TABLE wallets ();", Down: "DROP TABLE wallets;"},
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		migrations, err := Load(fstest.MapFS{
+			"0002_create_wallets.up.sql":    {Data: []byte("CREATE TABLE wallets ();")},
+			"0002_create_wallets.down.sql":  {Data: []byte("DROP TABLE wallets;")},
+			"0001_create_accounts.up.sql":   {Data: []byte("CREATE TABLE accounts ();")},
+			"0001_create_accounts.down.sql": {Data: []byte("DROP TABLE accounts;")},
+			"README.md":                     {Data: []byte("ignored")},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, testMigrations, migrations)
+	})
+
+	t.Run("missing up script", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{
+			"0001_create_accounts.down.sql": {Data: []byte("DROP TABLE accounts;")},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("version reused", func(t *testing.T) {
+		_, err := Load(fstest.MapFS{
+			"0001_create_accounts.up.sql": {Data: []byte("CREATE TABLE accounts ();")},
+			"0001_create_wallets.up.sql":  {Data: []byte("CREATE TABLE wallets ();")},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("embedded migrations", func(t *testing.T) {
+		loaded, err := Load(migrations.FS)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, loaded)
+		for i, m := range loaded {
+			assert.Equal(t, int64(i+1), m.Version)
+			assert.NotEmpty(t, m.Down)
+		}
+	})
+}
+
+func TestUp(t *testing.T) {
+	t.Run("applies pending only", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(qLock).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(qCreateTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(qApplied).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec(testMigrations[1].Up).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(qInsert).
+			WithArgs(int64(2), "create_wallets", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec(qUnlock).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		applied, err := New(db, testMigrations).Up(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, testMigrations[1:], applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("failed script rolls back and unlocks", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		errExpected := errors.New("err")
+		mock.ExpectExec(qLock).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(qCreateTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(qApplied).WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}))
+		mock.ExpectBegin()
+		mock.ExpectExec(testMigrations[0].Up).WillReturnError(errExpected)
+		mock.ExpectRollback()
+		mock.ExpectExec(qUnlock).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		applied, err := New(db, testMigrations).Up(context.Background())
+		assert.ErrorIs(t, err, errExpected)
+		assert.Empty(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDown(t *testing.T) {
+	t.Run("reverts latest applied", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(qLock).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(qCreateTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(qApplied).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec(testMigrations[0].Down).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(qDelete).WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectExec(qUnlock).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		reverted, err := New(db, testMigrations).Down(context.Background(), 5)
+		assert.NoError(t, err)
+		assert.Equal(t, testMigrations[:1], reverted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("no down script", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(qLock).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(qCreateTable).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(qApplied).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, time.Now()))
+		mock.ExpectExec(qUnlock).WithArgs(lockID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		irreversible := []Migration{{Version: 1, Name: "create_accounts", Up: "CREATE TABLE accounts ();"}}
+		_, err = New(db, irreversible).Down(context.Background(), 1)
+		assert.ErrorIs(t, err, ErrNoDownMigration)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("fresh database", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectQuery(qTableExists).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+		pending, err := New(db, testMigrations).Pending(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, testMigrations, pending)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("partially applied", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		assert.NoError(t, err)
+		defer db.Close()
+
+		appliedAt := time.Now()
+		mock.ExpectQuery(qTableExists).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectQuery(qApplied).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(1, appliedAt))
+
+		statuses, err := New(db, testMigrations).Status(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []Status{
+			{Migration: testMigrations[0], AppliedAt: &appliedAt},
+			{Migration: testMigrations[1]},
+		}, statuses)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
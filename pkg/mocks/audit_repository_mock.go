@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/audit_repository.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        context "context"
+        sql "database/sql"
+        reflect "reflect"
+
+        gomock "github.com/golang/mock/gomock"
+        model "github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+        ctrl     *gomock.Controller
+        recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+        mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+        mock := &MockAuditRepository{ctrl: ctrl}
+        mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+        return m.recorder
+}
+
+// CreateTx mocks base method.
+func (m *MockAuditRepository) CreateTx(ctx context.Context, tx *sql.Tx, entry model.AuditEntry) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "CreateTx", ctx, tx, entry)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// CreateTx indicates an expected call of CreateTx.
+func (mr *MockAuditRepositoryMockRecorder) CreateTx(ctx, tx, entry interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTx", reflect.TypeOf((*MockAuditRepository)(nil).CreateTx), ctx, tx, entry)
+}
+
+// List mocks base method.
+func (m *MockAuditRepository) List(ctx context.Context, walletID string) ([]model.AuditEntry, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "List", ctx, walletID)
+        ret0, _ := ret[0].([]model.AuditEntry)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditRepositoryMockRecorder) List(ctx, walletID interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditRepository)(nil).List), ctx, walletID)
+}
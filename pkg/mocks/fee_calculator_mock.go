@@ -0,0 +1,48 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/fee_calculator.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        reflect "reflect"
+
+        gomock "github.com/golang/mock/gomock"
+)
+
+// MockFeeCalculator is a mock of FeeCalculator interface.
+type MockFeeCalculator struct {
+        ctrl     *gomock.Controller
+        recorder *MockFeeCalculatorMockRecorder
+}
+
+// MockFeeCalculatorMockRecorder is the mock recorder for MockFeeCalculator.
+type MockFeeCalculatorMockRecorder struct {
+        mock *MockFeeCalculator
+}
+
+// NewMockFeeCalculator creates a new mock instance.
+func NewMockFeeCalculator(ctrl *gomock.Controller) *MockFeeCalculator {
+        mock := &MockFeeCalculator{ctrl: ctrl}
+        mock.recorder = &MockFeeCalculatorMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeeCalculator) EXPECT() *MockFeeCalculatorMockRecorder {
+        return m.recorder
+}
+
+// Calculate mocks base method.
+func (m *MockFeeCalculator) Calculate(amount int64) int64 {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Calculate", amount)
+        ret0, _ := ret[0].(int64)
+        return ret0
+}
+
+// Calculate indicates an expected call of Calculate.
+func (mr *MockFeeCalculatorMockRecorder) Calculate(amount interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Calculate", reflect.TypeOf((*MockFeeCalculator)(nil).Calculate), amount)
+}
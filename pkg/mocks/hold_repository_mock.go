@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/hold_repository.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        context "context"
+        sql "database/sql"
+        reflect "reflect"
+
+        gomock "github.com/golang/mock/gomock"
+        internal "github.com/hokdre/mini-ewallet/internal"
+        model "github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// MockHoldRepository is a mock of HoldRepository interface.
+type MockHoldRepository struct {
+        ctrl     *gomock.Controller
+        recorder *MockHoldRepositoryMockRecorder
+}
+
+// MockHoldRepositoryMockRecorder is the mock recorder for MockHoldRepository.
+type MockHoldRepositoryMockRecorder struct {
+        mock *MockHoldRepository
+}
+
+// NewMockHoldRepository creates a new mock instance.
+func NewMockHoldRepository(ctrl *gomock.Controller) *MockHoldRepository {
+        mock := &MockHoldRepository{ctrl: ctrl}
+        mock.recorder = &MockHoldRepositoryMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHoldRepository) EXPECT() *MockHoldRepositoryMockRecorder {
+        return m.recorder
+}
+
+// CreateTx mocks base method.
+func (m *MockHoldRepository) CreateTx(ctx context.Context, tx *sql.Tx, newHold model.Hold) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "CreateTx", ctx, tx, newHold)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// CreateTx indicates an expected call of CreateTx.
+func (mr *MockHoldRepositoryMockRecorder) CreateTx(ctx, tx, newHold interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTx", reflect.TypeOf((*MockHoldRepository)(nil).CreateTx), ctx, tx, newHold)
+}
+
+// GetOne mocks base method.
+func (m *MockHoldRepository) GetOne(ctx context.Context, filter internal.HoldFilter) (model.Hold, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetOne", ctx, filter)
+        ret0, _ := ret[0].(model.Hold)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetOne indicates an expected call of GetOne.
+func (mr *MockHoldRepositoryMockRecorder) GetOne(ctx, filter interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOne", reflect.TypeOf((*MockHoldRepository)(nil).GetOne), ctx, filter)
+}
+
+// List mocks base method.
+func (m *MockHoldRepository) List(ctx context.Context, filter internal.HoldFilter) ([]model.Hold, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "List", ctx, filter)
+        ret0, _ := ret[0].([]model.Hold)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockHoldRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockHoldRepository)(nil).List), ctx, filter)
+}
+
+// UpdateTx mocks base method.
+func (m *MockHoldRepository) UpdateTx(ctx context.Context, tx *sql.Tx, hold model.Hold) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "UpdateTx", ctx, tx, hold)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// UpdateTx indicates an expected call of UpdateTx.
+func (mr *MockHoldRepositoryMockRecorder) UpdateTx(ctx, tx, hold interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTx", reflect.TypeOf((*MockHoldRepository)(nil).UpdateTx), ctx, tx, hold)
+}
@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/ledger_repository.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        context "context"
+        sql "database/sql"
+        reflect "reflect"
+
+        gomock "github.com/golang/mock/gomock"
+        uuid "github.com/google/uuid"
+        model "github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// MockLedgerRepository is a mock of LedgerRepository interface.
+type MockLedgerRepository struct {
+        ctrl     *gomock.Controller
+        recorder *MockLedgerRepositoryMockRecorder
+}
+
+// MockLedgerRepositoryMockRecorder is the mock recorder for MockLedgerRepository.
+type MockLedgerRepositoryMockRecorder struct {
+        mock *MockLedgerRepository
+}
+
+// NewMockLedgerRepository creates a new mock instance.
+func NewMockLedgerRepository(ctrl *gomock.Controller) *MockLedgerRepository {
+        mock := &MockLedgerRepository{ctrl: ctrl}
+        mock.recorder = &MockLedgerRepositoryMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLedgerRepository) EXPECT() *MockLedgerRepositoryMockRecorder {
+        return m.recorder
+}
+
+// GetEntryTx mocks base method.
+func (m *MockLedgerRepository) GetEntryTx(ctx context.Context, tx *sql.Tx, transactionID uuid.UUID) (model.JournalEntry, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetEntryTx", ctx, tx, transactionID)
+        ret0, _ := ret[0].(model.JournalEntry)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetEntryTx indicates an expected call of GetEntryTx.
+func (mr *MockLedgerRepositoryMockRecorder) GetEntryTx(ctx, tx, transactionID interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntryTx", reflect.TypeOf((*MockLedgerRepository)(nil).GetEntryTx), ctx, tx, transactionID)
+}
+
+// GetWalletBalance mocks base method.
+func (m *MockLedgerRepository) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "GetWalletBalance", ctx, walletID)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// GetWalletBalance indicates an expected call of GetWalletBalance.
+func (mr *MockLedgerRepositoryMockRecorder) GetWalletBalance(ctx, walletID interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletBalance", reflect.TypeOf((*MockLedgerRepository)(nil).GetWalletBalance), ctx, walletID)
+}
+
+// PostTx mocks base method.
+func (m *MockLedgerRepository) PostTx(ctx context.Context, tx *sql.Tx, entry model.JournalEntry) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "PostTx", ctx, tx, entry)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// PostTx indicates an expected call of PostTx.
+func (mr *MockLedgerRepositoryMockRecorder) PostTx(ctx, tx, entry interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PostTx", reflect.TypeOf((*MockLedgerRepository)(nil).PostTx), ctx, tx, entry)
+}
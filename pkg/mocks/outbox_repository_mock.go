@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/outbox_repository.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        context "context"
+        sql "database/sql"
+        reflect "reflect"
+        time "time"
+
+        gomock "github.com/golang/mock/gomock"
+        model "github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// MockOutboxRepository is a mock of OutboxRepository interface.
+type MockOutboxRepository struct {
+        ctrl     *gomock.Controller
+        recorder *MockOutboxRepositoryMockRecorder
+}
+
+// MockOutboxRepositoryMockRecorder is the mock recorder for MockOutboxRepository.
+type MockOutboxRepositoryMockRecorder struct {
+        mock *MockOutboxRepository
+}
+
+// NewMockOutboxRepository creates a new mock instance.
+func NewMockOutboxRepository(ctrl *gomock.Controller) *MockOutboxRepository {
+        mock := &MockOutboxRepository{ctrl: ctrl}
+        mock.recorder = &MockOutboxRepositoryMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxRepository) EXPECT() *MockOutboxRepositoryMockRecorder {
+        return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockOutboxRepository) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Event, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Claim", ctx, now, lease, limit)
+        ret0, _ := ret[0].([]model.Event)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockOutboxRepositoryMockRecorder) Claim(ctx, now, lease, limit interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockOutboxRepository)(nil).Claim), ctx, now, lease, limit)
+}
+
+// CreateTx mocks base method.
+func (m *MockOutboxRepository) CreateTx(ctx context.Context, tx *sql.Tx, event model.Event) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "CreateTx", ctx, tx, event)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// CreateTx indicates an expected call of CreateTx.
+func (mr *MockOutboxRepositoryMockRecorder) CreateTx(ctx, tx, event interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTx", reflect.TypeOf((*MockOutboxRepository)(nil).CreateTx), ctx, tx, event)
+}
+
+// Update mocks base method.
+func (m *MockOutboxRepository) Update(ctx context.Context, event model.Event) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Update", ctx, event)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockOutboxRepositoryMockRecorder) Update(ctx, event interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockOutboxRepository)(nil).Update), ctx, event)
+}
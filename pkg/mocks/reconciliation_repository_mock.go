@@ -0,0 +1,83 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/reconciliation_repository.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        context "context"
+        sql "database/sql"
+        reflect "reflect"
+        time "time"
+
+        gomock "github.com/golang/mock/gomock"
+        model "github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// MockReconciliationRepository is a mock of ReconciliationRepository interface.
+type MockReconciliationRepository struct {
+        ctrl     *gomock.Controller
+        recorder *MockReconciliationRepositoryMockRecorder
+}
+
+// MockReconciliationRepositoryMockRecorder is the mock recorder for MockReconciliationRepository.
+type MockReconciliationRepositoryMockRecorder struct {
+        mock *MockReconciliationRepository
+}
+
+// NewMockReconciliationRepository creates a new mock instance.
+func NewMockReconciliationRepository(ctrl *gomock.Controller) *MockReconciliationRepository {
+        mock := &MockReconciliationRepository{ctrl: ctrl}
+        mock.recorder = &MockReconciliationRepositoryMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReconciliationRepository) EXPECT() *MockReconciliationRepositoryMockRecorder {
+        return m.recorder
+}
+
+// BalancesTx mocks base method.
+func (m *MockReconciliationRepository) BalancesTx(ctx context.Context, tx *sql.Tx) ([]model.WalletBalance, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "BalancesTx", ctx, tx)
+        ret0, _ := ret[0].([]model.WalletBalance)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// BalancesTx indicates an expected call of BalancesTx.
+func (mr *MockReconciliationRepositoryMockRecorder) BalancesTx(ctx, tx interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalancesTx", reflect.TypeOf((*MockReconciliationRepository)(nil).BalancesTx), ctx, tx)
+}
+
+// OrphansTx mocks base method.
+func (m *MockReconciliationRepository) OrphansTx(ctx context.Context, tx *sql.Tx) ([]model.Orphan, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "OrphansTx", ctx, tx)
+        ret0, _ := ret[0].([]model.Orphan)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// OrphansTx indicates an expected call of OrphansTx.
+func (mr *MockReconciliationRepositoryMockRecorder) OrphansTx(ctx, tx interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OrphansTx", reflect.TypeOf((*MockReconciliationRepository)(nil).OrphansTx), ctx, tx)
+}
+
+// PendingTx mocks base method.
+func (m *MockReconciliationRepository) PendingTx(ctx context.Context, tx *sql.Tx, before time.Time) ([]model.Transaction, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "PendingTx", ctx, tx, before)
+        ret0, _ := ret[0].([]model.Transaction)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// PendingTx indicates an expected call of PendingTx.
+func (mr *MockReconciliationRepositoryMockRecorder) PendingTx(ctx, tx, before interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingTx", reflect.TypeOf((*MockReconciliationRepository)(nil).PendingTx), ctx, tx, before)
+}
@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/statement_writer.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        reflect "reflect"
+
+        gomock "github.com/golang/mock/gomock"
+        model "github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// MockStatementWriter is a mock of StatementWriter interface.
+type MockStatementWriter struct {
+        ctrl     *gomock.Controller
+        recorder *MockStatementWriterMockRecorder
+}
+
+// MockStatementWriterMockRecorder is the mock recorder for MockStatementWriter.
+type MockStatementWriterMockRecorder struct {
+        mock *MockStatementWriter
+}
+
+// NewMockStatementWriter creates a new mock instance.
+func NewMockStatementWriter(ctrl *gomock.Controller) *MockStatementWriter {
+        mock := &MockStatementWriter{ctrl: ctrl}
+        mock.recorder = &MockStatementWriterMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatementWriter) EXPECT() *MockStatementWriterMockRecorder {
+        return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockStatementWriter) Begin(statement model.Statement) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Begin", statement)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockStatementWriterMockRecorder) Begin(statement interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockStatementWriter)(nil).Begin), statement)
+}
+
+// End mocks base method.
+func (m *MockStatementWriter) End(statement model.Statement) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "End", statement)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// End indicates an expected call of End.
+func (mr *MockStatementWriterMockRecorder) End(statement interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "End", reflect.TypeOf((*MockStatementWriter)(nil).End), statement)
+}
+
+// Line mocks base method.
+func (m *MockStatementWriter) Line(line model.StatementLine) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Line", line)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// Line indicates an expected call of Line.
+func (mr *MockStatementWriterMockRecorder) Line(line interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Line", reflect.TypeOf((*MockStatementWriter)(nil).Line), line)
+}
@@ -0,0 +1,83 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/token_repository.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        context "context"
+        reflect "reflect"
+        time "time"
+
+        gomock "github.com/golang/mock/gomock"
+        uuid "github.com/google/uuid"
+        model "github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// MockTokenRepository is a mock of TokenRepository interface.
+type MockTokenRepository struct {
+        ctrl     *gomock.Controller
+        recorder *MockTokenRepositoryMockRecorder
+}
+
+// MockTokenRepositoryMockRecorder is the mock recorder for MockTokenRepository.
+type MockTokenRepositoryMockRecorder struct {
+        mock *MockTokenRepository
+}
+
+// NewMockTokenRepository creates a new mock instance.
+func NewMockTokenRepository(ctrl *gomock.Controller) *MockTokenRepository {
+        mock := &MockTokenRepository{ctrl: ctrl}
+        mock.recorder = &MockTokenRepositoryMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRepository) EXPECT() *MockTokenRepositoryMockRecorder {
+        return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockTokenRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "DeleteExpired", ctx, now)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockTokenRepositoryMockRecorder) DeleteExpired(ctx, now interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockTokenRepository)(nil).DeleteExpired), ctx, now)
+}
+
+// IsRevoked mocks base method.
+func (m *MockTokenRepository) IsRevoked(ctx context.Context, tokenID uuid.UUID) (bool, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "IsRevoked", ctx, tokenID)
+        ret0, _ := ret[0].(bool)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockTokenRepositoryMockRecorder) IsRevoked(ctx, tokenID interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockTokenRepository)(nil).IsRevoked), ctx, tokenID)
+}
+
+// Revoke mocks base method.
+func (m *MockTokenRepository) Revoke(ctx context.Context, token model.Token) (bool, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Revoke", ctx, token)
+        ret0, _ := ret[0].(bool)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockTokenRepositoryMockRecorder) Revoke(ctx, token interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockTokenRepository)(nil).Revoke), ctx, token)
+}
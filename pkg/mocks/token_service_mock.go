@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/token_service.go
+
+// Package mock_internal is a generated GoMock package.
+package mock
+
+import (
+        context "context"
+        reflect "reflect"
+
+        gomock "github.com/golang/mock/gomock"
+        uuid "github.com/google/uuid"
+        model "github.com/hokdre/mini-ewallet/internal/model"
+)
+
+// MockTokenService is a mock of TokenService interface.
+type MockTokenService struct {
+        ctrl     *gomock.Controller
+        recorder *MockTokenServiceMockRecorder
+}
+
+// MockTokenServiceMockRecorder is the mock recorder for MockTokenService.
+type MockTokenServiceMockRecorder struct {
+        mock *MockTokenService
+}
+
+// NewMockTokenService creates a new mock instance.
+func NewMockTokenService(ctrl *gomock.Controller) *MockTokenService {
+        mock := &MockTokenService{ctrl: ctrl}
+        mock.recorder = &MockTokenServiceMockRecorder{mock}
+        return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenService) EXPECT() *MockTokenServiceMockRecorder {
+        return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MockTokenService) Issue(ctx context.Context, accountID uuid.UUID) (model.TokenPair, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Issue", ctx, accountID)
+        ret0, _ := ret[0].(model.TokenPair)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MockTokenServiceMockRecorder) Issue(ctx, accountID interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MockTokenService)(nil).Issue), ctx, accountID)
+}
+
+// Refresh mocks base method.
+func (m *MockTokenService) Refresh(ctx context.Context, refreshToken string) (model.TokenPair, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+        ret0, _ := ret[0].(model.TokenPair)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockTokenServiceMockRecorder) Refresh(ctx, refreshToken interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockTokenService)(nil).Refresh), ctx, refreshToken)
+}
+
+// Revoke mocks base method.
+func (m *MockTokenService) Revoke(ctx context.Context, token string) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Revoke", ctx, token)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockTokenServiceMockRecorder) Revoke(ctx, token interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockTokenService)(nil).Revoke), ctx, token)
+}
+
+// Verify mocks base method.
+func (m *MockTokenService) Verify(ctx context.Context, token, tokenType string) (model.Token, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Verify", ctx, token, tokenType)
+        ret0, _ := ret[0].(model.Token)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockTokenServiceMockRecorder) Verify(ctx, token, tokenType interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockTokenService)(nil).Verify), ctx, token, tokenType)
+}
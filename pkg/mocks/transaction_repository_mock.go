@@ -37,6 +37,21 @@ func (m *MockTransactionRepository) EXPECT() *MockTransactionRepositoryMockRecor
         return m.recorder
 }
 
+// AddRefundedTx mocks base method.
+func (m *MockTransactionRepository) AddRefundedTx(ctx context.Context, tx *sql.Tx, transaction model.Transaction, amount int64) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "AddRefundedTx", ctx, tx, transaction, amount)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// AddRefundedTx indicates an expected call of AddRefundedTx.
+func (mr *MockTransactionRepositoryMockRecorder) AddRefundedTx(ctx, tx, transaction, amount interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRefundedTx", reflect.TypeOf((*MockTransactionRepository)(nil).AddRefundedTx), ctx, tx, transaction, amount)
+}
+
 // Create mocks base method.
 func (m *MockTransactionRepository) Create(ctx context.Context, newTransaction model.Transaction) error {
         m.ctrl.T.Helper()
@@ -51,19 +66,18 @@ func (mr *MockTransactionRepositoryMockRecorder) Create(ctx, newTransaction inte
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTransactionRepository)(nil).Create), ctx, newTransaction)
 }
 
-// Get mocks base method.
-func (m *MockTransactionRepository) Get(ctx context.Context, filter internal.TransactionFilter) (model.Transaction, error) {
+// CreateTx mocks base method.
+func (m *MockTransactionRepository) CreateTx(ctx context.Context, tx *sql.Tx, newTransaction model.Transaction) error {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "Get", ctx, filter)
-        ret0, _ := ret[0].(model.Transaction)
-        ret1, _ := ret[1].(error)
-        return ret0, ret1
+        ret := m.ctrl.Call(m, "CreateTx", ctx, tx, newTransaction)
+        ret0, _ := ret[0].(error)
+        return ret0
 }
 
-// Get indicates an expected call of Get.
-func (mr *MockTransactionRepositoryMockRecorder) Get(ctx, filter interface{}) *gomock.Call {
+// CreateTx indicates an expected call of CreateTx.
+func (mr *MockTransactionRepositoryMockRecorder) CreateTx(ctx, tx, newTransaction interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockTransactionRepository)(nil).Get), ctx, filter)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTx", reflect.TypeOf((*MockTransactionRepository)(nil).CreateTx), ctx, tx, newTransaction)
 }
 
 // List mocks base method.
@@ -81,6 +95,36 @@ func (mr *MockTransactionRepositoryMockRecorder) List(ctx, filter interface{}) *
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockTransactionRepository)(nil).List), ctx, filter)
 }
 
+// Summarize mocks base method.
+func (m *MockTransactionRepository) Summarize(ctx context.Context, filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Summarize", ctx, filter)
+        ret0, _ := ret[0].(internal.TransactionSummary)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Summarize indicates an expected call of Summarize.
+func (mr *MockTransactionRepositoryMockRecorder) Summarize(ctx, filter interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summarize", reflect.TypeOf((*MockTransactionRepository)(nil).Summarize), ctx, filter)
+}
+
+// SummarizeTx mocks base method.
+func (m *MockTransactionRepository) SummarizeTx(ctx context.Context, tx *sql.Tx, filter internal.TransactionFilter) (internal.TransactionSummary, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "SummarizeTx", ctx, tx, filter)
+        ret0, _ := ret[0].(internal.TransactionSummary)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// SummarizeTx indicates an expected call of SummarizeTx.
+func (mr *MockTransactionRepositoryMockRecorder) SummarizeTx(ctx, tx, filter interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SummarizeTx", reflect.TypeOf((*MockTransactionRepository)(nil).SummarizeTx), ctx, tx, filter)
+}
+
 // UpdateTx mocks base method.
 func (m *MockTransactionRepository) UpdateTx(ctx context.Context, tx *sql.Tx, transaction model.Transaction) error {
         m.ctrl.T.Helper()
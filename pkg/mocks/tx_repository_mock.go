@@ -10,6 +10,7 @@ import (
         reflect "reflect"
 
         gomock "github.com/golang/mock/gomock"
+        internal "github.com/hokdre/mini-ewallet/internal"
 )
 
 // MockTxRepository is a mock of TxRepository interface.
@@ -36,15 +37,20 @@ func (m *MockTxRepository) EXPECT() *MockTxRepositoryMockRecorder {
 }
 
 // Process mocks base method.
-func (m *MockTxRepository) Process(ctx context.Context, f func(context.Context, *sql.Tx) error) error {
+func (m *MockTxRepository) Process(ctx context.Context, f func(context.Context, *sql.Tx) error, opts ...internal.TxOption) error {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "Process", ctx, f)
+        varargs := []interface{}{ctx, f}
+        for _, a := range opts {
+                varargs = append(varargs, a)
+        }
+        ret := m.ctrl.Call(m, "Process", varargs...)
         ret0, _ := ret[0].(error)
         return ret0
 }
 
 // Process indicates an expected call of Process.
-func (mr *MockTxRepositoryMockRecorder) Process(ctx, f interface{}) *gomock.Call {
+func (mr *MockTxRepositoryMockRecorder) Process(ctx, f interface{}, opts ...interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockTxRepository)(nil).Process), ctx, f)
+        varargs := append([]interface{}{ctx, f}, opts...)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockTxRepository)(nil).Process), varargs...)
 }
@@ -10,6 +10,7 @@ import (
         reflect "reflect"
 
         gomock "github.com/golang/mock/gomock"
+        uuid "github.com/google/uuid"
         internal "github.com/hokdre/mini-ewallet/internal"
         model "github.com/hokdre/mini-ewallet/internal/model"
 )
@@ -37,6 +38,21 @@ func (m *MockWalletRepository) EXPECT() *MockWalletRepositoryMockRecorder {
         return m.recorder
 }
 
+// Capture mocks base method.
+func (m *MockWalletRepository) Capture(ctx context.Context, tx *sql.Tx, wallet model.Wallet, reserved, amount int64) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Capture", ctx, tx, wallet, reserved, amount)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockWalletRepositoryMockRecorder) Capture(ctx, tx, wallet, reserved, amount interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockWalletRepository)(nil).Capture), ctx, tx, wallet, reserved, amount)
+}
+
 // CreateTx mocks base method.
 func (m *MockWalletRepository) CreateTx(ctx context.Context, tx *sql.Tx, newWallet model.Wallet) error {
         m.ctrl.T.Helper()
@@ -82,18 +98,63 @@ func (mr *MockWalletRepositoryMockRecorder) GetOne(ctx, filter interface{}) *gom
 }
 
 // Increment mocks base method.
-func (m *MockWalletRepository) Increment(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+func (m *MockWalletRepository) Increment(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount, maxBalance int64) (int64, error) {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "Increment", ctx, tx, wallet, amount)
+        ret := m.ctrl.Call(m, "Increment", ctx, tx, wallet, amount, maxBalance)
         ret0, _ := ret[0].(int64)
         ret1, _ := ret[1].(error)
         return ret0, ret1
 }
 
 // Increment indicates an expected call of Increment.
-func (mr *MockWalletRepositoryMockRecorder) Increment(ctx, tx, wallet, amount interface{}) *gomock.Call {
+func (mr *MockWalletRepositoryMockRecorder) Increment(ctx, tx, wallet, amount, maxBalance interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockWalletRepository)(nil).Increment), ctx, tx, wallet, amount)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Increment", reflect.TypeOf((*MockWalletRepository)(nil).Increment), ctx, tx, wallet, amount, maxBalance)
+}
+
+// LockTx mocks base method.
+func (m *MockWalletRepository) LockTx(ctx context.Context, tx *sql.Tx, walletID uuid.UUID) (model.Wallet, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "LockTx", ctx, tx, walletID)
+        ret0, _ := ret[0].(model.Wallet)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// LockTx indicates an expected call of LockTx.
+func (mr *MockWalletRepositoryMockRecorder) LockTx(ctx, tx, walletID interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockTx", reflect.TypeOf((*MockWalletRepository)(nil).LockTx), ctx, tx, walletID)
+}
+
+// Release mocks base method.
+func (m *MockWalletRepository) Release(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Release", ctx, tx, wallet, amount)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Release indicates an expected call of Release.
+func (mr *MockWalletRepositoryMockRecorder) Release(ctx, tx, wallet, amount interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Release", reflect.TypeOf((*MockWalletRepository)(nil).Release), ctx, tx, wallet, amount)
+}
+
+// Reserve mocks base method.
+func (m *MockWalletRepository) Reserve(ctx context.Context, tx *sql.Tx, wallet model.Wallet, amount int64) (int64, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Reserve", ctx, tx, wallet, amount)
+        ret0, _ := ret[0].(int64)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Reserve indicates an expected call of Reserve.
+func (mr *MockWalletRepositoryMockRecorder) Reserve(ctx, tx, wallet, amount interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reserve", reflect.TypeOf((*MockWalletRepository)(nil).Reserve), ctx, tx, wallet, amount)
 }
 
 // Update mocks base method.
@@ -108,4 +169,18 @@ func (m *MockWalletRepository) Update(ctx context.Context, wallet model.Wallet)
 func (mr *MockWalletRepositoryMockRecorder) Update(ctx, wallet interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockWalletRepository)(nil).Update), ctx, wallet)
-}
\ No newline at end of file
+}
+
+// UpdateTx mocks base method.
+func (m *MockWalletRepository) UpdateTx(ctx context.Context, tx *sql.Tx, wallet model.Wallet) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "UpdateTx", ctx, tx, wallet)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// UpdateTx indicates an expected call of UpdateTx.
+func (mr *MockWalletRepositoryMockRecorder) UpdateTx(ctx, tx, wallet interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTx", reflect.TypeOf((*MockWalletRepository)(nil).UpdateTx), ctx, tx, wallet)
+}
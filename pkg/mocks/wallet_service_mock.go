@@ -7,9 +7,11 @@ package mock
 import (
         context "context"
         reflect "reflect"
+        time "time"
 
         gomock "github.com/golang/mock/gomock"
         uuid "github.com/google/uuid"
+        internal "github.com/hokdre/mini-ewallet/internal"
         model "github.com/hokdre/mini-ewallet/internal/model"
 )
 
@@ -36,6 +38,51 @@ func (m *MockWalletService) EXPECT() *MockWalletServiceMockRecorder {
         return m.recorder
 }
 
+// Adjust mocks base method.
+func (m *MockWalletService) Adjust(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (model.Transaction, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Adjust", ctx, accountID, transaction)
+        ret0, _ := ret[0].(model.Transaction)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Adjust indicates an expected call of Adjust.
+func (mr *MockWalletServiceMockRecorder) Adjust(ctx, accountID, transaction interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjust", reflect.TypeOf((*MockWalletService)(nil).Adjust), ctx, accountID, transaction)
+}
+
+// Authorize mocks base method.
+func (m *MockWalletService) Authorize(ctx context.Context, accountID uuid.UUID, hold model.Hold) (model.Hold, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Authorize", ctx, accountID, hold)
+        ret0, _ := ret[0].(model.Hold)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Authorize indicates an expected call of Authorize.
+func (mr *MockWalletServiceMockRecorder) Authorize(ctx, accountID, hold interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authorize", reflect.TypeOf((*MockWalletService)(nil).Authorize), ctx, accountID, hold)
+}
+
+// Capture mocks base method.
+func (m *MockWalletService) Capture(ctx context.Context, accountID, holdID uuid.UUID, amount int64) (model.Transaction, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Capture", ctx, accountID, holdID, amount)
+        ret0, _ := ret[0].(model.Transaction)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Capture indicates an expected call of Capture.
+func (mr *MockWalletServiceMockRecorder) Capture(ctx, accountID, holdID, amount interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capture", reflect.TypeOf((*MockWalletService)(nil).Capture), ctx, accountID, holdID, amount)
+}
+
 // Deposit mocks base method.
 func (m *MockWalletService) Deposit(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (model.Transaction, error) {
         m.ctrl.T.Helper()
@@ -52,33 +99,33 @@ func (mr *MockWalletServiceMockRecorder) Deposit(ctx, accountID, transaction int
 }
 
 // Disable mocks base method.
-func (m *MockWalletService) Disable(ctx context.Context, accountID uuid.UUID) (model.Wallet, error) {
+func (m *MockWalletService) Disable(ctx context.Context, accountID uuid.UUID, version int64) (model.Wallet, error) {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "Disable", ctx, accountID)
+        ret := m.ctrl.Call(m, "Disable", ctx, accountID, version)
         ret0, _ := ret[0].(model.Wallet)
         ret1, _ := ret[1].(error)
         return ret0, ret1
 }
 
 // Disable indicates an expected call of Disable.
-func (mr *MockWalletServiceMockRecorder) Disable(ctx, accountID interface{}) *gomock.Call {
+func (mr *MockWalletServiceMockRecorder) Disable(ctx, accountID, version interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockWalletService)(nil).Disable), ctx, accountID)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockWalletService)(nil).Disable), ctx, accountID, version)
 }
 
 // Enable mocks base method.
-func (m *MockWalletService) Enable(ctx context.Context, accountID uuid.UUID) (model.Wallet, error) {
+func (m *MockWalletService) Enable(ctx context.Context, accountID uuid.UUID, version int64) (model.Wallet, error) {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "Enable", ctx, accountID)
+        ret := m.ctrl.Call(m, "Enable", ctx, accountID, version)
         ret0, _ := ret[0].(model.Wallet)
         ret1, _ := ret[1].(error)
         return ret0, ret1
 }
 
 // Enable indicates an expected call of Enable.
-func (mr *MockWalletServiceMockRecorder) Enable(ctx, accountID interface{}) *gomock.Call {
+func (mr *MockWalletServiceMockRecorder) Enable(ctx, accountID, version interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockWalletService)(nil).Enable), ctx, accountID)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enable", reflect.TypeOf((*MockWalletService)(nil).Enable), ctx, accountID, version)
 }
 
 // Get mocks base method.
@@ -97,25 +144,26 @@ func (mr *MockWalletServiceMockRecorder) Get(ctx, accountID interface{}) *gomock
 }
 
 // GetTransactions mocks base method.
-func (m *MockWalletService) GetTransactions(ctx context.Context, accountID uuid.UUID) ([]model.Transaction, error) {
+func (m *MockWalletService) GetTransactions(ctx context.Context, accountID uuid.UUID, filter internal.TransactionFilter) ([]model.Transaction, *internal.TransactionCursor, error) {
         m.ctrl.T.Helper()
-        ret := m.ctrl.Call(m, "GetTransactions", ctx, accountID)
+        ret := m.ctrl.Call(m, "GetTransactions", ctx, accountID, filter)
         ret0, _ := ret[0].([]model.Transaction)
-        ret1, _ := ret[1].(error)
-        return ret0, ret1
+        ret1, _ := ret[1].(*internal.TransactionCursor)
+        ret2, _ := ret[2].(error)
+        return ret0, ret1, ret2
 }
 
 // GetTransactions indicates an expected call of GetTransactions.
-func (mr *MockWalletServiceMockRecorder) GetTransactions(ctx, accountID interface{}) *gomock.Call {
+func (mr *MockWalletServiceMockRecorder) GetTransactions(ctx, accountID, filter interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
-        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockWalletService)(nil).GetTransactions), ctx, accountID)
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockWalletService)(nil).GetTransactions), ctx, accountID, filter)
 }
 
 // Init mocks base method.
-func (m *MockWalletService) Init(ctx context.Context, externalID string) (string, error) {
+func (m *MockWalletService) Init(ctx context.Context, externalID string) (model.TokenPair, error) {
         m.ctrl.T.Helper()
         ret := m.ctrl.Call(m, "Init", ctx, externalID)
-        ret0, _ := ret[0].(string)
+        ret0, _ := ret[0].(model.TokenPair)
         ret1, _ := ret[1].(error)
         return ret0, ret1
 }
@@ -126,6 +174,65 @@ func (mr *MockWalletServiceMockRecorder) Init(ctx, externalID interface{}) *gomo
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Init", reflect.TypeOf((*MockWalletService)(nil).Init), ctx, externalID)
 }
 
+// Refund mocks base method.
+func (m *MockWalletService) Refund(ctx context.Context, accountID, transactionID uuid.UUID, amount int64) (model.Transaction, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Refund", ctx, accountID, transactionID, amount)
+        ret0, _ := ret[0].(model.Transaction)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Refund indicates an expected call of Refund.
+func (mr *MockWalletServiceMockRecorder) Refund(ctx, accountID, transactionID, amount interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refund", reflect.TypeOf((*MockWalletService)(nil).Refund), ctx, accountID, transactionID, amount)
+}
+
+// Statement mocks base method.
+func (m *MockWalletService) Statement(ctx context.Context, accountID uuid.UUID, from, to time.Time, writer internal.StatementWriter) error {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Statement", ctx, accountID, from, to, writer)
+        ret0, _ := ret[0].(error)
+        return ret0
+}
+
+// Statement indicates an expected call of Statement.
+func (mr *MockWalletServiceMockRecorder) Statement(ctx, accountID, from, to, writer interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Statement", reflect.TypeOf((*MockWalletService)(nil).Statement), ctx, accountID, from, to, writer)
+}
+
+// Transfer mocks base method.
+func (m *MockWalletService) Transfer(ctx context.Context, accountID, receiverWalletID uuid.UUID, transaction model.Transaction) (model.Transaction, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Transfer", ctx, accountID, receiverWalletID, transaction)
+        ret0, _ := ret[0].(model.Transaction)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockWalletServiceMockRecorder) Transfer(ctx, accountID, receiverWalletID, transaction interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletService)(nil).Transfer), ctx, accountID, receiverWalletID, transaction)
+}
+
+// Void mocks base method.
+func (m *MockWalletService) Void(ctx context.Context, accountID, holdID uuid.UUID) (model.Hold, error) {
+        m.ctrl.T.Helper()
+        ret := m.ctrl.Call(m, "Void", ctx, accountID, holdID)
+        ret0, _ := ret[0].(model.Hold)
+        ret1, _ := ret[1].(error)
+        return ret0, ret1
+}
+
+// Void indicates an expected call of Void.
+func (mr *MockWalletServiceMockRecorder) Void(ctx, accountID, holdID interface{}) *gomock.Call {
+        mr.mock.ctrl.T.Helper()
+        return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Void", reflect.TypeOf((*MockWalletService)(nil).Void), ctx, accountID, holdID)
+}
+
 // Withdrawal mocks base method.
 func (m *MockWalletService) Withdrawal(ctx context.Context, accountID uuid.UUID, transaction model.Transaction) (model.Transaction, error) {
         m.ctrl.T.Helper()
@@ -139,4 +246,4 @@ func (m *MockWalletService) Withdrawal(ctx context.Context, accountID uuid.UUID,
 func (mr *MockWalletServiceMockRecorder) Withdrawal(ctx, accountID, transaction interface{}) *gomock.Call {
         mr.mock.ctrl.T.Helper()
         return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdrawal", reflect.TypeOf((*MockWalletService)(nil).Withdrawal), ctx, accountID, transaction)
-}
\ No newline at end of file
+}
@@ -0,0 +1,148 @@
+// Package pdf writes plain text documents as PDF without holding the whole
+// document in memory, a page is written out as soon as it is full.
+package pdf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// An A4 page in points, lines are set in 9pt Courier so columns padded with
+// spaces stay aligned.
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	margin       = 40
+	fontSize     = 9
+	leading      = 12
+	linesPerPage = (pageHeight - 2*margin) / leading
+
+	catalogObject = 1
+	pagesObject   = 2
+	fontObject    = 3
+)
+
+type Writer struct {
+	w       *bufio.Writer
+	written int64
+	// offsets holds the byte offset of every object, indexed by object number
+	offsets []int64
+	pages   []int
+	lines   []string
+	err     error
+}
+
+// NewWriter starts a document on w, nothing is complete until Close.
+func NewWriter(w io.Writer) *Writer {
+	p := &Writer{
+		w:       bufio.NewWriter(w),
+		offsets: make([]int64, fontObject+1),
+	}
+
+	p.printf("%%PDF-1.4\n%%\xe2\xe3\xcf\xd3\n")
+	p.object(fontObject, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	return p
+}
+
+// Line adds a line of text, characters outside of latin-1 are replaced.
+func (p *Writer) Line(text string) error {
+	p.lines = append(p.lines, text)
+	if len(p.lines) == linesPerPage {
+		p.flushPage()
+	}
+
+	return p.err
+}
+
+// Close writes the last page and the document trailer, it does not close
+// the underlying writer.
+func (p *Writer) Close() error {
+	if len(p.lines) > 0 || len(p.pages) == 0 {
+		p.flushPage()
+	}
+
+	kids := make([]string, len(p.pages))
+	for i, page := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	p.object(pagesObject, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	p.object(catalogObject, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObject))
+
+	xref := p.written
+	p.printf("xref\n0 %d\n0000000000 65535 f \n", len(p.offsets))
+	for _, offset := range p.offsets[1:] {
+		p.printf("%010d 00000 n \n", offset)
+	}
+	p.printf("trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(p.offsets), catalogObject, xref)
+
+	if p.err != nil {
+		return p.err
+	}
+	return p.w.Flush()
+}
+
+func (p *Writer) flushPage() {
+	content := strings.Builder{}
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, leading, margin, pageHeight-margin-fontSize)
+	for _, line := range p.lines {
+		fmt.Fprintf(&content, "(%s) Tj T*\n", escape(line))
+	}
+	content.WriteString("ET")
+	p.lines = p.lines[:0]
+
+	contentObject := p.reserve()
+	p.object(contentObject, fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+
+	page := p.reserve()
+	p.object(page, fmt.Sprintf(
+		"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+		pagesObject, pageWidth, pageHeight, fontObject, contentObject,
+	))
+	p.pages = append(p.pages, page)
+
+	// hand the page over instead of keeping it buffered
+	if p.err == nil {
+		p.err = p.w.Flush()
+	}
+}
+
+func (p *Writer) reserve() int {
+	p.offsets = append(p.offsets, 0)
+	return len(p.offsets) - 1
+}
+
+func (p *Writer) object(number int, body string) {
+	p.offsets[number] = p.written
+	p.printf("%d 0 obj\n%s\nendobj\n", number, body)
+}
+
+func (p *Writer) printf(format string, args ...interface{}) {
+	if p.err != nil {
+		return
+	}
+
+	n, err := fmt.Fprintf(p.w, format, args...)
+	p.written += int64(n)
+	p.err = err
+}
+
+// escape makes text safe inside a PDF string, the font is WinAnsi encoded so
+// latin-1 passes through as single bytes and anything else becomes "?".
+func escape(text string) string {
+	escaped := strings.Builder{}
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteByte('\\')
+			escaped.WriteByte(byte(r))
+		case r < 0x20 || (r >= 0x7f && r < 0xa0) || r > 0xff:
+			escaped.WriteByte('?')
+		default:
+			escaped.WriteByte(byte(r))
+		}
+	}
+
+	return escaped.String()
+}
@@ -0,0 +1,69 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter(t *testing.T) {
+	t.Run("Pages", TestWriterPages)
+	t.Run("Empty", TestWriterEmpty)
+	t.Run("Escape", TestEscape)
+}
+
+// assertValid checks the cross reference table points at every object.
+func assertValid(t *testing.T, document []byte, objects int) {
+	assert.True(t, bytes.HasPrefix(document, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(document, []byte("%%EOF\n")))
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(document)
+	assert.NotNil(t, startxref)
+	xref, err := strconv.Atoi(string(startxref[1]))
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(document[xref:], []byte(fmt.Sprintf("xref\n0 %d\n", objects+1))))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(document[xref:], -1)
+	assert.Len(t, entries, objects)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(document[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+	}
+}
+
+func TestWriterPages(t *testing.T) {
+	out := bytes.Buffer{}
+	p := NewWriter(&out)
+	for i := 0; i < linesPerPage+1; i++ {
+		assert.NoError(t, p.Line(fmt.Sprintf("line %d", i)))
+	}
+
+	// the first page is handed over before the document is closed
+	assert.Contains(t, out.String(), "(line 0) Tj")
+	assert.NotContains(t, out.String(), fmt.Sprintf("(line %d) Tj", linesPerPage))
+
+	assert.NoError(t, p.Close())
+	document := out.Bytes()
+	assert.Contains(t, string(document), "/Count 2")
+	assert.Contains(t, string(document), fmt.Sprintf("(line %d) Tj", linesPerPage))
+	// catalog, pages and font, then a content and a page object per page
+	assertValid(t, document, 3+2*2)
+}
+
+func TestWriterEmpty(t *testing.T) {
+	out := bytes.Buffer{}
+	assert.NoError(t, NewWriter(&out).Close())
+	assert.Contains(t, out.String(), "/Count 1")
+	assertValid(t, out.Bytes(), 3+2)
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `total \(idr\) \\ caf`+"\xe9"+` ?`, escape("total (idr) \\ café €"))
+	assert.False(t, strings.ContainsRune(escape("a\nb"), '\n'))
+}
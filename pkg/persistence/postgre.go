@@ -5,7 +5,8 @@ import (
 	"fmt"
 	"sync"
 
-	_ "github.com/lib/pq" // Example for PostgreSQL; use the driver for your DB
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
 var DB *sql.DB
@@ -25,7 +26,7 @@ type Config struct {
 func OpenPostgreDB(cfg Config) (*sql.DB, error) {
 	var err error
 	onceDB.Do(func() {
-		db, errDB := sql.Open("postgres", fmt.Sprintf(`
+		connector, errConnector := pq.NewConnector(fmt.Sprintf(`
 		host=%s
 		port=%s
 		user=%s
@@ -40,8 +41,12 @@ func OpenPostgreDB(cfg Config) (*sql.DB, error) {
 			cfg.DB,
 			cfg.SSLMode,
 		))
-		DB = db
-		err = errDB
+		if errConnector != nil {
+			err = errConnector
+			return
+		}
+
+		DB = sql.OpenDB(newTracedConnector(connector, semconv.DBSystemPostgreSQL))
 	})
 	if err != nil {
 		return nil, err
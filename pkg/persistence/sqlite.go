@@ -0,0 +1,216 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+const (
+	defaultSQLiteBusyTimeout = 5 * time.Second
+
+	// sqliteTimeFormat is fixed width and always UTC, so timestamps compare
+	// correctly as text.
+	sqliteTimeFormat = "2006-01-02 15:04:05.000000000-07:00"
+
+	pqUniqueViolation = "23505"
+)
+
+type SQLiteConfig struct {
+	// Path of the database file, it is created when missing.
+	Path        string
+	BusyTimeout time.Duration
+	MaxOpenConn int
+}
+
+// OpenSQLiteDB opens a database that accepts the Postgres flavoured queries
+// of the repositories, see sqliteRewrites for what gets translated.
+func OpenSQLiteDB(cfg SQLiteConfig) (*sql.DB, error) {
+	busyTimeout := cfg.BusyTimeout
+	if busyTimeout <= 0 {
+		busyTimeout = defaultSQLiteBusyTimeout
+	}
+
+	// WAL lets readers run next to the single writer, immediate transactions
+	// take the write lock up front so two of them never deadlock on upgrade.
+	dsn := fmt.Sprintf(
+		"file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(%d)&_txlock=immediate",
+		cfg.Path,
+		busyTimeout.Milliseconds(),
+	)
+	db := sql.OpenDB(newTracedConnector(
+		&dsnConnector{dsn: dsn, driver: &sqliteDriver{}},
+		semconv.DBSystemSqlite,
+	))
+	if cfg.MaxOpenConn > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConn)
+	}
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// sqliteRewrites turn the Postgres constructs used by the repositories and
+// the migration runner into SQLite, in order.
+var sqliteRewrites = []struct {
+	pattern *regexp.Regexp
+	replace string
+}{
+	// array parameters arrive as json, see CheckNamedValue
+	{regexp.MustCompile(`=\s*ANY\(\s*\$(\d+)\s*\)`), `IN (SELECT value FROM json_each($$$1))`},
+	// a NULL limit means no limit in Postgres, SQLite wants a negative one
+	{regexp.MustCompile(`(?i)LIMIT\s+\$(\d+)`), `LIMIT COALESCE($$$1, -1)`},
+	// SQLite has a single writer, row locks are implied
+	{regexp.MustCompile(`(?i)\s+FOR\s+UPDATE(\s+SKIP\s+LOCKED)?`), ``},
+	{regexp.MustCompile(`pg_advisory_(un)?lock\(\s*\$(\d+)\s*\)`), `$$$2`},
+	{regexp.MustCompile(`to_regclass\('(\w+)'\)\s+IS\s+NOT\s+NULL`), `EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = '$1')`},
+	{regexp.MustCompile(`\$(\d+)`), `?$1`},
+}
+
+func rewriteSQLite(query string) string {
+	for _, r := range sqliteRewrites {
+		query = r.pattern.ReplaceAllString(query, r.replace)
+	}
+
+	return query
+}
+
+// translateSQLiteError reports unique violations the way lib/pq does, so
+// repositories keep a single check.
+func translateSQLiteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return &pq.Error{Code: pqUniqueViolation, Message: sqliteErr.Error()}
+		}
+	}
+
+	return err
+}
+
+type sqliteDriver struct {
+	sqlite.Driver
+}
+
+func (d *sqliteDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c, ok := conn.(sqliteDriverConn)
+	if !ok {
+		_ = conn.Close()
+		return nil, errors.New("sqlite connection misses context support")
+	}
+
+	return &sqliteConn{sqliteDriverConn: c}, nil
+}
+
+type sqliteDriverConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+}
+
+type sqliteConn struct {
+	sqliteDriverConn
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.sqliteDriverConn.PrepareContext(ctx, rewriteSQLite(query))
+	if err != nil {
+		return nil, translateSQLiteError(err)
+	}
+
+	s, ok := stmt.(sqliteDriverStmt)
+	if !ok {
+		_ = stmt.Close()
+		return nil, errors.New("sqlite statement misses context support")
+	}
+
+	return &sqliteStmt{sqliteDriverStmt: s}, nil
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result, err := c.sqliteDriverConn.ExecContext(ctx, rewriteSQLite(query), args)
+	return result, translateSQLiteError(err)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := c.sqliteDriverConn.QueryContext(ctx, rewriteSQLite(query), args)
+	return rows, translateSQLiteError(err)
+}
+
+// CheckNamedValue stores pq arrays as json arrays and times in
+// sqliteTimeFormat, everything else takes the default conversion.
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch v := nv.Value.(type) {
+	case *pq.StringArray:
+		if v == nil || *v == nil {
+			nv.Value = nil
+			return nil
+		}
+
+		values, err := json.Marshal([]string(*v))
+		if err != nil {
+			return err
+		}
+		nv.Value = string(values)
+		return nil
+	case time.Time:
+		nv.Value = v.UTC().Format(sqliteTimeFormat)
+		return nil
+	case *time.Time:
+		if v == nil {
+			nv.Value = nil
+			return nil
+		}
+
+		nv.Value = v.UTC().Format(sqliteTimeFormat)
+		return nil
+	}
+
+	return driver.ErrSkip
+}
+
+type sqliteDriverStmt interface {
+	driver.Stmt
+	driver.StmtExecContext
+	driver.StmtQueryContext
+}
+
+type sqliteStmt struct {
+	sqliteDriverStmt
+}
+
+func (s *sqliteStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	result, err := s.sqliteDriverStmt.ExecContext(ctx, args)
+	return result, translateSQLiteError(err)
+}
+
+func (s *sqliteStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	rows, err := s.sqliteDriverStmt.QueryContext(ctx, args)
+	return rows, translateSQLiteError(err)
+}
@@ -0,0 +1,394 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/hokdre/mini-ewallet/internal/account"
+	"github.com/hokdre/mini-ewallet/internal/audit"
+	"github.com/hokdre/mini-ewallet/internal/fee"
+	"github.com/hokdre/mini-ewallet/internal/hold"
+	"github.com/hokdre/mini-ewallet/internal/ledger"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/hokdre/mini-ewallet/internal/outbox"
+	"github.com/hokdre/mini-ewallet/internal/reconciliation"
+	"github.com/hokdre/mini-ewallet/internal/token"
+	"github.com/hokdre/mini-ewallet/internal/transaction"
+	"github.com/hokdre/mini-ewallet/internal/wallet"
+	"github.com/hokdre/mini-ewallet/migrations"
+	"github.com/hokdre/mini-ewallet/pkg/migration"
+	"github.com/hokdre/mini-ewallet/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLite(t *testing.T) {
+	t.Run("Rewrite", TestRewriteSQLite)
+	t.Run("Migrate", TestSQLiteMigrate)
+	t.Run("LedgerBackfill", TestSQLiteLedgerBackfill)
+	t.Run("WalletService", TestSQLiteWalletService)
+}
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	db, err := OpenSQLiteDB(SQLiteConfig{Path: filepath.Join(t.TempDir(), "wallet.db")})
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func migrateTestSQLite(t *testing.T, db *sql.DB) {
+	scripts, err := migration.Load(migrations.FS)
+	assert.NoError(t, err)
+
+	_, err = migration.New(db, scripts).Up(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestRewriteSQLite(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Placeholders",
+			query: "UPDATE wallets SET balance = balance + $1 WHERE id = $2",
+			want:  "UPDATE wallets SET balance = balance + ?1 WHERE id = ?2",
+		},
+		{
+			name:  "Any",
+			query: "WHERE (id = ANY($1) OR $1 IS NULL)",
+			want:  "WHERE (id IN (SELECT value FROM json_each(?1)) OR ?1 IS NULL)",
+		},
+		{
+			name:  "Limit",
+			query: "ORDER BY id DESC LIMIT $3",
+			want:  "ORDER BY id DESC LIMIT COALESCE(?3, -1)",
+		},
+		{
+			name:  "RowLock",
+			query: "SELECT id FROM holds WHERE status = $1 FOR UPDATE SKIP LOCKED",
+			want:  "SELECT id FROM holds WHERE status = ?1",
+		},
+		{
+			name:  "AdvisoryLock",
+			query: "SELECT pg_advisory_unlock($1)",
+			want:  "SELECT ?1",
+		},
+		{
+			name:  "TableExists",
+			query: "SELECT to_regclass('schema_migrations') IS NOT NULL",
+			want:  "SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, rewriteSQLite(tt.query))
+		})
+	}
+}
+
+func TestSQLiteMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	scripts, err := migration.Load(migrations.FS)
+	assert.NoError(t, err)
+	migrator := migration.New(db, scripts)
+
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, pending, len(scripts))
+
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(scripts))
+
+	pending, err = migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	reverted, err := migrator.Down(ctx, len(scripts))
+	assert.NoError(t, err)
+	assert.Len(t, reverted, len(scripts))
+}
+
+// TestSQLiteLedgerBackfill books the transactions settled before the ledger
+// existed, their postings have to add up to the cached balance.
+func TestSQLiteLedgerBackfill(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	scripts, err := migration.Load(migrations.FS)
+	assert.NoError(t, err)
+
+	// the schema before the backfill
+	_, err = migration.New(db, scripts[:9]).Up(ctx)
+	assert.NoError(t, err)
+
+	timestamp := time.Now()
+	acc := model.Account{ID: uuid.New(), ExternalCustomerID: uuid.New().String(), CreatedAt: timestamp, UpdatedAt: timestamp}
+	legacy := model.Wallet{
+		ID:        uuid.New(),
+		OwnedBy:   acc.ID,
+		Balance:   5800,
+		Status:    model.WalletStatus.Enabled,
+		CreatedAt: timestamp,
+		UpdatedAt: timestamp,
+		Version:   1,
+	}
+	settled := func(transactionType string, status string, amount int64) model.Transaction {
+		return model.Transaction{
+			ID:           uuid.New(),
+			WalletID:     legacy.ID,
+			Type:         transactionType,
+			Status:       status,
+			ReferenceID:  uuid.New().String(),
+			Amount:       amount,
+			TransactedAt: &timestamp,
+			CreatedAt:    timestamp,
+			UpdatedAt:    timestamp,
+		}
+	}
+	transactions := []model.Transaction{
+		settled(model.TransactionType.Deposit, model.TransactionStatus.Success, 10000),
+		settled(model.TransactionType.Withdrawal, model.TransactionStatus.Success, 3000),
+		settled(model.TransactionType.Fee, model.TransactionStatus.Success, 200),
+		settled(model.TransactionType.TransferOut, model.TransactionStatus.Success, 1000),
+		settled(model.TransactionType.Withdrawal, model.TransactionStatus.Failed, 9000),
+	}
+	err = internal.NewTxRepository(db).Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if err := account.NewAccountRepo(db).CreateTx(ctx, tx, acc); err != nil {
+			return err
+		}
+		if err := wallet.NewWalletRepository(db).CreateTx(ctx, tx, legacy); err != nil {
+			return err
+		}
+		// the columns of the transactions table as of the schema above, the
+		// repository writes the ones added later
+		for _, t := range transactions {
+			_, err := tx.ExecContext(ctx, `INSERT INTO transactions(
+				id, wallet_id, type, status, reference_id, amount, fee, transacted_at, created_at, updated_at, is_active
+			) VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,true)`,
+				t.ID, t.WalletID, t.Type, t.Status, t.ReferenceID, t.Amount, t.Fee, t.TransactedAt, t.CreatedAt, t.UpdatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	applied, err := migration.New(db, scripts).Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(scripts)-9)
+
+	ledgerBalance, err := ledger.NewLedgerRepository(db).GetWalletBalance(ctx, legacy.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, legacy.Balance, ledgerBalance)
+
+	report, err := reconciliation.NewReconciliationService(reconciliation.Config{
+		TxRepository:             internal.NewTxRepository(db),
+		ReconciliationRepository: reconciliation.NewReconciliationRepository(db),
+	}).Reconcile(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, report.IsClean(), "%+v", report)
+}
+
+// TestSQLiteWalletService runs the wallet service on the postgres
+// repositories over sqlite, the ledger has to agree with the cached balances.
+func TestSQLiteWalletService(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	migrateTestSQLite(t, db)
+
+	encryption, err := util.NewAesEncryption(util.AesKey{ID: "1", Secret: "1111222233334444"})
+	assert.NoError(t, err)
+	tokenService := token.NewTokenService(token.Config{
+		Encryption:      encryption,
+		TokenRepository: token.NewTokenRepository(db),
+	})
+	ledgerRepo := ledger.NewLedgerRepository(db)
+	walletRepo := wallet.NewWalletRepository(db)
+	transactionRepo := transaction.NewAccountRepo(db)
+	cfg := wallet.Config{
+		AccountRepo:           account.NewAccountRepo(db),
+		WalletRepository:      walletRepo,
+		TransactionRepository: transactionRepo,
+		TxRepository:          internal.NewTxRepository(db),
+		LedgerRepository:      ledgerRepo,
+		HoldRepository:        hold.NewHoldRepository(db),
+		OutboxRepository:      outbox.NewOutboxRepository(db),
+		AuditRepository:       audit.NewAuditRepository(db),
+		TokenService:          tokenService,
+		Validator:             util.NewValidator(),
+		WithdrawalFee:         fee.NewFlat(100),
+	}
+	service := wallet.NewWalletService(cfg)
+
+	open := func(externalID string) (uuid.UUID, model.Wallet) {
+		tokens, err := service.Init(ctx, externalID)
+		assert.NoError(t, err)
+		claims, err := tokenService.Verify(ctx, tokens.AccessToken, model.TokenType.Access)
+		assert.NoError(t, err)
+
+		w, err := service.Enable(ctx, claims.AccountID, 0)
+		assert.NoError(t, err)
+		return claims.AccountID, w
+	}
+	alice, aliceWallet := open(uuid.New().String())
+	bob, bobWallet := open(uuid.New().String())
+
+	deposit, err := service.Deposit(ctx, alice, model.Transaction{ReferenceID: "deposit-1", Amount: 10000})
+	assert.NoError(t, err)
+
+	// a retried reference gets the first result back
+	retried, err := service.Deposit(ctx, alice, model.Transaction{ReferenceID: "deposit-1", Amount: 10000})
+	assert.NoError(t, err)
+	assert.Equal(t, deposit.ID, retried.ID)
+
+	// unique violations still surface as duplicate references
+	duplicate := deposit
+	duplicate.ID = uuid.New()
+	err = transactionRepo.Create(ctx, duplicate)
+	assert.ErrorIs(t, err, model.ErrDuplicateReferenceID)
+
+	_, err = service.Withdrawal(ctx, alice, model.Transaction{ReferenceID: "withdrawal-1", Amount: 1000})
+	assert.NoError(t, err)
+
+	_, err = service.Transfer(ctx, alice, bobWallet.ID, model.Transaction{ReferenceID: "transfer-1", Amount: 2000})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = service.Withdrawal(ctx, alice, model.Transaction{ReferenceID: uuid.New().String(), Amount: 900})
+		}()
+	}
+	wg.Wait()
+
+	for _, w := range []model.Wallet{aliceWallet, bobWallet} {
+		current, err := walletRepo.GetOne(ctx, internal.WalletFilter{IDs: []string{w.ID.String()}})
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, current.Balance, int64(0))
+
+		ledgerBalance, err := ledgerRepo.GetWalletBalance(ctx, w.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, ledgerBalance, current.Balance)
+	}
+
+	// 10000 - 1100 - 2000 leaves 6900, enough for exactly 6 withdrawals of 1000
+	aliceCurrent, err := service.Get(ctx, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(900), aliceCurrent.Balance)
+
+	bobCurrent, err := service.Get(ctx, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000), bobCurrent.Balance)
+
+	// the credit leg has its own reference, bob is free to use the sender's
+	bobDeposit, err := service.Deposit(ctx, bob, model.Transaction{ReferenceID: "transfer-1", Amount: 100})
+	assert.NoError(t, err)
+	assert.Equal(t, model.TransactionStatus.Success, bobDeposit.Status)
+
+	// the max balance caps every credit, bob holds 2100
+	capped := cfg
+	capped.Limits = wallet.Limits{MaxBalance: 2200}
+	cappedService := wallet.NewWalletService(capped)
+	_, err = cappedService.Transfer(ctx, alice, bobWallet.ID, model.Transaction{ReferenceID: "transfer-2", Amount: 200})
+	assert.ErrorIs(t, err, model.ErrBalanceLimitExceeded)
+	_, err = cappedService.Adjust(util.ContextWithOperator(ctx, "ops"), bob, model.Transaction{
+		Type:   model.TransactionType.AdjustmentCredit,
+		Amount: 200,
+		Reason: "goodwill",
+	})
+	assert.ErrorIs(t, err, model.ErrBalanceLimitExceeded)
+	_, err = cappedService.Deposit(ctx, bob, model.Transaction{ReferenceID: "deposit-2", Amount: 100})
+	assert.NoError(t, err)
+
+	// concurrent deposits are checked one after the other on the locked wallet
+	capped.Limits = wallet.Limits{Deposit: wallet.Limit{DailyCount: 3}}
+	cappedService = wallet.NewWalletService(capped)
+	carol, carolWallet := open(uuid.New().String())
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cappedService.Deposit(ctx, carol, model.Transaction{ReferenceID: uuid.New().String(), Amount: 100})
+		}()
+	}
+	wg.Wait()
+	carolDeposits, err := transactionRepo.Summarize(ctx, internal.TransactionFilter{
+		WalletIDs: []string{carolWallet.ID.String()},
+		Statuses:  []string{model.TransactionStatus.Success},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, carolDeposits.Count)
+
+	// array filters, time ranges and pages go through the rewritten queries
+	from := time.Now().Add(-time.Hour)
+	page, err := transactionRepo.List(ctx, internal.TransactionFilter{
+		WalletIDs:      []string{aliceWallet.ID.String()},
+		Types:          []string{model.TransactionType.Withdrawal},
+		Statuses:       []string{model.TransactionStatus.Success},
+		TransactedFrom: &from,
+		Limit:          4,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, page, 4)
+
+	last := page[len(page)-1]
+	rest, err := transactionRepo.List(ctx, internal.TransactionFilter{
+		WalletIDs: []string{aliceWallet.ID.String()},
+		Types:     []string{model.TransactionType.Withdrawal},
+		Statuses:  []string{model.TransactionStatus.Success},
+		After:     &internal.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID.String()},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, rest, 3)
+
+	// a pending row left behind by a crash never reached the ledger, recovery
+	// fails it and the status guard keeps it failed
+	stale := time.Now().Add(-time.Hour)
+	stuck := model.Transaction{
+		ID:          uuid.New(),
+		WalletID:    aliceWallet.ID,
+		Type:        model.TransactionType.Deposit,
+		Status:      model.TransactionStatus.Pending,
+		ReferenceID: "crashed-deposit",
+		Amount:      500,
+		CreatedAt:   stale,
+		UpdatedAt:   stale,
+	}
+	assert.NoError(t, transactionRepo.Create(ctx, stuck))
+
+	recovered, err := service.RecoverPending(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+
+	resolved, err := transactionRepo.List(ctx, internal.TransactionFilter{IDs: []string{stuck.ID.String()}})
+	assert.NoError(t, err)
+	assert.Equal(t, model.TransactionStatus.Failed, resolved[0].Status)
+
+	stuck.Status = model.TransactionStatus.Success
+	err = internal.NewTxRepository(db).Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return transactionRepo.UpdateTx(ctx, tx, stuck)
+	})
+	assert.ErrorIs(t, err, model.ErrInvalidStatusTransition)
+
+	// fees, transfers and failed withdrawals all reconcile
+	report, err := reconciliation.NewReconciliationService(reconciliation.Config{
+		TxRepository:             internal.NewTxRepository(db),
+		ReconciliationRepository: reconciliation.NewReconciliationRepository(db),
+	}).Reconcile(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Wallets)
+	assert.True(t, report.IsClean(), "%+v", report)
+}
@@ -0,0 +1,198 @@
+package persistence
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/hokdre/mini-ewallet/pkg/persistence")
+
+// queryTarget picks the operation and the first table out of the queries
+// the repositories run, for span names such as "UPDATE wallets".
+var queryTarget = regexp.MustCompile(`(?is)^\s*(SELECT)\b.*?\bFROM\s+(\w+)|^\s*(INSERT)\s+INTO\s+(\w+)|^\s*(UPDATE)\s+(\w+)|^\s*(DELETE)\s+FROM\s+(\w+)`)
+
+// tracedConnector opens connections that run every statement in a span,
+// the span is a child of whatever span the statement context carries.
+type tracedConnector struct {
+	driver.Connector
+	system attribute.KeyValue
+}
+
+func newTracedConnector(connector driver.Connector, system attribute.KeyValue) *tracedConnector {
+	return &tracedConnector{Connector: connector, system: system}
+}
+
+func (c *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracedConn{Conn: conn, system: c.system}, nil
+}
+
+// dsnConnector is the connector of a driver that has none of its own.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// tracedConn passes everything on to Conn, the optional interfaces Conn
+// lacks answer the way database/sql treats a missing one.
+type tracedConn struct {
+	driver.Conn
+	system attribute.KeyValue
+}
+
+func (c *tracedConn) startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	name := "SQL"
+	attributes := []attribute.KeyValue{c.system, semconv.DBQueryText(query)}
+	if match := queryTarget.FindStringSubmatch(query); match != nil {
+		for i := 1; i < len(match); i += 2 {
+			if match[i] != "" {
+				name = strings.ToUpper(match[i]) + " " + match[i+1]
+				attributes = append(attributes, semconv.DBOperationName(strings.ToUpper(match[i])), semconv.DBCollectionName(match[i+1]))
+				break
+			}
+		}
+	}
+
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attributes...))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, driver.ErrSkip) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = p.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &tracedStmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := c.startSpan(ctx, query)
+	result, err := e.ExecContext(ctx, query, args)
+	endSpan(span, err)
+	return result, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	ctx, span := c.startSpan(ctx, query)
+	rows, err := q.QueryContext(ctx, query, args)
+	endSpan(span, err)
+	return rows, err
+}
+
+func (c *tracedConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+
+	return driver.ErrSkip
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+
+	return true
+}
+
+// tracedStmt spans the executions of a prepared statement. It must not
+// check named values itself, database/sql would then skip the connection's
+// checker.
+type tracedStmt struct {
+	driver.Stmt
+	conn  *tracedConn
+	query string
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, errors.New("sql statement misses context support")
+	}
+
+	ctx, span := s.conn.startSpan(ctx, s.query)
+	result, err := e.ExecContext(ctx, args)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, errors.New("sql statement misses context support")
+	}
+
+	ctx, span := s.conn.startSpan(ctx, s.query)
+	rows, err := q.QueryContext(ctx, args)
+	endSpan(span, err)
+	return rows, err
+}
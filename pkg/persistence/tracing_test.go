@@ -0,0 +1,112 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	recordSpans sync.Once
+	spans       = tracetest.NewSpanRecorder()
+)
+
+// tracedSpans installs the recorder, tracers handed out before the first
+// provider keep delegating to it so it is installed only once.
+func tracedSpans() *tracetest.SpanRecorder {
+	recordSpans.Do(func() {
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)))
+	})
+
+	return spans
+}
+
+// spansOf returns the ended spans of the trace of span by name.
+func spansOf(recorder *tracetest.SpanRecorder, span trace.Span) map[string]sdktrace.ReadOnlySpan {
+	traced := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range recorder.Ended() {
+		if s.SpanContext().TraceID() == span.SpanContext().TraceID() {
+			traced[s.Name()] = s
+		}
+	}
+
+	return traced
+}
+
+func TestTracing(t *testing.T) {
+	t.Run("SpanName", TestQueryTarget)
+	t.Run("Spans", TestTracedSpans)
+}
+
+func TestQueryTarget(t *testing.T) {
+	tests := []struct {
+		query string
+		name  string
+	}{
+		{query: "SELECT id, balance\n\t\tFROM wallets WHERE id = $1 FOR UPDATE", name: "SELECT wallets"},
+		{query: "insert into transactions (id) values ($1)", name: "INSERT transactions"},
+		{query: " UPDATE wallets SET balance = balance + $1", name: "UPDATE wallets"},
+		{query: "DELETE FROM tokens WHERE expires_at < $1", name: "DELETE tokens"},
+		{query: "WITH pending AS (SELECT 1) SELECT 1", name: "SQL"},
+	}
+	for _, tt := range tests {
+		recorder := tracedSpans()
+		ctx, root := otel.Tracer("test").Start(context.Background(), "root")
+		_, span := (&tracedConn{system: semconv.DBSystemSqlite}).startSpan(ctx, tt.query)
+		span.End()
+		root.End()
+
+		_, found := spansOf(recorder, root)[tt.name]
+		assert.True(t, found, tt.query)
+	}
+}
+
+func TestTracedSpans(t *testing.T) {
+	recorder := tracedSpans()
+	db := openTestSQLite(t)
+	migrateTestSQLite(t, db)
+
+	ctx, root := otel.Tracer("test").Start(context.Background(), "root")
+	err := internal.NewTxRepository(db).Process(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		stmt, err := tx.Prepare("UPDATE wallets SET balance = balance + $1 WHERE id = $2")
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		_, err = stmt.ExecContext(ctx, 100, uuid.New())
+		return err
+	})
+	assert.NoError(t, err)
+
+	_, err = db.QueryContext(ctx, "SELECT id FROM missing")
+	assert.Error(t, err)
+	root.End()
+
+	traced := spansOf(recorder, root)
+	process, update, missing := traced["TxRepository.Process"], traced["UPDATE wallets"], traced["SELECT missing"]
+	assert.NotNil(t, process)
+	assert.NotNil(t, update)
+	assert.NotNil(t, missing)
+	if process == nil || update == nil || missing == nil {
+		return
+	}
+
+	assert.Equal(t, root.SpanContext().SpanID(), process.Parent().SpanID())
+	assert.Equal(t, process.SpanContext().SpanID(), update.Parent().SpanID())
+	assert.Contains(t, update.Attributes(), semconv.DBSystemSqlite)
+	assert.Contains(t, update.Attributes(), semconv.DBQueryText("UPDATE wallets SET balance = balance + $1 WHERE id = $2"))
+	assert.Equal(t, codes.Unset, update.Status().Code)
+	assert.Equal(t, codes.Error, missing.Status().Code)
+}
@@ -0,0 +1,106 @@
+// Package ratelimit keeps a token bucket per key, such as an account or a
+// client ip.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// sweepInterval is how often buckets are looked through for idle ones.
+const sweepInterval = time.Minute
+
+type Config struct {
+	// PerMinute is the rate the bucket refills at, zero means no limit.
+	PerMinute float64
+	// Burst is the bucket size, at least one request always fits.
+	Burst int
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+type Limiter struct {
+	limit rate.Limit
+	burst int
+	// idle is how long a bucket takes to fill up again, an unused bucket
+	// is full after that and is dropped instead of kept around.
+	idle time.Duration
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// New returns nil when cfg has no limit, a nil Limiter allows everything.
+func New(cfg Config) *Limiter {
+	if cfg.PerMinute <= 0 {
+		return nil
+	}
+
+	burst := cfg.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	limit := rate.Limit(cfg.PerMinute / 60)
+
+	return &Limiter{
+		limit:   limit,
+		burst:   burst,
+		idle:    time.Duration(float64(burst) / float64(limit) * float64(time.Second)),
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Allow takes a token from the bucket of key. When it is empty the request
+// is refused and retryAfter tells when a token will be back.
+func (l *Limiter) Allow(key string, now time.Time) (allowed bool, retryAfter time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		// a refused request must not eat into the next token
+		reservation.CancelAt(now)
+		return false, delay
+	}
+
+	return true, 0
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < sweepInterval {
+		return
+	}
+	l.swept = now
+
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > l.idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RetryAfterSeconds rounds retryAfter up to the whole seconds of a
+// Retry-After header.
+func RetryAfterSeconds(retryAfter time.Duration) int {
+	return int(math.Ceil(retryAfter.Seconds()))
+}
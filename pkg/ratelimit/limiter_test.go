@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	t.Run("Allow", TestAllow)
+	t.Run("Unlimited", TestUnlimited)
+	t.Run("Sweep", TestSweep)
+	t.Run("RetryAfterSeconds", TestRetryAfterSeconds)
+}
+
+func TestAllow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	l := New(Config{PerMinute: 6, Burst: 2})
+
+	// the burst goes through at once
+	for i := 0; i < 2; i++ {
+		allowed, _ := l.Allow("a", now)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter := l.Allow("a", now)
+	assert.False(t, allowed)
+	assert.Equal(t, 10*time.Second, retryAfter)
+
+	// refused requests do not push the next token further away
+	allowed, retryAfter = l.Allow("a", now.Add(4*time.Second))
+	assert.False(t, allowed)
+	assert.InDelta(t, 6*time.Second, retryAfter, float64(time.Millisecond))
+
+	// other keys have buckets of their own
+	allowed, _ = l.Allow("b", now)
+	assert.True(t, allowed)
+
+	allowed, _ = l.Allow("a", now.Add(10*time.Second))
+	assert.True(t, allowed)
+	allowed, _ = l.Allow("a", now.Add(10*time.Second))
+	assert.False(t, allowed)
+}
+
+func TestUnlimited(t *testing.T) {
+	l := New(Config{Burst: 1})
+	assert.Nil(t, l)
+
+	for i := 0; i < 100; i++ {
+		allowed, retryAfter := l.Allow("a", time.Now())
+		assert.True(t, allowed)
+		assert.Zero(t, retryAfter)
+	}
+
+	// a zero burst still lets a request through
+	l = New(Config{PerMinute: 1})
+	allowed, _ := l.Allow("a", time.Now())
+	assert.True(t, allowed)
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	l := New(Config{PerMinute: 60, Burst: 30})
+	l.Allow("idle", now)
+	l.Allow("busy", now)
+
+	// 30 seconds refill the idle bucket, the busy one was used since
+	l.Allow("busy", now.Add(50*time.Second))
+	l.Allow("busy", now.Add(70*time.Second))
+	assert.Len(t, l.buckets, 1)
+	assert.Contains(t, l.buckets, "busy")
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, RetryAfterSeconds(time.Millisecond))
+	assert.Equal(t, 10, RetryAfterSeconds(10*time.Second))
+	assert.Equal(t, 11, RetryAfterSeconds(10*time.Second+time.Nanosecond))
+}
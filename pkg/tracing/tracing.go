@@ -0,0 +1,155 @@
+// Package tracing sets up OpenTelemetry for the wallet. Spans are started
+// from the global tracer provider, so packages only need otel.Tracer and
+// nothing is recorded until Start installs a provider.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var Exporter = struct {
+	None   string
+	OTLP   string
+	Stdout string
+	File   string
+}{
+	None:   "none",
+	OTLP:   "otlp",
+	Stdout: "stdout",
+	File:   "file",
+}
+
+type Config struct {
+	ServiceName string
+	// Exporter is one of Exporter, empty picks otlp when OTLPEndpoint is
+	// set, file when File is set and stdout otherwise. Only none turns
+	// exporting off.
+	Exporter string
+	// OTLPEndpoint is the host:port of an OTLP/HTTP collector, empty falls
+	// back to the OTEL_EXPORTER_OTLP_* variables of the exporter.
+	OTLPEndpoint string
+	OTLPInsecure bool
+	// File spans are appended to as JSON lines when Exporter is file.
+	File string
+	// SampleRatio of the root spans kept, 0 keeps none and 1 keeps all.
+	// Remote parents decide for their children.
+	SampleRatio float64
+}
+
+// Start installs the tracer provider and the W3C trace context propagator.
+// The returned shutdown flushes the spans not exported yet, it must run
+// before the process exits.
+func Start(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	// propagate even when spans are not exported, so traces of the callers
+	// stay connected through us
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, closer, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if errClose := closer.Close(); err == nil {
+				err = errClose
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, io.Closer, error) {
+	switch exporterOf(cfg) {
+	case Exporter.None:
+		return nil, nil, nil
+	case Exporter.OTLP:
+		opts := []otlptracehttp.Option{}
+		if cfg.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.OTLPEndpoint))
+		}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		return exporter, nil, err
+	case Exporter.Stdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		return exporter, nil, err
+	case Exporter.File:
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, err
+		}
+		return exporter, file, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// exporterOf resolves an empty Exporter, spans are kept somewhere unless
+// none is asked for.
+func exporterOf(cfg Config) string {
+	switch {
+	case cfg.Exporter != "":
+		return cfg.Exporter
+	case cfg.OTLPEndpoint != "":
+		return Exporter.OTLP
+	case cfg.File != "":
+		return Exporter.File
+	default:
+		return Exporter.Stdout
+	}
+}
+
+// End is deferred with the address of the named error result, so the span
+// is marked failed with whatever the function ends up returning.
+func End(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	t.Run("File", TestStartFile)
+	t.Run("DefaultExporter", TestStartDefaultExporter)
+	t.Run("UnknownExporter", TestStartUnknownExporter)
+	t.Run("End", TestEnd)
+}
+
+func TestStartFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Start(context.Background(), Config{
+		ServiceName: "test",
+		Exporter:    Exporter.File,
+		File:        path,
+		SampleRatio: 1,
+	})
+	assert.NoError(t, err)
+
+	// the incoming trace is continued
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	_, span := otel.Tracer("test").Start(ctx, "span")
+	span.End()
+	assert.NoError(t, shutdown(context.Background()))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 1)
+
+	exported := struct {
+		Name        string
+		SpanContext struct{ TraceID string }
+		Parent      struct{ SpanID string }
+	}{}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &exported))
+	assert.Equal(t, "span", exported.Name)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", exported.SpanContext.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", exported.Parent.SpanID)
+}
+
+func TestStartDefaultExporter(t *testing.T) {
+	assert.Equal(t, Exporter.OTLP, exporterOf(Config{OTLPEndpoint: "localhost:4318", File: "traces.json"}))
+	assert.Equal(t, Exporter.File, exporterOf(Config{File: "traces.json"}))
+	assert.Equal(t, Exporter.Stdout, exporterOf(Config{}))
+	assert.Equal(t, Exporter.None, exporterOf(Config{Exporter: Exporter.None, File: "traces.json"}))
+
+	// without an endpoint the spans land in the file
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Start(context.Background(), Config{ServiceName: "test", File: path, SampleRatio: 1})
+	assert.NoError(t, err)
+	_, span := otel.Tracer("test").Start(context.Background(), "span")
+	span.End()
+	assert.NoError(t, shutdown(context.Background()))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"Name":"span"`)
+}
+
+func TestStartUnknownExporter(t *testing.T) {
+	_, err := Start(context.Background(), Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+
+	shutdown, err := Start(context.Background(), Config{Exporter: Exporter.None})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	run := func(name string, result error) (err error) {
+		_, span := tracer.Start(context.Background(), name)
+		defer End(span, &err)
+
+		return result
+	}
+	assert.NoError(t, run("success", nil))
+	assert.Error(t, run("failed", errors.New("err")))
+
+	ended := recorder.Ended()
+	assert.Len(t, ended, 2)
+	assert.Equal(t, codes.Unset, ended[0].Status().Code)
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+	assert.Len(t, ended[1].Events(), 1)
+}
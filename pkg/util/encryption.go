@@ -1,13 +1,21 @@
 package util
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
+	"strings"
+)
+
+const keyIDSeparator = "."
+
+var (
+	ErrInvalidCipherText = errors.New("invalid cipher text")
+	ErrUnknownKeyID      = errors.New("unknown key id")
 )
 
 type Encryption interface {
@@ -15,79 +23,106 @@ type Encryption interface {
 	Decrypt(text string) (string, error)
 }
 
-func NewAesEncryption(secret string) (*aesEncryption, error) {
-	keyLength := len(secret)
-	if keyLength != 16 && keyLength != 24 && keyLength != 32 {
-		return nil, errors.New("invlid length of secret")
-	}
-	return &aesEncryption{secret: secret}, nil
+// AesKey is a secret named by ID, the ID is embedded in every cipher text
+// so the key can be found again after the primary key rotated.
+type AesKey struct {
+	ID     string
+	Secret string
 }
 
-type aesEncryption struct {
-	secret string
-}
+// ParseAesKeys reads "id:secret,id:secret", the secret is everything after
+// the first colon.
+func ParseAesKeys(raw string) ([]AesKey, error) {
+	keys := []AesKey{}
+	for _, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, secret, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid key %q, expected id:secret", pair)
+		}
+		keys = append(keys, AesKey{ID: id, Secret: secret})
+	}
 
-func (a *aesEncryption) Encrypt(text string) (string, error) {
-	keyBytes := []byte(a.secret)
-	plainTextBytes := []byte(text)
+	return keys, nil
+}
 
-	block, err := aes.NewCipher(keyBytes)
-	if err != nil {
-		return "", err
+// NewAesEncryption encrypts with AES-GCM under primary, cipher texts of
+// primary and of decryptionKeys can be decrypted.
+func NewAesEncryption(primary AesKey, decryptionKeys ...AesKey) (*aesEncryption, error) {
+	a := &aesEncryption{
+		primary: primary.ID,
+		keys:    map[string]cipher.AEAD{},
 	}
-
-	plainTextBytes = a.pkcs7Padding(plainTextBytes, aes.BlockSize)
-	cipherText := make([]byte, aes.BlockSize+len(plainTextBytes))
-	iv := cipherText[:aes.BlockSize]
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		return "", err
+	for _, key := range append([]AesKey{primary}, decryptionKeys...) {
+		if key.ID == "" || strings.Contains(key.ID, keyIDSeparator) {
+			return nil, fmt.Errorf("invalid key id %q", key.ID)
+		}
+		if _, ok := a.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+
+		keyLength := len(key.Secret)
+		if keyLength != 16 && keyLength != 24 && keyLength != 32 {
+			return nil, fmt.Errorf("invlid length of secret for key %q", key.ID)
+		}
+
+		block, err := aes.NewCipher([]byte(key.Secret))
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		a.keys[key.ID] = aead
 	}
 
-	mode := cipher.NewCBCEncrypter(block, iv)
-	mode.CryptBlocks(cipherText[aes.BlockSize:], plainTextBytes)
-	return base64.StdEncoding.EncodeToString(cipherText), nil
+	return a, nil
 }
 
-func (a *aesEncryption) pkcs7Padding(data []byte, blockSize int) []byte {
-	padding := blockSize - len(data)%blockSize
-	padText := bytes.Repeat([]byte{byte(padding)}, padding)
-	return append(data, padText...)
+// aesEncryption produces "<key id>.<base64url nonce and sealed text>", the
+// key id is authenticated as additional data.
+type aesEncryption struct {
+	primary string
+	keys    map[string]cipher.AEAD
 }
 
-func (a *aesEncryption) Decrypt(text string) (string, error) {
-	cipherTextBytes, err := base64.StdEncoding.DecodeString(text)
-	if err != nil {
-		return "", err
-	}
-
-	block, err := aes.NewCipher([]byte(a.secret))
-	if err != nil {
+func (a *aesEncryption) Encrypt(text string) (string, error) {
+	aead := a.keys[a.primary]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(text)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return "", err
 	}
 
-	iv := cipherTextBytes[:aes.BlockSize]
-	cipherTextBytes = cipherTextBytes[aes.BlockSize:]
-	mode := cipher.NewCBCDecrypter(block, iv)
-	mode.CryptBlocks(cipherTextBytes, cipherTextBytes)
+	sealed := aead.Seal(nonce, nonce, []byte(text), []byte(a.primary))
+	return a.primary + keyIDSeparator + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
 
-	plainTextBytes, err := a.pkcs7UnPadding(cipherTextBytes)
-	if err != nil {
-		return "", err
+func (a *aesEncryption) Decrypt(text string) (string, error) {
+	keyID, encoded, ok := strings.Cut(text, keyIDSeparator)
+	if !ok {
+		return "", ErrInvalidCipherText
 	}
 
-	return string(plainTextBytes), nil
-}
+	aead, ok := a.keys[keyID]
+	if !ok {
+		return "", ErrUnknownKeyID
+	}
 
-func (a *aesEncryption) pkcs7UnPadding(data []byte) ([]byte, error) {
-	length := len(data)
-	if length == 0 {
-		return nil, errors.New("invalid padding size")
+	sealed, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return "", ErrInvalidCipherText
 	}
 
-	padding := int(data[length-1])
-	if padding > length {
-		return nil, errors.New("invalid padding")
+	nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plainText, err := aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return "", ErrInvalidCipherText
 	}
 
-	return data[:length-padding], nil
+	return string(plainText), nil
 }
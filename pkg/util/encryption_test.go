@@ -0,0 +1,96 @@
+package util
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAesEncryption(t *testing.T) {
+	oldKey := AesKey{ID: "1", Secret: "1111222233334444"}
+	newKey := AesKey{ID: "2", Secret: "5555666677778888"}
+
+	t.Run("round trip", func(t *testing.T) {
+		e, err := NewAesEncryption(oldKey)
+		assert.NoError(t, err)
+
+		cipherText, err := e.Encrypt("hello")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(cipherText, "1."))
+
+		plainText, err := e.Decrypt(cipherText)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", plainText)
+	})
+
+	t.Run("rotation keeps old cipher texts readable", func(t *testing.T) {
+		before, err := NewAesEncryption(oldKey)
+		assert.NoError(t, err)
+		cipherText, err := before.Encrypt("hello")
+		assert.NoError(t, err)
+
+		after, err := NewAesEncryption(newKey, oldKey)
+		assert.NoError(t, err)
+		plainText, err := after.Decrypt(cipherText)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", plainText)
+
+		rotated, err := after.Encrypt("hello")
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(rotated, "2."))
+
+		_, err = before.Decrypt(rotated)
+		assert.ErrorIs(t, err, ErrUnknownKeyID)
+	})
+
+	t.Run("tampered cipher text", func(t *testing.T) {
+		e, err := NewAesEncryption(oldKey, newKey)
+		assert.NoError(t, err)
+		cipherText, err := e.Encrypt("hello")
+		assert.NoError(t, err)
+
+		sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(cipherText, "1."))
+		assert.NoError(t, err)
+		sealed[len(sealed)-1] ^= 1
+		_, err = e.Decrypt("1." + base64.RawURLEncoding.EncodeToString(sealed))
+		assert.ErrorIs(t, err, ErrInvalidCipherText)
+
+		// the key id is authenticated, a swapped id does not open
+		_, err = e.Decrypt("2." + strings.TrimPrefix(cipherText, "1."))
+		assert.ErrorIs(t, err, ErrInvalidCipherText)
+
+		for _, garbage := range []string{"", "1", "1.", "1.!!", "1.AAAA"} {
+			_, err = e.Decrypt(garbage)
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		_, err := NewAesEncryption(AesKey{ID: "1", Secret: "short"})
+		assert.Error(t, err)
+
+		_, err = NewAesEncryption(AesKey{Secret: "1111222233334444"})
+		assert.Error(t, err)
+
+		_, err = NewAesEncryption(oldKey, oldKey)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseAesKeys(t *testing.T) {
+	keys, err := ParseAesKeys("1:1111222233334444, 2:aaaa:bbbbccccdddd")
+	assert.NoError(t, err)
+	assert.Equal(t, []AesKey{
+		{ID: "1", Secret: "1111222233334444"},
+		{ID: "2", Secret: "aaaa:bbbbccccdddd"},
+	}, keys)
+
+	keys, err = ParseAesKeys("")
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = ParseAesKeys("1111222233334444")
+	assert.Error(t, err)
+}
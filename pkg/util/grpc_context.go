@@ -0,0 +1,33 @@
+package util
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hokdre/mini-ewallet/internal/model"
+)
+
+type tokenKey struct{}
+
+// ContextWithToken is the gRPC counterpart of SetToken.
+func ContextWithToken(ctx context.Context, token model.Token) context.Context {
+	return context.WithValue(ctx, tokenKey{}, token)
+}
+
+func TokenFromContext(ctx context.Context) (model.Token, error) {
+	token, ok := ctx.Value(tokenKey{}).(model.Token)
+	if !ok {
+		return model.Token{}, model.ErrLoginInfoUknown
+	}
+
+	return token, nil
+}
+
+func AccountIDFromContext(ctx context.Context) (uuid.UUID, error) {
+	token, err := TokenFromContext(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return token.AccountID, nil
+}
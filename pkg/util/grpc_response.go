@@ -0,0 +1,94 @@
+package util
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+const grpcErrorDomain = "mini-ewallet"
+
+// GrpcFailed is the gRPC counterpart of a 400 SendFailed, fields maps the
+// invalid field to its description.
+func GrpcFailed(fields map[string]string) error {
+	badRequest := &errdetails.BadRequest{}
+	for field, description := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: description,
+		})
+	}
+
+	return withDetails(status.New(codes.InvalidArgument, "Fail"), badRequest)
+}
+
+// GrpcError maps err onto a status the same way SendFailedOrError maps it
+// onto an http status.
+func GrpcError(err error) error {
+	log.Printf("err : %s \n", err)
+
+	if validationErrs, ok := err.(validator.ValidationErrors); ok {
+		fields := map[string]string{}
+		for _, fieldErr := range validationErrs {
+			fields[fieldErr.Field()] = "value is not valid"
+		}
+
+		return GrpcFailed(fields)
+	}
+
+	if errors.Is(err, model.ErrBussiness) {
+		st := status.New(codes.FailedPrecondition, err.Error())
+		var codeErr *model.CodeError
+		if errors.As(err, &codeErr) {
+			return withDetails(st, &errdetails.ErrorInfo{
+				Reason: codeErr.Code,
+				Domain: grpcErrorDomain,
+			})
+		}
+		return st.Err()
+	}
+
+	// a stale version or a transaction that moved on is a lost race, the
+	// client reads again and retries, only a reused reference already exists
+	if errors.Is(err, model.ErrWalletVersionConflict) || errors.Is(err, model.ErrInvalidStatusTransition) {
+		return status.Error(codes.Aborted, err.Error())
+	}
+
+	if errors.Is(err, model.ErrConflict) {
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+
+	if errors.Is(err, model.ErrLoginInfoUknown) {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	if errors.Is(err, model.ErrForbidden) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if errors.Is(err, model.ErrRateLimited) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
@@ -0,0 +1,62 @@
+package util
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hokdre/mini-ewallet/internal/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{name: "Bussiness", err: model.ErrInsufficientBalance, want: codes.FailedPrecondition},
+		{name: "Conflict", err: model.ErrReferenceIDConflict, want: codes.AlreadyExists},
+		{name: "DuplicateReference", err: model.ErrDuplicateReferenceID, want: codes.AlreadyExists},
+		{name: "VersionConflict", err: model.ErrWalletVersionConflict, want: codes.Aborted},
+		{name: "StatusTransition", err: model.ErrInvalidStatusTransition, want: codes.Aborted},
+		{name: "LoginInfoUnknown", err: model.ErrTokenExpired, want: codes.Unauthenticated},
+		{name: "Forbidden", err: model.ErrInsufficientScope, want: codes.PermissionDenied},
+		{name: "RateLimited", err: model.ErrRateLimited, want: codes.ResourceExhausted},
+		{name: "NotFound", err: fmt.Errorf("failed get wallet : %w", sql.ErrNoRows), want: codes.NotFound},
+		{name: "Internal", err: errors.New("connection refused"), want: codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(GrpcError(tt.err))
+			assert.Equal(t, tt.want, st.Code())
+			assert.Equal(t, tt.err.Error(), st.Message())
+		})
+	}
+
+	t.Run("CodeError", func(t *testing.T) {
+		st := status.Convert(GrpcError(model.ErrDailyAmountLimitExceeded))
+		assert.Equal(t, codes.FailedPrecondition, st.Code())
+		assert.Len(t, st.Details(), 1)
+
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		assert.True(t, ok)
+		assert.Equal(t, "daily_amount_limit_exceeded", info.GetReason())
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		err := NewValidator().Validate(model.Transaction{})
+		st := status.Convert(GrpcError(err))
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Len(t, st.Details(), 1)
+
+		badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+		assert.True(t, ok)
+		assert.NotEmpty(t, badRequest.GetFieldViolations())
+	})
+}
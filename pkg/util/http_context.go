@@ -8,6 +8,7 @@ import (
 
 const (
 	KeyAccountID = "ACCOUNT_ID"
+	KeyToken     = "TOKEN"
 )
 
 func GetAccountID(ctx echo.Context) (uuid.UUID, error) {
@@ -28,3 +29,17 @@ func SetAccountID(ctx echo.Context, accountID uuid.UUID) echo.Context {
 	ctx.Set(KeyAccountID, accountID)
 	return ctx
 }
+
+func GetToken(ctx echo.Context) (model.Token, error) {
+	token, ok := ctx.Get(KeyToken).(model.Token)
+	if !ok {
+		return model.Token{}, model.ErrLoginInfoUknown
+	}
+
+	return token, nil
+}
+
+func SetToken(ctx echo.Context, token model.Token) echo.Context {
+	ctx.Set(KeyToken, token)
+	return SetAccountID(ctx, token.AccountID)
+}
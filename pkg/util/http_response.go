@@ -65,13 +65,28 @@ func SendFailedOrError(ctx echo.Context, err error) error {
 		data := map[string]interface{}{
 			"error": err.Error(),
 		}
+		var codeErr *model.CodeError
+		if errors.As(err, &codeErr) {
+			data["code"] = codeErr.Code
+		}
 		return SendFailed(ctx, http.StatusBadRequest, data)
 	}
 
+	if errors.Is(err, model.ErrConflict) {
+		data := map[string]interface{}{
+			"error": err.Error(),
+		}
+		return SendFailed(ctx, http.StatusConflict, data)
+	}
+
 	if errors.Is(err, model.ErrLoginInfoUknown) {
 		return SendError(ctx, http.StatusUnauthorized, err)
 	}
 
+	if errors.Is(err, model.ErrForbidden) {
+		return SendError(ctx, http.StatusForbidden, err)
+	}
+
 	if errors.Is(err, sql.ErrNoRows) {
 		return SendError(ctx, http.StatusNotFound, err)
 	}
@@ -0,0 +1,18 @@
+package util
+
+import "context"
+
+type operatorKey struct{}
+
+// ContextWithOperator names who acts on a wallet from the back office, the
+// service stores it with adjustments and status changes.
+func ContextWithOperator(ctx context.Context, operator string) context.Context {
+	return context.WithValue(ctx, operatorKey{}, operator)
+}
+
+// OperatorFromContext is empty when no operator acts, the account holder
+// does through the api.
+func OperatorFromContext(ctx context.Context) string {
+	operator, _ := ctx.Value(operatorKey{}).(string)
+	return operator
+}
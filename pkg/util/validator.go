@@ -18,6 +18,7 @@ func NewValidator() *validatorImpl {
 	_ = v.RegisterValidation("enumWalletStatus", impl.validateWalletStatus)
 	_ = v.RegisterValidation("enumTransactionType", impl.validateEnumTransactionType)
 	_ = v.RegisterValidation("enumTransactionStatus", impl.validateEnumTransactionStatus)
+	_ = v.RegisterValidation("enumHoldStatus", impl.validateEnumHoldStatus)
 	_ = v.RegisterValidation("gteNow", impl.validateDateGTENow)
 	impl.validate = v
 	return impl
@@ -39,7 +40,15 @@ func (v *validatorImpl) validateWalletStatus(fl validator.FieldLevel) bool {
 func (v *validatorImpl) validateEnumTransactionType(fl validator.FieldLevel) bool {
 	value := strings.ToLower(fl.Field().String())
 	return value == model.TransactionType.Withdrawal ||
-		value == model.TransactionType.Deposit
+		value == model.TransactionType.Deposit ||
+		value == model.TransactionType.TransferOut ||
+		value == model.TransactionType.TransferIn ||
+		value == model.TransactionType.Capture ||
+		value == model.TransactionType.Refund ||
+		value == model.TransactionType.Reversal ||
+		value == model.TransactionType.Fee ||
+		value == model.TransactionType.AdjustmentCredit ||
+		value == model.TransactionType.AdjustmentDebit
 }
 
 func (v *validatorImpl) validateEnumTransactionStatus(fl validator.FieldLevel) bool {
@@ -49,6 +58,14 @@ func (v *validatorImpl) validateEnumTransactionStatus(fl validator.FieldLevel) b
 		value == model.TransactionStatus.Failed
 }
 
+func (v *validatorImpl) validateEnumHoldStatus(fl validator.FieldLevel) bool {
+	value := strings.ToLower(fl.Field().String())
+	return value == model.HoldStatus.Authorized ||
+		value == model.HoldStatus.Captured ||
+		value == model.HoldStatus.Voided ||
+		value == model.HoldStatus.Expired
+}
+
 func (v *validatorImpl) validateDateGTENow(fl validator.FieldLevel) bool {
 	fieldValue := fl.Field().Interface()
 	if fieldValue == nil {